@@ -95,7 +95,11 @@
 // - https://github.com/vdobler/chart: basic charts in Go
 package draw2d
 
-import "image/color"
+import (
+	"image/color"
+	"math"
+	"strings"
+)
 
 // FillRule defines the type for fill rules
 type FillRule int
@@ -156,6 +160,60 @@ func (join LineJoin) String() string {
 	}[join]
 }
 
+// BlendMode is the way the colors of a group are mixed with the colors
+// below it, as defined by https://www.w3.org/TR/compositing-1/#blending
+type BlendMode int
+
+const (
+	// BlendNormal paints the colors of the group over the ones below
+	BlendNormal BlendMode = iota
+	// BlendMultiply multiplies the colors, the result is darker
+	BlendMultiply
+	// BlendScreen multiplies the complements of the colors, the result is
+	// lighter
+	BlendScreen
+	// BlendOverlay multiplies or screens the colors depending on the color
+	// below
+	BlendOverlay
+	// BlendDarken keeps the darkest of the colors
+	BlendDarken
+	// BlendLighten keeps the lightest of the colors
+	BlendLighten
+	// BlendColorDodge brightens the color below to reflect the color of the
+	// group
+	BlendColorDodge
+	// BlendColorBurn darkens the color below to reflect the color of the
+	// group
+	BlendColorBurn
+	// BlendHardLight multiplies or screens the colors depending on the color
+	// of the group
+	BlendHardLight
+	// BlendSoftLight darkens or lightens the colors depending on the color of
+	// the group
+	BlendSoftLight
+	// BlendDifference subtracts the darkest of the colors from the lightest
+	BlendDifference
+	// BlendExclusion is like BlendDifference with a lower contrast
+	BlendExclusion
+)
+
+func (blend BlendMode) String() string {
+	return map[BlendMode]string{
+		BlendNormal:     "normal",
+		BlendMultiply:   "multiply",
+		BlendScreen:     "screen",
+		BlendOverlay:    "overlay",
+		BlendDarken:     "darken",
+		BlendLighten:    "lighten",
+		BlendColorDodge: "color-dodge",
+		BlendColorBurn:  "color-burn",
+		BlendHardLight:  "hard-light",
+		BlendSoftLight:  "soft-light",
+		BlendDifference: "difference",
+		BlendExclusion:  "exclusion",
+	}[blend]
+}
+
 // StrokeStyle keeps stroke style attributes
 // that is used by the Stroke method of a Drawer
 type StrokeStyle struct {
@@ -208,6 +266,120 @@ const (
 	HalignRight
 )
 
+// TextMetrics describes the dimensions of a text drawn with the current font,
+// like the canvas TextMetrics. Horizontal positions are relative to the x
+// coordinate the text is drawn at. Ascent, Descent, LineGap, CapHeight and
+// XHeight are distances and are always positive.
+type TextMetrics struct {
+	// Width is the advance width of the text, the distance to the origin of
+	// a following text. It is the height of the column for vertical text.
+	Width float64
+	// Left, Top, Right and Bottom are the bounds of the inked glyph outlines,
+	// relative to the origin of the text on the baseline. Top is negative
+	// above the baseline, as returned by GetStringBounds.
+	Left, Top, Right, Bottom float64
+	// Ascent is the distance from the baseline to the top of the font
+	Ascent float64
+	// Descent is the distance from the baseline to the bottom of the font
+	Descent float64
+	// LineGap is the recommended space between the descent of a line and
+	// the ascent of the next one
+	LineGap float64
+	// CapHeight is the height of flat capital letters above the baseline
+	CapHeight float64
+	// XHeight is the height of lowercase letters above the baseline
+	XHeight float64
+	// Glyphs holds the position of each rune of the text
+	Glyphs []GlyphMetrics
+}
+
+// GlyphMetrics is the position of a glyph within a TextMetrics. Glyphs are
+// listed from left to right once the text is shaped.
+type GlyphMetrics struct {
+	// Rune is the first character drawn by the glyph
+	Rune rune
+	// X is the position of the glyph origin, kerning included
+	X float64
+	// Y is the vertical offset of the glyph origin, non zero for marks
+	// positioned on their base
+	Y float64
+	// Advance is the advance width of the glyph, zero for marks
+	Advance float64
+}
+
+// TextAnchor defines where text drawn along a path is anchored relative to
+// the offset given to FillStringOnPath and StrokeStringOnPath
+type TextAnchor int
+
+const (
+	// TextAnchorStart places the start of the text at the offset
+	TextAnchorStart TextAnchor = iota
+	// TextAnchorMiddle places the middle of the text at the offset
+	TextAnchorMiddle
+	// TextAnchorEnd places the end of the text at the offset
+	TextAnchorEnd
+)
+
+func (anchor TextAnchor) String() string {
+	return map[TextAnchor]string{
+		TextAnchorStart:  "start",
+		TextAnchorMiddle: "middle",
+		TextAnchorEnd:    "end",
+	}[anchor]
+}
+
+// TextDecoration is a combination of the lines drawn with the text by
+// FillStringAt and StrokeStringAt
+type TextDecoration int
+
+const (
+	// TextDecorationNone draws no line
+	TextDecorationNone TextDecoration = 0
+	// TextDecorationUnderline draws a line below the baseline
+	TextDecorationUnderline TextDecoration = 1 << (iota - 1)
+	// TextDecorationOverline draws a line at the ascent of the font
+	TextDecorationOverline
+	// TextDecorationLineThrough draws a line through the middle of lowercase letters
+	TextDecorationLineThrough
+)
+
+// String returns the value of the css text-decoration property
+func (decoration TextDecoration) String() string {
+	var values []string
+	if decoration&TextDecorationUnderline != 0 {
+		values = append(values, "underline")
+	}
+	if decoration&TextDecorationOverline != 0 {
+		values = append(values, "overline")
+	}
+	if decoration&TextDecorationLineThrough != 0 {
+		values = append(values, "line-through")
+	}
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, " ")
+}
+
+// WritingMode is the direction in which text is laid out
+type WritingMode int
+
+const (
+	// WritingModeHorizontal lays out text on a line, from left to right
+	WritingModeHorizontal WritingMode = iota
+	// WritingModeVertical lays out text in a column, from top to bottom.
+	// CJK characters stay upright, other characters are turned 90° clockwise.
+	WritingModeVertical
+)
+
+// String returns the value of the css writing-mode property
+func (mode WritingMode) String() string {
+	return map[WritingMode]string{
+		WritingModeHorizontal: "horizontal-tb",
+		WritingModeVertical:   "vertical-rl",
+	}[mode]
+}
+
 // TextStyle describe text property
 type TextStyle struct {
 	// Color defines the color of text
@@ -253,3 +425,45 @@ type ImageScaling struct {
 	// ScalingPolicy defines the scaling policy to applied to the image
 	ScalingPolicy ScalingPolicy
 }
+
+// Rect returns the rectangle x0, y0, x1, y1 covered by an image of width x
+// height pixels drawn at x, y with the scaling: the image is scaled by the
+// scaling policy, and aligned in the rectangle of Width x Height at x, y
+func (scaling ImageScaling) Rect(x, y, width, height float64) (x0, y0, x1, y1 float64) {
+	sx, sy := 1.0, 1.0
+	if width > 0 && height > 0 {
+		switch scaling.ScalingPolicy {
+		case ScalingStretch:
+			sx, sy = scaling.Width/width, scaling.Height/height
+		case ScalingWidth:
+			sx = scaling.Width / width
+			sy = sx
+		case ScalingHeight:
+			sx = scaling.Height / height
+			sy = sx
+		case ScalingFit:
+			sx = math.Min(scaling.Width/width, scaling.Height/height)
+			sy = sx
+		case ScalingSameArea:
+			sx = math.Sqrt(scaling.Width * scaling.Height / (width * height))
+			sy = sx
+		case ScalingFill:
+			sx = math.Max(scaling.Width/width, scaling.Height/height)
+			sy = sx
+		}
+	}
+	width, height = width*sx, height*sy
+	switch scaling.Halign {
+	case HalignCenter:
+		x += (scaling.Width - width) / 2
+	case HalignRight:
+		x += scaling.Width - width
+	}
+	switch scaling.Valign {
+	case ValignCenter:
+		y += (scaling.Height - height) / 2
+	case ValignBottom, ValignBaseline:
+		y += scaling.Height - height
+	}
+	return x, y, x + width, y + height
+}
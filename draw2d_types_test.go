@@ -48,6 +48,37 @@ func TestLineJoin_String(t *testing.T) {
 	}
 }
 
+func TestTextDecoration_String(t *testing.T) {
+	tests := []struct {
+		name       string
+		decoration TextDecoration
+		expected   string
+	}{
+		{"None", TextDecorationNone, "none"},
+		{"Underline", TextDecorationUnderline, "underline"},
+		{"Overline", TextDecorationOverline, "overline"},
+		{"LineThrough", TextDecorationLineThrough, "line-through"},
+		{"Underline and LineThrough", TextDecorationUnderline | TextDecorationLineThrough, "underline line-through"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.decoration.String(); got != tt.expected {
+				t.Errorf("TextDecoration.String() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWritingMode_String(t *testing.T) {
+	if got := WritingModeHorizontal.String(); got != "horizontal-tb" {
+		t.Errorf("WritingModeHorizontal.String() = %v, want horizontal-tb", got)
+	}
+	if got := WritingModeVertical.String(); got != "vertical-rl" {
+		t.Errorf("WritingModeVertical.String() = %v, want vertical-rl", got)
+	}
+}
+
 func TestFillRule_Constants(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -161,3 +192,29 @@ func TestSolidFillStyle_Defaults(t *testing.T) {
 		t.Errorf("SolidFillStyle.Color RGBA values incorrect")
 	}
 }
+
+func TestImageScaling_Rect(t *testing.T) {
+	tests := []struct {
+		name    string
+		scaling ImageScaling
+		want    [4]float64
+	}{
+		{"None", ImageScaling{Width: 100, Height: 50}, [4]float64{10, 20, 30, 60}},
+		{"Stretch", ImageScaling{Width: 100, Height: 50, ScalingPolicy: ScalingStretch}, [4]float64{10, 20, 110, 70}},
+		{"Width", ImageScaling{Width: 40, ScalingPolicy: ScalingWidth}, [4]float64{10, 20, 50, 100}},
+		{"Height", ImageScaling{Height: 20, ScalingPolicy: ScalingHeight}, [4]float64{10, 20, 20, 40}},
+		{"FitCenter", ImageScaling{Width: 100, Height: 50, ScalingPolicy: ScalingFit, Halign: HalignCenter, Valign: ValignCenter}, [4]float64{47.5, 20, 72.5, 70}},
+		{"FillBottomRight", ImageScaling{Width: 100, Height: 50, ScalingPolicy: ScalingFill, Halign: HalignRight, Valign: ValignBottom}, [4]float64{10, -130, 110, 70}},
+		{"SameArea", ImageScaling{Width: 80, Height: 10, ScalingPolicy: ScalingSameArea}, [4]float64{10, 20, 30, 60}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// an image of 20 x 40 pixels drawn at 10, 20
+			x0, y0, x1, y1 := tt.scaling.Rect(10, 20, 20, 40)
+			if got := [4]float64{x0, y0, x1, y1}; got != tt.want {
+				t.Errorf("ImageScaling.Rect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,614 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"golang.org/x/text/unicode/bidi"
+)
+
+// Implementation of the Unicode Bidirectional Algorithm (UAX #9) for a single
+// line of text, with its explicit embeddings, overrides and isolates. Only
+// the character classes come from golang.org/x/text/unicode/bidi: its
+// Paragraph does not pair brackets and its Ordering merges the runs of
+// different levels of the same direction, which rule L2 reorders.
+
+// maxDepth is the maximum explicit embedding level (BD2)
+const maxDepth = 125
+
+// bidiRun is a sequence of runes with the same embedding level
+type bidiRun struct {
+	start, end int
+	level      uint8
+}
+
+// rtl returns true if the run is drawn right to left
+func (run bidiRun) rtl() bool {
+	return run.level&1 == 1
+}
+
+// isNeutral returns true for the classes resolved by rules N1 and N2
+func isNeutral(c bidi.Class) bool {
+	switch c {
+	case bidi.B, bidi.S, bidi.WS, bidi.ON, bidi.LRI, bidi.RLI, bidi.FSI, bidi.PDI:
+		return true
+	}
+	return false
+}
+
+// isIsolateInitiator returns true for LRI, RLI and FSI
+func isIsolateInitiator(c bidi.Class) bool {
+	return c == bidi.LRI || c == bidi.RLI || c == bidi.FSI
+}
+
+// isRemovedByX9 returns true for the embedding, override and boundary
+// neutral characters, which rule X9 removes
+func isRemovedByX9(c bidi.Class) bool {
+	switch c {
+	case bidi.LRE, bidi.RLE, bidi.LRO, bidi.RLO, bidi.PDF, bidi.BN:
+		return true
+	}
+	return false
+}
+
+// isTrailing returns true for the whitespace and the formatting characters
+// reset to the paragraph level by rule L1 before a separator or at the end
+// of the line
+func isTrailing(c bidi.Class) bool {
+	return c == bidi.WS || c == bidi.LRI || c == bidi.RLI || c == bidi.FSI || c == bidi.PDI || isRemovedByX9(c)
+}
+
+// direction returns L for even levels and R for odd levels
+func direction(level uint8) bidi.Class {
+	if level&1 == 1 {
+		return bidi.R
+	}
+	return bidi.L
+}
+
+// strongDirection maps L to L and R, EN and AN to R, as used by rules N0-N2
+func strongDirection(c bidi.Class) (bidi.Class, bool) {
+	switch c {
+	case bidi.L:
+		return bidi.L, true
+	case bidi.R, bidi.AL, bidi.EN, bidi.AN:
+		return bidi.R, true
+	}
+	return 0, false
+}
+
+func bidiClass(r rune) bidi.Class {
+	p, _ := bidi.LookupRune(r)
+	return p.Class()
+}
+
+// bidiLevels resolves the embedding level of each rune
+func bidiLevels(runes []rune) []uint8 {
+	n := len(runes)
+	original := make([]bidi.Class, n)
+	for i, r := range runes {
+		original[i] = bidiClass(r)
+	}
+	matching := matchIsolates(original)
+
+	// P2, P3: the paragraph level is given by the first strong character
+	// out of the isolates
+	paragraph := firstStrongLevel(original, matching, 0, n)
+
+	// X1-X8: the explicit levels, and the classes of the overridden
+	// characters
+	explicit, types := explicitLevels(original, matching, paragraph)
+
+	// X9, X10: the weak, neutral and implicit rules are applied to each
+	// isolating run sequence, without the characters removed by X9
+	levels := make([]uint8, n)
+	for _, sequence := range isolatingRunSequences(original, explicit, matching) {
+		level := explicit[sequence[0]]
+		// sos and eos are the directions of the higher of the level of the
+		// sequence and the ones of the characters around it
+		before, after := paragraph, paragraph
+		prev := sequence[0] - 1
+		for prev >= 0 && isRemovedByX9(original[prev]) {
+			prev--
+		}
+		if prev >= 0 {
+			before = explicit[prev]
+		}
+		if last := sequence[len(sequence)-1]; !isIsolateInitiator(original[last]) {
+			next := last + 1
+			for next < n && isRemovedByX9(original[next]) {
+				next++
+			}
+			if next < n {
+				after = explicit[next]
+			}
+		}
+		sequenceRunes := make([]rune, len(sequence))
+		sequenceTypes := make([]bidi.Class, len(sequence))
+		for k, i := range sequence {
+			sequenceRunes[k], sequenceTypes[k] = runes[i], types[i]
+		}
+		resolved := implicitLevels(sequenceRunes, sequenceTypes, level, direction(max(before, level)), direction(max(after, level)))
+		for k, i := range sequence {
+			levels[i] = resolved[k]
+		}
+	}
+	// the characters removed by X9 take the level of the preceding
+	// character
+	for i, c := range original {
+		if !isRemovedByX9(c) {
+			continue
+		}
+		levels[i] = paragraph
+		if i > 0 {
+			levels[i] = levels[i-1]
+		}
+	}
+
+	// L1: separators and trailing whitespace are reset to the paragraph level
+	trailing := true
+	for i := n - 1; i >= 0; i-- {
+		switch c := original[i]; {
+		case c == bidi.S || c == bidi.B:
+			levels[i] = paragraph
+			trailing = true
+		case isTrailing(c):
+			if trailing {
+				levels[i] = paragraph
+			}
+		default:
+			trailing = false
+		}
+	}
+	return levels
+}
+
+// matchIsolates returns for each isolate initiator the index of its matching
+// PDI (BD9), len(classes) if it has none, for each matching PDI the index of
+// its isolate initiator, and -1 for the other characters
+func matchIsolates(classes []bidi.Class) []int {
+	matching := make([]int, len(classes))
+	var initiators []int
+	for i, c := range classes {
+		matching[i] = -1
+		switch {
+		case isIsolateInitiator(c):
+			matching[i] = len(classes)
+			initiators = append(initiators, i)
+		case c == bidi.PDI && len(initiators) > 0:
+			initiator := initiators[len(initiators)-1]
+			initiators = initiators[:len(initiators)-1]
+			matching[initiator], matching[i] = i, initiator
+		case c == bidi.B:
+			initiators = initiators[:0]
+		}
+	}
+	return matching
+}
+
+// firstStrongLevel returns the level given by the first strong character of
+// classes from start to end, skipping the isolates, as rules P2 and P3 do
+func firstStrongLevel(classes []bidi.Class, matching []int, start, end int) uint8 {
+	for i := start; i < end; i++ {
+		switch c := classes[i]; {
+		case c == bidi.L:
+			return 0
+		case c == bidi.R || c == bidi.AL:
+			return 1
+		case isIsolateInitiator(c):
+			i = matching[i]
+		}
+	}
+	return 0
+}
+
+// directionalStatus is an entry of the directional status stack of rules
+// X1-X8
+type directionalStatus struct {
+	level uint8
+	// override is L or R for the overrides, ON otherwise
+	override bidi.Class
+	isolate  bool
+}
+
+// explicitLevels applies rules X1-X8 to the classes of a paragraph of level
+// paragraph. It returns the explicit levels and the classes, the ones of
+// the overridden characters being changed to L or R.
+func explicitLevels(original []bidi.Class, matching []int, paragraph uint8) (levels []uint8, types []bidi.Class) {
+	levels = make([]uint8, len(original))
+	types = make([]bidi.Class, len(original))
+	copy(types, original)
+	// X1
+	stack := []directionalStatus{{level: paragraph, override: bidi.ON}}
+	var overflowIsolates, overflowEmbeddings, validIsolates int
+	for i, c := range original {
+		last := stack[len(stack)-1]
+		switch c {
+		case bidi.LRE, bidi.RLE, bidi.LRO, bidi.RLO, bidi.LRI, bidi.RLI, bidi.FSI:
+			// X2-X5c: the isolate initiators have the level and the
+			// override of the enclosing embedding
+			isolate := isIsolateInitiator(c)
+			rtl := c == bidi.RLE || c == bidi.RLO || c == bidi.RLI
+			if c == bidi.FSI {
+				rtl = firstStrongLevel(original, matching, i+1, matching[i]) == 1
+			}
+			levels[i] = last.level
+			if isolate && last.override != bidi.ON {
+				types[i] = last.override
+			}
+			// the least greater odd or even level
+			level := (last.level + 2) &^ 1
+			if rtl {
+				level = (last.level + 1) | 1
+			}
+			switch {
+			case level <= maxDepth && overflowIsolates == 0 && overflowEmbeddings == 0:
+				if isolate {
+					validIsolates++
+				}
+				override := bidi.ON
+				switch c {
+				case bidi.LRO:
+					override = bidi.L
+				case bidi.RLO:
+					override = bidi.R
+				}
+				stack = append(stack, directionalStatus{level, override, isolate})
+			case isolate:
+				overflowIsolates++
+			case overflowIsolates == 0:
+				overflowEmbeddings++
+			}
+		case bidi.PDI:
+			// X6a: the isolate is terminated with the embeddings it
+			// contains
+			switch {
+			case overflowIsolates > 0:
+				overflowIsolates--
+			case validIsolates > 0:
+				overflowEmbeddings = 0
+				for !stack[len(stack)-1].isolate {
+					stack = stack[:len(stack)-1]
+				}
+				stack = stack[:len(stack)-1]
+				validIsolates--
+			}
+			last = stack[len(stack)-1]
+			levels[i] = last.level
+			if last.override != bidi.ON {
+				types[i] = last.override
+			}
+		case bidi.PDF:
+			// X7
+			switch {
+			case overflowIsolates > 0:
+			case overflowEmbeddings > 0:
+				overflowEmbeddings--
+			case !last.isolate && len(stack) >= 2:
+				stack = stack[:len(stack)-1]
+			}
+			levels[i] = last.level
+		case bidi.B:
+			// X8: the end of the paragraph terminates everything
+			stack = stack[:1]
+			overflowIsolates, overflowEmbeddings, validIsolates = 0, 0, 0
+			levels[i] = paragraph
+		case bidi.BN:
+			// X6 ignores the boundary neutrals
+			levels[i] = last.level
+		default:
+			// X6
+			levels[i] = last.level
+			if last.override != bidi.ON {
+				types[i] = last.override
+			}
+		}
+	}
+	return levels, types
+}
+
+// isolatingRunSequences returns the indices of the characters of each
+// isolating run sequence (BD13), without the characters removed by X9
+func isolatingRunSequences(original []bidi.Class, levels []uint8, matching []int) [][]int {
+	// BD7: the level runs
+	var runs [][]int
+	runOf := make([]int, len(original))
+	for i, c := range original {
+		if isRemovedByX9(c) {
+			continue
+		}
+		if len(runs) == 0 {
+			runs = append(runs, nil)
+		} else if run := runs[len(runs)-1]; levels[run[len(run)-1]] != levels[i] {
+			runs = append(runs, nil)
+		}
+		runs[len(runs)-1] = append(runs[len(runs)-1], i)
+		runOf[i] = len(runs) - 1
+	}
+	// the runs ending with an isolate initiator continue with the one of
+	// its matching PDI
+	var sequences [][]int
+	for _, run := range runs {
+		if first := run[0]; original[first] == bidi.PDI && matching[first] >= 0 {
+			continue
+		}
+		var sequence []int
+		for {
+			sequence = append(sequence, run...)
+			last := run[len(run)-1]
+			if !isIsolateInitiator(original[last]) || matching[last] == len(original) {
+				break
+			}
+			run = runs[runOf[matching[last]]]
+		}
+		sequences = append(sequences, sequence)
+	}
+	return sequences
+}
+
+// implicitLevels resolves the levels of the runes of an isolating run
+// sequence of level level, of the classes original, with the weak, neutral
+// and implicit rules. sos and eos are the directions at the start and the
+// end of the sequence.
+func implicitLevels(runes []rune, original []bidi.Class, level uint8, sos, eos bidi.Class) []uint8 {
+	n := len(runes)
+	embedding := direction(level)
+
+	types := make([]bidi.Class, n)
+	copy(types, original)
+
+	// W1: non spacing marks take the type of the previous character, ON
+	// after the isolate initiators and PDI
+	prev := sos
+	for i, c := range types {
+		if c == bidi.NSM {
+			types[i] = prev
+			if isIsolateInitiator(prev) || prev == bidi.PDI {
+				types[i] = bidi.ON
+			}
+		}
+		prev = types[i]
+	}
+	// W2: european numbers after arabic letters become arabic numbers
+	// W3: arabic letters become R
+	lastStrong := sos
+	for i, c := range types {
+		switch c {
+		case bidi.L, bidi.R, bidi.AL:
+			lastStrong = c
+		case bidi.EN:
+			if lastStrong == bidi.AL {
+				types[i] = bidi.AN
+			}
+		}
+	}
+	for i, c := range types {
+		if c == bidi.AL {
+			types[i] = bidi.R
+		}
+	}
+	// W4: a single separator between two numbers of the same type joins them
+	for i := 1; i < n-1; i++ {
+		before, after := types[i-1], types[i+1]
+		switch types[i] {
+		case bidi.ES:
+			if before == bidi.EN && after == bidi.EN {
+				types[i] = bidi.EN
+			}
+		case bidi.CS:
+			if before == after && (before == bidi.EN || before == bidi.AN) {
+				types[i] = before
+			}
+		}
+	}
+	// W5: terminators adjacent to european numbers become european numbers
+	for i := 0; i < n; i++ {
+		if types[i] != bidi.ET {
+			continue
+		}
+		j := i
+		for j < n && types[j] == bidi.ET {
+			j++
+		}
+		if (i > 0 && types[i-1] == bidi.EN) || (j < n && types[j] == bidi.EN) {
+			for k := i; k < j; k++ {
+				types[k] = bidi.EN
+			}
+		}
+		i = j - 1
+	}
+	// W6: remaining separators and terminators become neutral
+	for i, c := range types {
+		if c == bidi.ES || c == bidi.ET || c == bidi.CS {
+			types[i] = bidi.ON
+		}
+	}
+	// W7: european numbers after L become L
+	lastStrong = sos
+	for i, c := range types {
+		switch c {
+		case bidi.L, bidi.R:
+			lastStrong = c
+		case bidi.EN:
+			if lastStrong == bidi.L {
+				types[i] = bidi.L
+			}
+		}
+	}
+
+	resolveBrackets(runes, original, types, embedding, sos)
+
+	// N1, N2: sequences of neutrals take the direction of the surrounding
+	// strong characters when they agree, the embedding direction otherwise
+	for i := 0; i < n; i++ {
+		if !isNeutral(types[i]) {
+			continue
+		}
+		j := i
+		for j < n && isNeutral(types[j]) {
+			j++
+		}
+		before, after := sos, eos
+		if i > 0 {
+			before, _ = strongDirection(types[i-1])
+		}
+		if j < n {
+			after, _ = strongDirection(types[j])
+		}
+		direction := embedding
+		if before == after {
+			direction = before
+		}
+		for k := i; k < j; k++ {
+			types[k] = direction
+		}
+		i = j - 1
+	}
+
+	// I1, I2: resolve implicit levels
+	levels := make([]uint8, n)
+	for i, c := range types {
+		levels[i] = level
+		if level&1 == 0 {
+			switch c {
+			case bidi.R:
+				levels[i]++
+			case bidi.AN, bidi.EN:
+				levels[i] += 2
+			}
+		} else if c == bidi.L || c == bidi.EN || c == bidi.AN {
+			levels[i]++
+		}
+	}
+	return levels
+}
+
+// resolveBrackets applies rule N0 to the bracket pairs of runes, in an
+// isolating run sequence of direction embedding starting with sos
+func resolveBrackets(runes []rune, original, types []bidi.Class, embedding, sos bidi.Class) {
+	type pair struct{ open, close int }
+	var pairs []pair
+	var stack []int
+	for i, r := range runes {
+		if original[i] != bidi.ON {
+			continue
+		}
+		p, _ := bidi.LookupRune(r)
+		if !p.IsBracket() {
+			continue
+		}
+		if p.IsOpeningBracket() {
+			if len(stack) == 63 {
+				break
+			}
+			stack = append(stack, i)
+			continue
+		}
+		// find the opening bracket matching this closing one
+		for k := len(stack) - 1; k >= 0; k-- {
+			if mirror(runes[stack[k]]) == r {
+				pairs = append(pairs, pair{stack[k], i})
+				stack = stack[:k]
+				break
+			}
+		}
+	}
+	// pairs are processed in the order of their opening bracket
+	for i := 1; i < len(pairs); i++ {
+		for j := i; j > 0 && pairs[j].open < pairs[j-1].open; j-- {
+			pairs[j], pairs[j-1] = pairs[j-1], pairs[j]
+		}
+	}
+	for _, p := range pairs {
+		foundEmbedding, foundOpposite := false, false
+		for k := p.open + 1; k < p.close; k++ {
+			if d, ok := strongDirection(types[k]); ok {
+				if d == embedding {
+					foundEmbedding = true
+				} else {
+					foundOpposite = true
+				}
+			}
+		}
+		var direction bidi.Class
+		switch {
+		case foundEmbedding:
+			direction = embedding
+		case foundOpposite:
+			context := sos
+			for k := p.open - 1; k >= 0; k-- {
+				if d, ok := strongDirection(types[k]); ok {
+					context = d
+					break
+				}
+			}
+			direction = embedding
+			if context != embedding {
+				direction = context
+			}
+		default:
+			continue
+		}
+		types[p.open], types[p.close] = direction, direction
+	}
+}
+
+// mirrored holds the mirrored characters that are not brackets, which
+// bidi.ReverseString leaves unchanged
+var mirrored = map[rune]rune{
+	'<': '>', '>': '<',
+	'«': '»', '»': '«',
+	'‹': '›', '›': '‹',
+	'≤': '≥', '≥': '≤',
+	'≪': '≫', '≫': '≪',
+}
+
+// mirror returns the mirrored counterpart of r for right to left text,
+// or r itself if it has none
+func mirror(r rune) rune {
+	if m, ok := mirrored[r]; ok {
+		return m
+	}
+	for _, m := range bidi.ReverseString(string(r)) {
+		return m
+	}
+	return r
+}
+
+// bidiRuns splits runes into runs of the same embedding level and returns
+// them in visual order, from left to right (rule L2)
+func bidiRuns(runes []rune) []bidiRun {
+	levels := bidiLevels(runes)
+	var runs []bidiRun
+	var highest, lowestOdd uint8 = 0, 255
+	for i, level := range levels {
+		if i == 0 || level != levels[i-1] {
+			runs = append(runs, bidiRun{start: i, level: level})
+		}
+		runs[len(runs)-1].end = i + 1
+		if level > highest {
+			highest = level
+		}
+		if level&1 == 1 && level < lowestOdd {
+			lowestOdd = level
+		}
+	}
+	// reverse any sequence of runs at level k or higher, from the highest
+	// level down to the lowest odd level
+	for k := highest; k >= lowestOdd && k > 0; k-- {
+		for i := 0; i < len(runs); i++ {
+			if runs[i].level < k {
+				continue
+			}
+			j := i
+			for j < len(runs) && runs[j].level >= k {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				runs[a], runs[b] = runs[b], runs[a]
+			}
+			i = j
+		}
+	}
+	return runs
+}
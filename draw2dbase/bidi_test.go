@@ -0,0 +1,123 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math/rand"
+	"testing"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+// visualOrder returns text as it is displayed from left to right
+func visualOrder(text string) string {
+	runes := []rune(text)
+	var visual []rune
+	for _, run := range bidiRuns(runes) {
+		if run.rtl() {
+			for i := run.end - 1; i >= run.start; i-- {
+				visual = append(visual, mirror(runes[i]))
+			}
+		} else {
+			visual = append(visual, runes[run.start:run.end]...)
+		}
+	}
+	return string(visual)
+}
+
+func TestBidiVisualOrder(t *testing.T) {
+	tests := []struct {
+		name, logical, visual string
+	}{
+		{"latin", "Hello, World!", "Hello, World!"},
+		{"hebrew", "שלום", "םולש"},
+		{"hebrew in latin", "abc שלום def", "abc םולש def"},
+		{"brackets", "abc שלום (עולם) def", "abc (םלוע) םולש def"},
+		{"number in hebrew", "שנה 2024 טובה", "הבוט 2024 הנש"},
+		{"latin in hebrew", "שלום abc", "abc םולש"},
+		{"trailing punctuation", "שלום!", "!םולש"},
+		{"arabic number", "عام ٢٠٢٤", "٢٠٢٤ ماع"},
+		// the removed formatting characters take the level of the previous
+		// character
+		{"embedding", "abc \u202bdef שלום\u202c ghi", "abc \u202b\u202cםולש def ghi"},
+		{"override", "abc \u202edef\u202c ghi", "abc \u202e\u202cfed ghi"},
+		{"isolate", "שלום \u2066abc (def)\u2069!", "!\u2069abc (def)\u2066 םולש"},
+		{"first strong isolate", "abc \u2068שלום 12\u2069 def", "abc \u206812 םולש\u2069 def"},
+	}
+	for _, test := range tests {
+		if visual := visualOrder(test.logical); visual != test.visual {
+			t.Errorf("%s: visual order of %q is %q, want %q", test.name, test.logical, visual, test.visual)
+		}
+	}
+}
+
+func TestBidiLevels(t *testing.T) {
+	levels := bidiLevels([]rune("ab שלום 12 cd "))
+	want := []uint8{0, 0, 0, 1, 1, 1, 1, 1, 2, 2, 0, 0, 0, 0}
+	if len(levels) != len(want) {
+		t.Fatalf("got %d levels, want %d", len(levels), len(want))
+	}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Errorf("level of rune %d is %d, want %d", i, levels[i], want[i])
+		}
+	}
+}
+
+// xtextDirections returns whether each rune of text is right to left as
+// resolved by golang.org/x/text/unicode/bidi
+func xtextDirections(t *testing.T, text string) []bool {
+	var p bidi.Paragraph
+	if _, err := p.SetString(text); err != nil {
+		t.Fatal(err)
+	}
+	o, err := p.Order()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rtl []bool
+	for i := 0; i < o.NumRuns(); i++ {
+		run := o.Run(i)
+		for range []rune(run.String()) {
+			rtl = append(rtl, run.Direction() == bidi.RightToLeft)
+		}
+	}
+	return rtl
+}
+
+// TestBidiLevels_XText compares the directions of random text with the ones
+// resolved by golang.org/x/text/unicode/bidi, which is tested against
+// BidiCharacterTest.txt, with its embeddings, overrides and isolates. Its
+// Paragraph does not pair brackets, the text has none.
+func TestBidiLevels_XText(t *testing.T) {
+	// strong, weak, neutral, non spacing and boundary neutral characters
+	alphabet := []rune("aZ שא عب 12٣4۵+-$%#.,:/ \t!?\u064b\u064e\u0300\u00a0\u200b\x01")
+	// the embeddings, overrides and isolates
+	alphabet = append(alphabet, []rune("\u202a\u202b\u202c\u202d\u202e\u2066\u2067\u2068\u2069")...)
+	rng := rand.New(rand.NewSource(1))
+	for range 20000 {
+		runes := make([]rune, 1+rng.Intn(10))
+		for i := range runes {
+			runes[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		levels := bidiLevels(runes)
+		want := xtextDirections(t, string(runes))
+		if len(want) != len(runes) {
+			t.Fatalf("%q: got %d directions from x/text", string(runes), len(want))
+		}
+		for i := range runes {
+			if rtl := levels[i]&1 == 1; rtl != want[i] {
+				t.Fatalf("%q: got levels %v, want the directions %v", string(runes), levels, want)
+			}
+		}
+	}
+}
+
+func TestMirror(t *testing.T) {
+	for r, m := range map[rune]rune{'(': ')', ']': '[', '<': '>', 'a': 'a'} {
+		if got := mirror(r); got != m {
+			t.Errorf("mirror(%q) = %q, want %q", r, got, m)
+		}
+	}
+}
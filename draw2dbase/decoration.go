@@ -0,0 +1,92 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"github.com/llgcode/draw2d"
+)
+
+// DecorationLine is the vertical extent of a line of text decoration, as
+// offsets from the baseline, y going downwards
+type DecorationLine struct {
+	Top, Bottom float64
+}
+
+// DecorationLines returns the lines of decoration for a font with metrics m
+func DecorationLines(m FontMetrics, decoration draw2d.TextDecoration) []DecorationLine {
+	var lines []DecorationLine
+	if decoration&draw2d.TextDecorationUnderline != 0 {
+		lines = append(lines, DecorationLine{m.UnderlinePosition, m.UnderlinePosition + m.UnderlineThickness})
+	}
+	if decoration&draw2d.TextDecorationOverline != 0 {
+		lines = append(lines, DecorationLine{-m.Ascent, -m.Ascent + m.UnderlineThickness})
+	}
+	if decoration&draw2d.TextDecorationLineThrough != 0 {
+		lines = append(lines, DecorationLine{m.StrikeoutPosition, m.StrikeoutPosition + m.StrikeoutThickness})
+	}
+	return lines
+}
+
+// AddDecorationLines adds a rectangle to path for each line, spanning a text
+// of width drawn at x, y
+func AddDecorationLines(path draw2d.PathBuilder, lines []DecorationLine, x, y, width float64) {
+	for _, line := range lines {
+		path.MoveTo(x, y+line.Top)
+		path.LineTo(x+width, y+line.Top)
+		path.LineTo(x+width, y+line.Bottom)
+		path.LineTo(x, y+line.Bottom)
+		path.Close()
+	}
+}
+
+// DecorationBounds returns the bounds of the decoration lines of a text of
+// advance laid out in mode, relative to its origin. The lines of vertical
+// text follow its sideways baseline. ok is false if nothing is drawn.
+func DecorationBounds(m FontMetrics, decoration draw2d.TextDecoration, mode draw2d.WritingMode, advance float64) (left, top, right, bottom float64, ok bool) {
+	lines := DecorationLines(m, decoration)
+	if advance <= 0 || len(lines) == 0 {
+		return 0, 0, 0, 0, false
+	}
+	top, bottom = lines[0].Top, lines[0].Bottom
+	for _, line := range lines[1:] {
+		if line.Top < top {
+			top = line.Top
+		}
+		if line.Bottom > bottom {
+			bottom = line.Bottom
+		}
+	}
+	if mode == draw2d.WritingModeVertical {
+		baseline := SidewaysBaseline(m)
+		return baseline - bottom, 0, baseline - top, advance, true
+	}
+	return 0, top, advance, bottom, true
+}
+
+// AddDecorations adds to path the decoration lines of a text of advance laid
+// out in mode with a font of metrics m and drawn at x, y. The lines of
+// vertical text follow its sideways baseline.
+func AddDecorations(path draw2d.PathBuilder, m FontMetrics, decoration draw2d.TextDecoration, mode draw2d.WritingMode, x, y, advance float64) {
+	lines := DecorationLines(m, decoration)
+	if mode == draw2d.WritingModeVertical {
+		AddDecorationLines(rotatedPath{path, x + SidewaysBaseline(m), y}, lines, 0, 0, advance)
+		return
+	}
+	AddDecorationLines(path, lines, x, y, advance)
+}
+
+// DrawDecorations draws the decoration lines set on gc for a text of advance
+// drawn at x, y with a font of metrics m, in the writing mode of gc.
+// drawType is FillGlyph or StrokeGlyph.
+func DrawDecorations(gc draw2d.GraphicContext, m FontMetrics, x, y, advance float64, drawType func(g *Glyph, gc draw2d.GraphicContext)) {
+	if advance <= 0 || gc.GetTextDecoration() == draw2d.TextDecorationNone {
+		return
+	}
+	path := &draw2d.Path{}
+	AddDecorations(path, m, gc.GetTextDecoration(), gc.GetWritingMode(), x, y, advance)
+	gc.Save()
+	gc.BeginPath()
+	drawType(&Glyph{Path: path, Width: advance}, gc)
+	gc.Restore()
+}
@@ -0,0 +1,124 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// DrawContour draws the given closed contour at the given sub-pixel offset.
+func DrawContour(path draw2d.PathBuilder, ps []truetype.Point, dx, dy float64) {
+	if len(ps) == 0 {
+		return
+	}
+	startX, startY := pointToF64Point(ps[0])
+	var others []truetype.Point
+	if ps[0].Flags&0x01 != 0 {
+		others = ps[1:]
+	} else {
+		lastX, lastY := pointToF64Point(ps[len(ps)-1])
+		if ps[len(ps)-1].Flags&0x01 != 0 {
+			startX, startY = lastX, lastY
+			others = ps[:len(ps)-1]
+		} else {
+			startX = (startX + lastX) / 2
+			startY = (startY + lastY) / 2
+			others = ps
+		}
+	}
+	path.MoveTo(startX+dx, startY+dy)
+	q0X, q0Y, on0 := startX, startY, true
+	for _, p := range others {
+		qX, qY := pointToF64Point(p)
+		on := p.Flags&0x01 != 0
+		if on {
+			if on0 {
+				path.LineTo(qX+dx, qY+dy)
+			} else {
+				path.QuadCurveTo(q0X+dx, q0Y+dy, qX+dx, qY+dy)
+			}
+		} else {
+			if on0 {
+				// No-op.
+			} else {
+				midX := (q0X + qX) / 2
+				midY := (q0Y + qY) / 2
+				path.QuadCurveTo(q0X+dx, q0Y+dy, midX+dx, midY+dy)
+			}
+		}
+		q0X, q0Y, on0 = qX, qY, on
+	}
+	// Close the curve.
+	if on0 {
+		path.LineTo(startX+dx, startY+dy)
+	} else {
+		path.QuadCurveTo(q0X+dx, q0Y+dy, startX+dx, startY+dy)
+	}
+}
+
+// p is a truetype.Point measured in FUnits and positive Y going upwards.
+// The returned value is the same thing measured in floating point and positive Y
+// going downwards.
+func pointToF64Point(p truetype.Point) (x, y float64) {
+	return fUnitsToFloat64(p.X), -fUnitsToFloat64(p.Y)
+}
+
+func fUnitsToFloat64(x fixed.Int26_6) float64 {
+	scaled := x << 2
+	return float64(scaled/256) + float64(scaled%256)/256.0
+}
+
+// GlyphPath returns the outline of the glyph index of f at scale, with its
+// origin at (0, 0)
+func GlyphPath(f *truetype.Font, scale float64, index truetype.Index) (*draw2d.Path, error) {
+	glyphBuf := &truetype.GlyphBuf{}
+	if err := glyphBuf.Load(f, fixed.Int26_6(scale), index, font.HintingNone); err != nil {
+		return nil, err
+	}
+	path := &draw2d.Path{}
+	e0 := 0
+	for _, e1 := range glyphBuf.Ends {
+		DrawContour(path, glyphBuf.Points[e0:e1], 0, 0)
+		e0 = e1
+	}
+	return path, nil
+}
+
+// IndexGlyphCache is a GlyphCache that also caches the glyphs substituted
+// by shaping, keyed by glyph index
+type IndexGlyphCache interface {
+	GlyphCache
+	// FetchIndex fetches the glyph index of f at scale with the width
+	// advance, converting it first if it isn't cached
+	FetchIndex(gc draw2d.GraphicContext, fontName string, f *truetype.Font, scale float64, index truetype.Index, advance float64) *Glyph
+}
+
+// FetchShapedGlyph returns the glyph of a shaped string. Glyphs mapped
+// directly from their rune come from glyphCache, substituted ones too if it
+// is an IndexGlyphCache, otherwise they are converted from the font f at
+// scale.
+func FetchShapedGlyph(gc draw2d.GraphicContext, glyphCache GlyphCache, fontName string, f *truetype.Font, scale float64, g ShapedGlyph) *Glyph {
+	if !g.Substituted {
+		return glyphCache.Fetch(gc, fontName, g.Rune)
+	}
+	if cache, ok := glyphCache.(IndexGlyphCache); ok {
+		return cache.FetchIndex(gc, fontName, f, scale, g.Index, g.Advance)
+	}
+	glyph := renderGlyphIndex(f, scale, g.Index)
+	glyph.Width = g.Advance
+	return glyph
+}
+
+// renderGlyphIndex converts the glyph index of f at scale, its width being
+// left to the caller
+func renderGlyphIndex(f *truetype.Font, scale float64, index truetype.Index) *Glyph {
+	path, err := GlyphPath(f, scale, index)
+	if err != nil {
+		path = &draw2d.Path{}
+	}
+	return &Glyph{Path: path}
+}
@@ -0,0 +1,157 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+)
+
+// DefaultGlyphCacheSize is the number of glyphs kept by the glyph cache a
+// graphic context creates for itself
+const DefaultGlyphCacheSize = 2048
+
+// GlyphCacheStats holds the counters of an LRUGlyphCache
+type GlyphCacheStats struct {
+	// Hits is the number of glyphs found in the cache
+	Hits uint64
+	// Misses is the number of glyphs rendered because they were not cached
+	Misses uint64
+	// Evictions is the number of glyphs removed to respect the capacity
+	Evictions uint64
+	// Len is the number of glyphs in the cache
+	Len int
+	// Capacity is the maximum number of glyphs in the cache
+	Capacity int
+}
+
+// LRUGlyphCache is a GlyphCache holding a bounded number of glyphs, the least
+// recently used glyphs are evicted first. It is safe for concurrent use and
+// can be shared by several graphic contexts: glyphs are keyed by font name,
+// resolution and rune, or glyph index for the glyphs substituted by shaping.
+type LRUGlyphCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[glyphKey]*list.Element
+	// order holds the entries, most recently used first
+	order                   *list.List
+	hits, misses, evictions uint64
+}
+
+type glyphKey struct {
+	fontName string
+	dpi      int
+	chr      rune
+	variant  GlyphVariant
+	// index is the glyph of the entries of substituted glyphs, which are
+	// keyed by index instead of chr
+	index   truetype.Index
+	byIndex bool
+}
+
+type glyphEntry struct {
+	key   glyphKey
+	glyph *Glyph
+}
+
+// NewLRUGlyphCache creates a glyph cache holding at most capacity glyphs,
+// DefaultGlyphCacheSize if capacity is not positive
+func NewLRUGlyphCache(capacity int) *LRUGlyphCache {
+	if capacity <= 0 {
+		capacity = DefaultGlyphCacheSize
+	}
+	return &LRUGlyphCache{
+		capacity: capacity,
+		entries:  make(map[glyphKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Fetch fetches a glyph from the cache, rendering it with gc first if it
+// isn't cached. Rendering happens without holding the cache lock, gc must
+// only be used by the calling goroutine.
+func (cache *LRUGlyphCache) Fetch(gc draw2d.GraphicContext, fontName string, chr rune) *Glyph {
+	key := glyphKey{fontName: fontName, dpi: gc.GetDPI(), chr: chr}
+	return cache.fetch(key, func() *Glyph {
+		return renderGlyph(gc, fontName, chr)
+	})
+}
+
+// FetchVariant fetches a hinted or shifted variant of the glyph of chr in f
+// at scale, converting it from f first if it isn't cached. The variants of a
+// glyph are cached separately.
+func (cache *LRUGlyphCache) FetchVariant(gc draw2d.GraphicContext, fontName string, f *truetype.Font, scale float64, chr rune, variant GlyphVariant) *Glyph {
+	key := glyphKey{fontName: fontName, dpi: gc.GetDPI(), chr: chr, variant: variant}
+	return cache.fetch(key, func() *Glyph {
+		return renderGlyphVariant(f, scale, f.Index(chr), variant)
+	})
+}
+
+// FetchIndex fetches the glyph index of f at scale, a glyph substituted by
+// shaping, converting it from f first if it isn't cached. Its width is
+// advance, the glyphs of the same index being cached once.
+func (cache *LRUGlyphCache) FetchIndex(gc draw2d.GraphicContext, fontName string, f *truetype.Font, scale float64, index truetype.Index, advance float64) *Glyph {
+	key := glyphKey{fontName: fontName, dpi: gc.GetDPI(), index: index, byIndex: true}
+	glyph := cache.fetch(key, func() *Glyph {
+		return renderGlyphIndex(f, scale, index)
+	})
+	glyph.Width = advance
+	return glyph
+}
+
+// fetch returns a copy of the glyph of key, calling render outside of the
+// lock if it isn't cached
+func (cache *LRUGlyphCache) fetch(key glyphKey, render func() *Glyph) *Glyph {
+	cache.mu.Lock()
+	if element, ok := cache.entries[key]; ok {
+		cache.order.MoveToFront(element)
+		cache.hits++
+		cache.mu.Unlock()
+		return element.Value.(*glyphEntry).glyph.Copy()
+	}
+	cache.misses++
+	cache.mu.Unlock()
+
+	glyph := render()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if element, ok := cache.entries[key]; ok {
+		// another goroutine rendered the same glyph meanwhile
+		cache.order.MoveToFront(element)
+		return element.Value.(*glyphEntry).glyph.Copy()
+	}
+	cache.entries[key] = cache.order.PushFront(&glyphEntry{key: key, glyph: glyph})
+	for cache.order.Len() > cache.capacity {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*glyphEntry).key)
+		cache.evictions++
+	}
+	return glyph.Copy()
+}
+
+// Stats returns the counters of the cache
+func (cache *LRUGlyphCache) Stats() GlyphCacheStats {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return GlyphCacheStats{
+		Hits:      cache.hits,
+		Misses:    cache.misses,
+		Evictions: cache.evictions,
+		Len:       cache.order.Len(),
+		Capacity:  cache.capacity,
+	}
+}
+
+// Clear removes all the glyphs from the cache, the counters are kept
+func (cache *LRUGlyphCache) Clear() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.entries = make(map[glyphKey]*list.Element)
+	cache.order.Init()
+}
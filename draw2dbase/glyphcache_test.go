@@ -0,0 +1,151 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+)
+
+// renderCounter renders every rune as a square as wide as the rune value
+// and counts the glyphs it renders
+type renderCounter struct {
+	draw2d.GraphicContext
+	path    draw2d.Path
+	dpi     int
+	renders *int64
+}
+
+func (gc *renderCounter) Save()                                  {}
+func (gc *renderCounter) Restore()                               {}
+func (gc *renderCounter) BeginPath()                             { gc.path.Clear() }
+func (gc *renderCounter) SetWritingMode(mode draw2d.WritingMode) {}
+func (gc *renderCounter) GetDPI() int {
+	return gc.dpi
+}
+func (gc *renderCounter) GetPath() draw2d.Path {
+	return *gc.path.Copy()
+}
+func (gc *renderCounter) CreateStringPath(s string, x, y float64) float64 {
+	atomic.AddInt64(gc.renders, 1)
+	w := float64([]rune(s)[0])
+	gc.path.MoveTo(x, y)
+	gc.path.LineTo(x+w, y)
+	gc.path.LineTo(x+w, y-w)
+	gc.path.Close()
+	return w
+}
+
+func newRenderCounter(dpi int) *renderCounter {
+	return &renderCounter{dpi: dpi, renders: new(int64)}
+}
+
+func TestLRUGlyphCache_Fetch(t *testing.T) {
+	cache := NewLRUGlyphCache(10)
+	gc := newRenderCounter(72)
+	glyph := cache.Fetch(gc, "font", 'a')
+	if glyph.Width != 'a' {
+		t.Errorf("glyph width is %v, want %v", glyph.Width, float64('a'))
+	}
+	glyph.Path.Clear()
+	if again := cache.Fetch(gc, "font", 'a'); len(again.Path.Points) == 0 {
+		t.Error("modifying a fetched glyph should not modify the cached one")
+	}
+	if *gc.renders != 1 {
+		t.Errorf("glyph rendered %d times, want 1", *gc.renders)
+	}
+	// the resolution and the font name are part of the key
+	cache.Fetch(newRenderCounter(96), "font", 'a')
+	cache.Fetch(gc, "other", 'a')
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 3 || stats.Len != 3 || stats.Capacity != 10 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestLRUGlyphCache_Eviction(t *testing.T) {
+	cache := NewLRUGlyphCache(3)
+	gc := newRenderCounter(72)
+	for _, r := range "abc" {
+		cache.Fetch(gc, "font", r)
+	}
+	// 'a' becomes the most recently used, 'b' is evicted by 'd'
+	cache.Fetch(gc, "font", 'a')
+	cache.Fetch(gc, "font", 'd')
+	*gc.renders = 0
+	cache.Fetch(gc, "font", 'a')
+	cache.Fetch(gc, "font", 'c')
+	if *gc.renders != 0 {
+		t.Error("'a' and 'c' should still be cached")
+	}
+	cache.Fetch(gc, "font", 'b')
+	if *gc.renders != 1 {
+		t.Error("'b' should have been evicted")
+	}
+	stats := cache.Stats()
+	if stats.Len != 3 || stats.Evictions != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	cache.Clear()
+	if stats := cache.Stats(); stats.Len != 0 || stats.Evictions != 2 {
+		t.Errorf("Clear should empty the cache and keep the counters, got %+v", stats)
+	}
+}
+
+func TestLRUGlyphCache_DefaultCapacity(t *testing.T) {
+	if capacity := NewLRUGlyphCache(0).Stats().Capacity; capacity != DefaultGlyphCacheSize {
+		t.Errorf("capacity is %d, want %d", capacity, DefaultGlyphCacheSize)
+	}
+}
+
+func TestLRUGlyphCache_Concurrent(t *testing.T) {
+	cache := NewLRUGlyphCache(50)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gc := newRenderCounter(72)
+			for j := 0; j < 500; j++ {
+				r := rune('a' + j%60)
+				if glyph := cache.Fetch(gc, "font", r); glyph.Width != float64(r) {
+					t.Errorf("glyph of %q has width %v", r, glyph.Width)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	stats := cache.Stats()
+	if stats.Hits+stats.Misses != 8*500 || stats.Len > 50 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestLRUGlyphCache_FetchIndex(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	cache := NewLRUGlyphCache(10)
+	gc := newRenderCounter(72)
+	index := f.Index('a')
+	shaped := ShapedGlyph{Index: index, Rune: 'b', Substituted: true, Advance: 7}
+	glyph := FetchShapedGlyph(gc, cache, "font", f, 64*12, shaped)
+	if glyph.Width != 7 || glyph.Path.IsEmpty() {
+		t.Fatalf("got glyph %+v, want the outline of the index with the advance", glyph)
+	}
+	shaped.Advance = 9
+	if again := FetchShapedGlyph(gc, cache, "font", f, 64*12, shaped); again.Width != 9 {
+		t.Errorf("got width %v, want the advance of the shaped glyph", again.Width)
+	}
+	// the glyph of an index does not collide with the one of a rune of the
+	// same value
+	cache.Fetch(gc, "font", rune(index))
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || *gc.renders != 1 {
+		t.Errorf("unexpected stats %+v, %d renders", stats, *gc.renders)
+	}
+}
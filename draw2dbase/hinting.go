@@ -0,0 +1,113 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// GlyphRendering controls how DrawString fits glyphs to the pixel grid of a
+// raster backend. The zero value draws unhinted glyphs at their exact
+// position.
+type GlyphRendering struct {
+	// Hinting fits the glyph outlines to the pixel grid, vertically only or
+	// in both directions
+	Hinting font.Hinting
+	// Subpixels is the number of horizontal positions a glyph can take
+	// within a pixel, each one cached as a glyph variant. Glyph origins
+	// are rounded to whole pixels when it is 1, or when it is 0 and the
+	// glyphs are hinted.
+	Subpixels int
+}
+
+// aligned returns true if glyph origins are rounded to the pixel grid
+func (rendering GlyphRendering) aligned() bool {
+	return rendering.Hinting != font.HintingNone || rendering.Subpixels > 0
+}
+
+// align rounds the device position of a glyph origin to the pixel grid. It
+// returns the whole pixel position and the variant of the glyph to draw
+// there.
+func (rendering GlyphRendering) align(x, y float64) (px, py float64, variant GlyphVariant) {
+	subpixels := math.Max(float64(rendering.Subpixels), 1)
+	x = math.Round(x*subpixels) / subpixels
+	px = math.Floor(x)
+	return px, math.Round(y), GlyphVariant{Hinting: rendering.Hinting, Offset: x - px}
+}
+
+// GlyphVariant is a glyph rendered with hinting or shifted right by a
+// fraction of pixel
+type GlyphVariant struct {
+	Hinting font.Hinting
+	// Offset is the horizontal shift of the outline, in [0, 1)
+	Offset float64
+}
+
+// VariantGlyphCache is a GlyphCache that also caches glyph variants
+type VariantGlyphCache interface {
+	GlyphCache
+	// FetchVariant fetches a variant of the glyph of chr in f at scale,
+	// rendering it first if it isn't cached
+	FetchVariant(gc draw2d.GraphicContext, fontName string, f *truetype.Font, scale float64, chr rune, variant GlyphVariant) *Glyph
+}
+
+// GlyphVariantPath returns the outline of a variant of the glyph index of f
+// at scale, and its advance width. freetype only implements full hinting,
+// vertical hinting keeps the horizontal coordinates of the unhinted outline.
+func GlyphVariantPath(f *truetype.Font, scale float64, index truetype.Index, variant GlyphVariant) (*draw2d.Path, float64, error) {
+	hinting := variant.Hinting
+	if hinting == font.HintingVertical {
+		hinting = font.HintingFull
+	}
+	glyphBuf := &truetype.GlyphBuf{}
+	if err := glyphBuf.Load(f, fixed.Int26_6(scale), index, hinting); err != nil {
+		return nil, 0, err
+	}
+	advance := glyphBuf.AdvanceWidth
+	if variant.Hinting == font.HintingVertical {
+		unhinted := &truetype.GlyphBuf{}
+		if err := unhinted.Load(f, fixed.Int26_6(scale), index, font.HintingNone); err != nil {
+			return nil, 0, err
+		}
+		if len(unhinted.Points) == len(glyphBuf.Points) {
+			for i := range glyphBuf.Points {
+				glyphBuf.Points[i].X = unhinted.Points[i].X
+			}
+		}
+		advance = unhinted.AdvanceWidth
+	}
+	path := &draw2d.Path{}
+	e0 := 0
+	for _, e1 := range glyphBuf.Ends {
+		DrawContour(path, glyphBuf.Points[e0:e1], variant.Offset, 0)
+		e0 = e1
+	}
+	return path, float64(advance) / 64, nil
+}
+
+// FetchGlyphVariant returns a variant of the glyph of a shaped string. It
+// comes from glyphCache if it is a VariantGlyphCache and the glyph is mapped
+// directly from its rune, otherwise it is converted from the font f at scale.
+func FetchGlyphVariant(gc draw2d.GraphicContext, glyphCache GlyphCache, fontName string, f *truetype.Font, scale float64, g ShapedGlyph, variant GlyphVariant) *Glyph {
+	if cache, ok := glyphCache.(VariantGlyphCache); ok && !g.Substituted {
+		return cache.FetchVariant(gc, fontName, f, scale, g.Rune, variant)
+	}
+	return renderGlyphVariant(f, scale, g.Index, variant)
+}
+
+func renderGlyphVariant(f *truetype.Font, scale float64, index truetype.Index, variant GlyphVariant) *Glyph {
+	path, width, err := GlyphVariantPath(f, scale, index, variant)
+	if err != nil {
+		path = &draw2d.Path{}
+	}
+	return &Glyph{
+		Path:  path,
+		Width: width,
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/font"
+)
+
+func TestGlyphRendering_Align(t *testing.T) {
+	tests := []struct {
+		rendering      GlyphRendering
+		x, y           float64
+		px, py, offset float64
+	}{
+		{GlyphRendering{Hinting: font.HintingFull}, 10.6, 20.4, 11, 20, 0},
+		{GlyphRendering{Subpixels: 1}, 10.4, 20.5, 10, 21, 0},
+		{GlyphRendering{Subpixels: 4}, 10.3, 20, 10, 20, 0.25},
+		{GlyphRendering{Subpixels: 4}, 10.9, 20, 11, 20, 0},
+		{GlyphRendering{Subpixels: 3}, -0.4, 0, -1, 0, 2.0 / 3},
+	}
+	for _, tt := range tests {
+		px, py, variant := tt.rendering.align(tt.x, tt.y)
+		if px != tt.px || py != tt.py || variant.Offset-tt.offset > 1e-9 || tt.offset-variant.Offset > 1e-9 {
+			t.Errorf("%+v.align(%v, %v) = %v, %v, %+v, want %v, %v, offset %v",
+				tt.rendering, tt.x, tt.y, px, py, variant, tt.px, tt.py, tt.offset)
+		}
+		if variant.Hinting != tt.rendering.Hinting {
+			t.Errorf("the variant should keep the hinting %v, got %v", tt.rendering.Hinting, variant.Hinting)
+		}
+	}
+	if (GlyphRendering{}).aligned() {
+		t.Error("the zero GlyphRendering should draw glyphs at their exact position")
+	}
+}
+
+func TestGlyphVariantPath(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	scale := 11.0 * 64
+	index := f.Index('e')
+	unhinted, _, err := GlyphVariantPath(f, scale, index, GlyphVariant{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, fullAdvance, _ := GlyphVariantPath(f, scale, index, GlyphVariant{Hinting: font.HintingFull})
+	vertical, _, _ := GlyphVariantPath(f, scale, index, GlyphVariant{Hinting: font.HintingVertical})
+	shifted, _, _ := GlyphVariantPath(f, scale, index, GlyphVariant{Offset: 0.5})
+	if fullAdvance != float64(int(fullAdvance)) {
+		t.Errorf("the hinted advance %v should be a whole number of pixels", fullAdvance)
+	}
+	if len(vertical.Points) != len(unhinted.Points) || len(full.Points) != len(unhinted.Points) {
+		t.Fatalf("variants should have the same points, got %d %d %d", len(unhinted.Points), len(full.Points), len(vertical.Points))
+	}
+	moved := false
+	for i := 0; i < len(unhinted.Points); i += 2 {
+		if vertical.Points[i] != unhinted.Points[i] || vertical.Points[i+1] != full.Points[i+1] {
+			t.Fatalf("vertical hinting should only move the points vertically, point %d", i/2)
+		}
+		if shifted.Points[i] != unhinted.Points[i]+0.5 || shifted.Points[i+1] != unhinted.Points[i+1] {
+			t.Fatalf("the shifted variant should move the points by half a pixel, point %d", i/2)
+		}
+		moved = moved || full.Points[i+1] != unhinted.Points[i+1]
+	}
+	if !moved {
+		t.Error("hinting should move some points of the outline")
+	}
+}
+
+func TestLRUGlyphCache_FetchVariant(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	cache := NewLRUGlyphCache(10)
+	gc := newRenderCounter(72)
+	g := ShapedGlyph{Index: f.Index('a'), Rune: 'a', Base: -1}
+	half := GlyphVariant{Hinting: font.HintingFull, Offset: 0.5}
+	FetchGlyphVariant(gc, cache, "font", f, 12*64, g, GlyphVariant{Hinting: font.HintingFull})
+	FetchGlyphVariant(gc, cache, "font", f, 12*64, g, half)
+	glyph := FetchGlyphVariant(gc, cache, "font", f, 12*64, g, half)
+	if stats := cache.Stats(); stats.Len != 2 || stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("variants should be cached separately, got %+v", stats)
+	}
+	if *gc.renders != 0 {
+		t.Error("variants are converted from the font, not rendered with the graphic context")
+	}
+	if len(glyph.Path.Points) == 0 || glyph.Width <= 0 {
+		t.Errorf("unexpected variant %+v", glyph)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"image"
+
+	"github.com/llgcode/draw2d"
+)
+
+// DrawImageScaled draws img at x, y, scaled and aligned as specified by
+// scaling, with gc.DrawImageRect
+func DrawImageScaled(gc draw2d.GraphicContext, img image.Image, x, y float64, scaling draw2d.ImageScaling) {
+	bounds := img.Bounds()
+	x0, y0, x1, y1 := scaling.Rect(x, y, float64(bounds.Dx()), float64(bounds.Dy()))
+	gc.DrawImageRect(img, bounds, [4]float64{x0, y0, x1, y1})
+}
+
+// DrawImageRect draws the rectangle srcRect of img scaled to dstRect with
+// gc.DrawImage, the current transformation being composed with the scaling
+// for the time of the drawing. The parts of srcRect outside of img are not
+// drawn.
+func DrawImageRect(gc draw2d.GraphicContext, img image.Image, srcRect image.Rectangle, dstRect [4]float64) {
+	sub := SubImage(img, srcRect)
+	if srcRect.Empty() || sub.Bounds().Empty() {
+		return
+	}
+	tr := gc.GetMatrixTransform()
+	src := [4]float64{float64(srcRect.Min.X), float64(srcRect.Min.Y), float64(srcRect.Max.X), float64(srcRect.Max.Y)}
+	gc.ComposeMatrixTransform(draw2d.NewMatrixFromRects(src, dstRect))
+	gc.DrawImage(sub)
+	gc.SetMatrixTransform(tr)
+}
+
+// SubImage returns the part of img within r, which keeps the coordinates
+// of img
+func SubImage(img image.Image, r image.Rectangle) image.Image {
+	if img, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return img.SubImage(r)
+	}
+	return subImage{img, r.Intersect(img.Bounds())}
+}
+
+// subImage is the part of an image without SubImage method within rect
+type subImage struct {
+	image.Image
+	rect image.Rectangle
+}
+
+func (img subImage) Bounds() image.Rectangle {
+	return img.rect
+}
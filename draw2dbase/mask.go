@@ -0,0 +1,97 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"image"
+	"math"
+
+	"github.com/llgcode/draw2d"
+)
+
+// Mask is the mask set by SetMask, SetLuminanceMask or MaskWithPath. It is
+// either an image or the fill of paths. A mask is not modified once set,
+// the contexts saved by Save share it.
+type Mask struct {
+	// Image is the mask image, nil for a mask of paths
+	Image image.Image
+	// Luminance selects the modulation of the drawings by the luminance of
+	// the image times its alpha instead of its alpha
+	Luminance bool
+	// Paths are filled with FillRule to make the mask of paths
+	Paths    []*draw2d.Path
+	FillRule draw2d.FillRule
+	// Tr transforms the image or the paths to device space
+	Tr draw2d.Matrix
+}
+
+// Bounds returns the bounds in device space of the area not masked out,
+// which contain the transformed image or the transformed paths. ok is false
+// if the mask is empty.
+func (m *Mask) Bounds() (x0, y0, x1, y1 float64, ok bool) {
+	if m.Image != nil {
+		b := m.Image.Bounds()
+		if b.Empty() {
+			return 0, 0, 0, 0, false
+		}
+		x0, y0, x1, y1 = m.Tr.TransformRectangle(float64(b.Min.X), float64(b.Min.Y), float64(b.Max.X), float64(b.Max.Y))
+		return x0, y0, x1, y1, true
+	}
+	x0, y0, x1, y1, ok = PathsBounds(m.Paths)
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+	x0, y0, x1, y1 = m.Tr.TransformRectangle(x0, y0, x1, y1)
+	return x0, y0, x1, y1, true
+}
+
+// PathsBounds returns the bounds of the points of paths, which contain
+// their curves
+func PathsBounds(paths []*draw2d.Path) (x0, y0, x1, y1 float64, ok bool) {
+	x0, y0, x1, y1 = math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	add := func(x, y float64) {
+		x0, y0, x1, y1 = math.Min(x0, x), math.Min(y0, y), math.Max(x1, x), math.Max(y1, y)
+	}
+	for _, p := range paths {
+		i := 0
+		for _, cmp := range p.Components {
+			switch cmp {
+			case draw2d.MoveToCmp, draw2d.LineToCmp:
+				add(p.Points[i], p.Points[i+1])
+				i += 2
+			case draw2d.QuadCurveToCmp:
+				add(p.Points[i], p.Points[i+1])
+				add(p.Points[i+2], p.Points[i+3])
+				i += 4
+			case draw2d.CubicCurveToCmp:
+				add(p.Points[i], p.Points[i+1])
+				add(p.Points[i+2], p.Points[i+3])
+				add(p.Points[i+4], p.Points[i+5])
+				i += 6
+			case draw2d.ArcToCmp:
+				// the bounds of the ellipse of the arc
+				cx, cy, rx, ry := p.Points[i], p.Points[i+1], p.Points[i+2], p.Points[i+3]
+				add(cx-rx, cy-ry)
+				add(cx+rx, cy+ry)
+				i += 6
+			}
+		}
+	}
+	return x0, y0, x1, y1, x0 <= x1 && y0 <= y1
+}
+
+// StrokeMargin returns the distance by which the stroke of a path with
+// lineWidth, cap and join may exceed the bounds of its points. Miter joins
+// reach half the line width times the miter limit, 10 by default in pdf.
+func StrokeMargin(lineWidth float64, cap draw2d.LineCap, join draw2d.LineJoin) float64 {
+	const miterLimit = 10
+	switch {
+	case join == draw2d.MiterJoin:
+		return math.Max(lineWidth/2*miterLimit, lineWidth)
+	case cap == draw2d.SquareCap:
+		// the corners of the caps
+		return lineWidth / 2 * math.Sqrt2
+	}
+	return lineWidth / 2
+}
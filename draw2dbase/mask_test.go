@@ -0,0 +1,31 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+)
+
+func TestStrokeMargin(t *testing.T) {
+	for _, test := range []struct {
+		lineWidth float64
+		cap       draw2d.LineCap
+		join      draw2d.LineJoin
+		want      float64
+	}{
+		{4, draw2d.ButtCap, draw2d.RoundJoin, 2},
+		{4, draw2d.RoundCap, draw2d.BevelJoin, 2},
+		{4, draw2d.SquareCap, draw2d.RoundJoin, 2 * math.Sqrt2},
+		// half the line width times the miter limit
+		{4, draw2d.ButtCap, draw2d.MiterJoin, 20},
+		{0, draw2d.ButtCap, draw2d.MiterJoin, 0},
+	} {
+		if got := StrokeMargin(test.lineWidth, test.cap, test.join); got != test.want {
+			t.Errorf("StrokeMargin(%v, %v, %v): got %v, want %v", test.lineWidth, test.cap, test.join, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"log"
+	"math"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// FontMetrics holds the vertical metrics of a font at a given size, in pixels.
+// Ascent, Descent, LineGap, CapHeight and XHeight are positive distances from
+// the baseline. The decoration positions are offsets from the baseline to the
+// top of the lines, y going downwards as in the drawing coordinates.
+type FontMetrics struct {
+	Ascent    float64
+	Descent   float64
+	LineGap   float64
+	CapHeight float64
+	XHeight   float64
+
+	UnderlinePosition  float64
+	UnderlineThickness float64
+	StrikeoutPosition  float64
+	StrikeoutThickness float64
+}
+
+// ApproximateFontMetrics returns metrics for a font of which only the size of
+// the em square and the ascent and descent are known, the other values are
+// usual proportions of the em.
+func ApproximateFontMetrics(em, ascent, descent float64) FontMetrics {
+	m := FontMetrics{
+		Ascent:    ascent,
+		Descent:   descent,
+		CapHeight: 0.7 * em,
+		XHeight:   0.5 * em,
+	}
+	m.approximateDecorations(em)
+	return m
+}
+
+// approximateDecorations sets the decoration lines the font does not define
+func (m *FontMetrics) approximateDecorations(em float64) {
+	if m.UnderlineThickness <= 0 {
+		m.UnderlineThickness = em / 20
+		m.UnderlinePosition = em / 10
+	}
+	if m.StrikeoutThickness <= 0 {
+		m.StrikeoutThickness = m.UnderlineThickness
+		xHeight := m.XHeight
+		if xHeight <= 0 {
+			xHeight = em / 2
+		}
+		m.StrikeoutPosition = -(xHeight + m.StrikeoutThickness) / 2
+	}
+}
+
+// GetFontMetrics returns the vertical metrics of f at scale, the number of
+// 26.6 fixed point units in 1 em as in ContextStack.Scale. The hhea and OS/2
+// tables are used when the font was parsed with draw2d.ParseFont, otherwise
+// the line gap is zero, the cap and x heights are measured on the 'H' and
+// 'x' glyphs and the decoration lines are approximated. The underline is read
+// from the post table and the strikeout from the OS/2 table.
+func GetFontMetrics(f *truetype.Font, scale float64) FontMetrics {
+	var m FontMetrics
+	// pixels per font unit
+	px := scale / 64 / float64(f.FUnitsPerEm())
+	data := draw2d.GetFontFileData(f)
+
+	hhea := FontTable(data, "hhea")
+	if ascent, ok := int16At(hhea, 4); ok {
+		descent, _ := int16At(hhea, 6)
+		lineGap, _ := int16At(hhea, 8)
+		m.Ascent = float64(ascent) * px
+		m.Descent = -float64(descent) * px
+		m.LineGap = float64(lineGap) * px
+	} else {
+		faceMetrics := truetype.NewFace(f, &truetype.Options{Size: scale / 64, DPI: 72}).Metrics()
+		m.Ascent = float64(faceMetrics.Ascent) / 64
+		m.Descent = float64(faceMetrics.Descent) / 64
+	}
+
+	// sxHeight and sCapHeight exist from version 2 of the OS/2 table
+	os2 := FontTable(data, "OS/2")
+	if version, ok := int16At(os2, 0); ok && version >= 2 {
+		if xHeight, ok := int16At(os2, 86); ok {
+			m.XHeight = float64(xHeight) * px
+		}
+		if capHeight, ok := int16At(os2, 88); ok {
+			m.CapHeight = float64(capHeight) * px
+		}
+	}
+	if m.XHeight == 0 {
+		m.XHeight = glyphTop(f, scale, 'x')
+	}
+	if m.CapHeight == 0 {
+		m.CapHeight = glyphTop(f, scale, 'H')
+	}
+
+	post := FontTable(data, "post")
+	if position, ok := int16At(post, 8); ok {
+		thickness, _ := int16At(post, 10)
+		m.UnderlinePosition = -float64(position) * px
+		m.UnderlineThickness = float64(thickness) * px
+	}
+	if size, ok := int16At(os2, 26); ok {
+		position, _ := int16At(os2, 28)
+		m.StrikeoutPosition = -float64(position) * px
+		m.StrikeoutThickness = float64(size) * px
+	}
+	m.approximateDecorations(scale / 64)
+	return m
+}
+
+// glyphTop returns the height above the baseline of the glyph of r
+func glyphTop(f *truetype.Font, scale float64, r rune) float64 {
+	index := f.Index(r)
+	if index == 0 {
+		return 0
+	}
+	glyphBuf := &truetype.GlyphBuf{}
+	if err := glyphBuf.Load(f, fixed.Int26_6(scale), index, font.HintingNone); err != nil {
+		return 0
+	}
+	return float64(glyphBuf.Bounds.Max.Y) / 64
+}
+
+// MeasureString returns the metrics of text laid out in mode with f at scale,
+// the number of 26.6 fixed point units in 1 em as in ContextStack.Scale. The
+// ink bounds include the lines of decoration.
+func MeasureString(f *truetype.Font, scale float64, mode draw2d.WritingMode, decoration draw2d.TextDecoration, text string) draw2d.TextMetrics {
+	fm := GetFontMetrics(f, scale)
+	metrics := draw2d.TextMetrics{
+		Ascent:    fm.Ascent,
+		Descent:   fm.Descent,
+		LineGap:   fm.LineGap,
+		CapHeight: fm.CapHeight,
+		XHeight:   fm.XHeight,
+	}
+	glyphBuf := &truetype.GlyphBuf{}
+	top, left, bottom, right := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	glyphs, advance := ShapeString(f, scale, mode, text)
+	for _, g := range glyphs {
+		if err := glyphBuf.Load(f, fixed.Int26_6(scale), g.Index, font.HintingNone); err != nil {
+			log.Println(err)
+		} else if n := len(glyphBuf.Ends); n > 0 {
+			for _, p := range glyphBuf.Points[:glyphBuf.Ends[n-1]] {
+				x, y := GlyphPoint(p, g)
+				top = math.Min(top, y)
+				bottom = math.Max(bottom, y)
+				left = math.Min(left, x)
+				right = math.Max(right, x)
+			}
+		}
+		metrics.Glyphs = append(metrics.Glyphs, draw2d.GlyphMetrics{Rune: g.Rune, X: g.X, Y: g.Y, Advance: g.Advance})
+	}
+	metrics.Width = advance
+	if l, t, r, b, ok := DecorationBounds(fm, decoration, mode, advance); ok {
+		top, left, bottom, right = math.Min(top, t), math.Min(left, l), math.Max(bottom, b), math.Max(right, r)
+	}
+	if left <= right {
+		metrics.Left, metrics.Top, metrics.Right, metrics.Bottom = left, top, right, bottom
+	}
+	return metrics
+}
@@ -0,0 +1,148 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func loadTestFont(t *testing.T, parse func([]byte) (*truetype.Font, error)) *truetype.Font {
+	data, err := os.ReadFile("../resource/font/luxisr.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFontTable(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	data := draw2d.GetFontFileData(f)
+	if data == nil {
+		t.Fatal("GetFontFileData should return the data given to ParseFont")
+	}
+	if len(FontTable(data, "hhea")) != 36 {
+		t.Error("hhea table should be 36 bytes long")
+	}
+	if FontTable(data, "none") != nil {
+		t.Error("FontTable should return nil for a missing table")
+	}
+	if FontTable(data[:10], "hhea") != nil {
+		t.Error("FontTable should return nil for truncated data")
+	}
+}
+
+func TestGetFontMetrics(t *testing.T) {
+	scale := 20.0 * 64
+	for name, parse := range map[string]func([]byte) (*truetype.Font, error){
+		"ParseFont":      draw2d.ParseFont,
+		"truetype.Parse": truetype.Parse,
+	} {
+		m := GetFontMetrics(loadTestFont(t, parse), scale)
+		if !(m.Ascent > m.CapHeight && m.CapHeight > m.XHeight && m.XHeight > 0) {
+			t.Errorf("%s: unexpected metrics %+v", name, m)
+		}
+		if m.Descent <= 0 || m.Ascent+m.Descent < 20 {
+			t.Errorf("%s: unexpected ascent and descent %+v", name, m)
+		}
+	}
+}
+
+func TestMeasureString(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	m := MeasureString(f, 20*64, draw2d.WritingModeHorizontal, draw2d.TextDecorationNone, "AVxg")
+	if len(m.Glyphs) != 4 {
+		t.Fatalf("expected 4 glyphs, got %d", len(m.Glyphs))
+	}
+	last := m.Glyphs[3]
+	if math.Abs(last.X+last.Advance-m.Width) > 1e-9 {
+		t.Errorf("last glyph should end at the text width: %+v, width %v", last, m.Width)
+	}
+	if m.Top >= 0 || m.Bottom <= 0 {
+		t.Errorf("ink bounds of 'AVxg' should cross the baseline: %v %v", m.Top, m.Bottom)
+	}
+	if -m.Top > m.Ascent+1e-9 || m.Left < -1 || m.Right > m.Width+1 {
+		t.Errorf("ink bounds %v %v %v %v exceed the font metrics", m.Left, m.Top, m.Right, m.Bottom)
+	}
+
+	empty := MeasureString(f, 20*64, draw2d.WritingModeHorizontal, draw2d.TextDecorationNone, "")
+	if empty.Width != 0 || empty.Left != 0 || empty.Right != 0 || empty.Ascent != m.Ascent {
+		t.Errorf("unexpected metrics for empty string %+v", empty)
+	}
+}
+
+func TestGetFontMetrics_Decorations(t *testing.T) {
+	f, err := draw2d.ParseFont(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// one pixel per font unit
+	m := GetFontMetrics(f, float64(f.FUnitsPerEm())*64)
+	if m.UnderlinePosition != 275 || m.UnderlineThickness != 50 {
+		t.Errorf("underline should be read from the post table, got %+v", m)
+	}
+	if m.StrikeoutPosition != -512 || m.StrikeoutThickness != 102 {
+		t.Errorf("strikeout should be read from the OS/2 table, got %+v", m)
+	}
+
+	// luxi sans defines neither, the lines are approximated
+	m = GetFontMetrics(loadTestFont(t, draw2d.ParseFont), 20*64)
+	if m.UnderlineThickness != 1 || m.UnderlinePosition != 2 {
+		t.Errorf("unexpected approximated underline %+v", m)
+	}
+	if middle := m.StrikeoutPosition + m.StrikeoutThickness/2; math.Abs(middle+m.XHeight/2) > 1e-9 {
+		t.Errorf("strikeout should be centered on half the x height, got %+v", m)
+	}
+}
+
+func TestDecorationLines(t *testing.T) {
+	m := ApproximateFontMetrics(20, 16, 4)
+	if lines := DecorationLines(m, draw2d.TextDecorationNone); len(lines) != 0 {
+		t.Errorf("no lines expected, got %v", lines)
+	}
+	lines := DecorationLines(m, draw2d.TextDecorationUnderline|draw2d.TextDecorationOverline|draw2d.TextDecorationLineThrough)
+	want := []DecorationLine{{2, 3}, {-16, -15}, {-5.5, -4.5}}
+	if len(lines) != len(want) {
+		t.Fatalf("got %v, want %v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d is %v, want %v", i, lines[i], want[i])
+		}
+	}
+
+	left, top, right, bottom, ok := DecorationBounds(m, draw2d.TextDecorationUnderline|draw2d.TextDecorationOverline, draw2d.WritingModeHorizontal, 50)
+	if !ok || left != 0 || top != -16 || right != 50 || bottom != 3 {
+		t.Errorf("unexpected bounds %v %v %v %v %v", left, top, right, bottom, ok)
+	}
+	// the sideways baseline of vertical text is 6 pixels left of the center line
+	left, top, right, bottom, ok = DecorationBounds(m, draw2d.TextDecorationUnderline|draw2d.TextDecorationOverline, draw2d.WritingModeVertical, 50)
+	if !ok || left != -9 || top != 0 || right != 10 || bottom != 50 {
+		t.Errorf("unexpected vertical bounds %v %v %v %v %v", left, top, right, bottom, ok)
+	}
+	if _, _, _, _, ok := DecorationBounds(m, draw2d.TextDecorationUnderline, draw2d.WritingModeHorizontal, 0); ok {
+		t.Error("empty text should have no decoration bounds")
+	}
+}
+
+func TestMeasureString_Decorations(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	plain := MeasureString(f, 20*64, draw2d.WritingModeHorizontal, draw2d.TextDecorationNone, "ace")
+	underlined := MeasureString(f, 20*64, draw2d.WritingModeHorizontal, draw2d.TextDecorationUnderline|draw2d.TextDecorationOverline, "ace")
+	if underlined.Bottom <= plain.Bottom || underlined.Top >= plain.Top {
+		t.Errorf("decorations should extend the ink bounds: %+v %+v", plain, underlined)
+	}
+	if underlined.Left != 0 || underlined.Right != underlined.Width {
+		t.Errorf("decorations span the advance of the text: %+v", underlined)
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"weak"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+)
+
+// Readers for the OpenType layout tables GSUB, GPOS and GDEF.
+// See https://docs.microsoft.com/typography/opentype/spec/chapter2
+// Only the lookups needed by the shaper are supported: single and ligature
+// substitutions, mark to base and mark to mark attachments.
+
+// otTable is a slice of a layout table, reads out of its bounds return zero
+type otTable []byte
+
+func (t otTable) u16(i int) int {
+	if i < 0 || i+2 > len(t) {
+		return 0
+	}
+	return int(u16(t, i))
+}
+
+func (t otTable) i16(i int) int {
+	return int(int16(t.u16(i)))
+}
+
+func (t otTable) u32(i int) int {
+	if i < 0 || i+4 > len(t) {
+		return 0
+	}
+	return int(u32(t, i))
+}
+
+func (t otTable) tag(i int) string {
+	if i < 0 || i+4 > len(t) {
+		return ""
+	}
+	return string(t[i : i+4])
+}
+
+// sub returns the table starting at offset, nil if offset is null or
+// out of bounds
+func (t otTable) sub(offset int) otTable {
+	if offset <= 0 || offset >= len(t) {
+		return nil
+	}
+	return t[offset:]
+}
+
+// coverage returns the coverage index of glyph, or -1 if it is not covered
+func (t otTable) coverage(glyph truetype.Index) int {
+	g := int(glyph)
+	switch t.u16(0) {
+	case 1:
+		count := t.u16(2)
+		i := sort.Search(count, func(i int) bool { return t.u16(4+2*i) >= g })
+		if i < count && t.u16(4+2*i) == g {
+			return i
+		}
+	case 2:
+		count := t.u16(2)
+		i := sort.Search(count, func(i int) bool { return t.u16(4+6*i+2) >= g })
+		if i < count && t.u16(4+6*i) <= g {
+			return t.u16(4+6*i+4) + g - t.u16(4+6*i)
+		}
+	}
+	return -1
+}
+
+// class returns the class of glyph in a class definition table
+func (t otTable) class(glyph truetype.Index) int {
+	g := int(glyph)
+	switch t.u16(0) {
+	case 1:
+		start, count := t.u16(2), t.u16(4)
+		if g >= start && g < start+count {
+			return t.u16(6 + 2*(g-start))
+		}
+	case 2:
+		count := t.u16(2)
+		i := sort.Search(count, func(i int) bool { return t.u16(4+6*i+2) >= g })
+		if i < count && t.u16(4+6*i) <= g {
+			return t.u16(4 + 6*i + 4)
+		}
+	}
+	return 0
+}
+
+// anchor returns the coordinates of an anchor table in font units
+func (t otTable) anchor() (x, y int) {
+	return t.i16(2), t.i16(4)
+}
+
+// otLookup is a lookup of a GSUB or GPOS table with its subtables resolved
+type otLookup struct {
+	kind      int
+	flag      int
+	subtables []otTable
+}
+
+const lookupIgnoreMarks = 0x0008
+
+// otLayout holds the layout tables of a font
+type otLayout struct {
+	gsub, gpos, gdef otTable
+	mu               sync.Mutex
+	lookups          map[string][]otLookup
+}
+
+// otLayouts maps weak pointers to fonts to their layout tables, the entries
+// are deleted when the fonts are collected
+var otLayouts sync.Map
+
+// getLayout returns the layout tables of f, read from the data it was parsed
+// from with draw2d.ParseFont.
+func getLayout(f *truetype.Font) *otLayout {
+	key := weak.Make(f)
+	if layout, ok := otLayouts.Load(key); ok {
+		return layout.(*otLayout)
+	}
+	data := draw2d.GetFontFileData(f)
+	layout := &otLayout{
+		gsub:    otTable(FontTable(data, "GSUB")),
+		gpos:    otTable(FontTable(data, "GPOS")),
+		gdef:    otTable(FontTable(data, "GDEF")),
+		lookups: make(map[string][]otLookup),
+	}
+	actual, loaded := otLayouts.LoadOrStore(key, layout)
+	if !loaded {
+		runtime.AddCleanup(f, func(key weak.Pointer[truetype.Font]) {
+			otLayouts.Delete(key)
+		}, key)
+	}
+	return actual.(*otLayout)
+}
+
+// isMark returns true if the GDEF table classifies glyph as a mark,
+// ok is false if the font has no glyph class definition.
+func (layout *otLayout) isMark(glyph truetype.Index) (mark, ok bool) {
+	classDef := layout.gdef.sub(layout.gdef.u16(4))
+	if classDef == nil {
+		return false, false
+	}
+	return classDef.class(glyph) == 3, true
+}
+
+// featureLookups returns the lookups of table implementing feature for
+// script, in lookup list order. The default script is used if the font has
+// no specific entry for script.
+func (layout *otLayout) featureLookups(table otTable, tableTag, script, feature string) []otLookup {
+	key := tableTag + script + feature
+	layout.mu.Lock()
+	defer layout.mu.Unlock()
+	if lookups, ok := layout.lookups[key]; ok {
+		return lookups
+	}
+	lookups := readFeatureLookups(table, tableTag, script, feature)
+	layout.lookups[key] = lookups
+	return lookups
+}
+
+func readFeatureLookups(table otTable, tableTag, script, feature string) []otLookup {
+	if table == nil {
+		return nil
+	}
+	scriptList := table.sub(table.u16(4))
+	featureList := table.sub(table.u16(6))
+	lookupList := table.sub(table.u16(8))
+
+	// find the script, falling back on the default one
+	var scriptTable otTable
+	for _, tag := range []string{script, "DFLT", "latn"} {
+		for i := 0; i < scriptList.u16(0); i++ {
+			if scriptList.tag(2+6*i) == tag {
+				scriptTable = scriptList.sub(scriptList.u16(2 + 6*i + 4))
+				break
+			}
+		}
+		if scriptTable != nil {
+			break
+		}
+	}
+	langSys := scriptTable.sub(scriptTable.u16(0))
+	if langSys == nil {
+		return nil
+	}
+
+	var indices []int
+	for i := 0; i < langSys.u16(4); i++ {
+		featureIndex := langSys.u16(6 + 2*i)
+		if featureList.tag(2+6*featureIndex) != feature {
+			continue
+		}
+		featureTable := featureList.sub(featureList.u16(2 + 6*featureIndex + 4))
+		for j := 0; j < featureTable.u16(2); j++ {
+			indices = append(indices, featureTable.u16(4+2*j))
+		}
+	}
+	sort.Ints(indices)
+
+	extension := 7
+	if tableTag == "GPOS" {
+		extension = 9
+	}
+	var lookups []otLookup
+	for i, index := range indices {
+		if i > 0 && index == indices[i-1] {
+			continue
+		}
+		lookupTable := lookupList.sub(lookupList.u16(2 + 2*index))
+		lookup := otLookup{kind: lookupTable.u16(0), flag: lookupTable.u16(2)}
+		for j := 0; j < lookupTable.u16(4); j++ {
+			subtable := lookupTable.sub(lookupTable.u16(6 + 2*j))
+			if lookup.kind == extension {
+				lookup.kind = subtable.u16(2)
+				subtable = subtable.sub(subtable.u32(4))
+			}
+			if subtable != nil {
+				lookup.subtables = append(lookup.subtables, subtable)
+			}
+		}
+		lookups = append(lookups, lookup)
+	}
+	return lookups
+}
+
+// singleSubstitution applies a GSUB lookup of type 1 to glyph
+func singleSubstitution(subtable otTable, glyph truetype.Index) (truetype.Index, bool) {
+	i := subtable.sub(subtable.u16(2)).coverage(glyph)
+	if i < 0 {
+		return glyph, false
+	}
+	switch subtable.u16(0) {
+	case 1:
+		return truetype.Index(uint16(int(glyph) + subtable.i16(4))), true
+	case 2:
+		if i < subtable.u16(4) {
+			return truetype.Index(subtable.u16(6 + 2*i)), true
+		}
+	}
+	return glyph, false
+}
+
+// ligatureSubstitution applies a GSUB lookup of type 4 to the glyphs
+// starting with first, next returns the following glyphs to match. It returns
+// the ligature glyph and the number of glyphs it replaces.
+func ligatureSubstitution(subtable otTable, first truetype.Index, next func(n int) (truetype.Index, bool)) (truetype.Index, int) {
+	i := subtable.sub(subtable.u16(2)).coverage(first)
+	if i < 0 || subtable.u16(0) != 1 || i >= subtable.u16(4) {
+		return first, 0
+	}
+	ligatureSet := subtable.sub(subtable.u16(6 + 2*i))
+ligatures:
+	for j := 0; j < ligatureSet.u16(0); j++ {
+		ligature := ligatureSet.sub(ligatureSet.u16(2 + 2*j))
+		count := ligature.u16(2)
+		if count == 0 {
+			continue
+		}
+		for k := 1; k < count; k++ {
+			glyph, ok := next(k)
+			if !ok || int(glyph) != ligature.u16(4+2*(k-1)) {
+				continue ligatures
+			}
+		}
+		return truetype.Index(ligature.u16(0)), count
+	}
+	return first, 0
+}
+
+// markAttachment applies a GPOS lookup of type 4 or 6 and returns the
+// offset in font units from the base origin to the mark origin
+func markAttachment(subtable otTable, mark, base truetype.Index) (dx, dy int, ok bool) {
+	if subtable.u16(0) != 1 {
+		return 0, 0, false
+	}
+	markIndex := subtable.sub(subtable.u16(2)).coverage(mark)
+	baseIndex := subtable.sub(subtable.u16(4)).coverage(base)
+	if markIndex < 0 || baseIndex < 0 {
+		return 0, 0, false
+	}
+	classCount := subtable.u16(6)
+	markArray := subtable.sub(subtable.u16(8))
+	baseArray := subtable.sub(subtable.u16(10))
+	if markIndex >= markArray.u16(0) || baseIndex >= baseArray.u16(0) {
+		return 0, 0, false
+	}
+	class := markArray.u16(2 + 4*markIndex)
+	markAnchor := markArray.sub(markArray.u16(2 + 4*markIndex + 2))
+	if class >= classCount || markAnchor == nil {
+		return 0, 0, false
+	}
+	baseAnchor := baseArray.sub(baseArray.u16(2 + 2*(baseIndex*classCount+class)))
+	if baseAnchor == nil {
+		return 0, 0, false
+	}
+	mx, my := markAnchor.anchor()
+	bx, by := baseAnchor.anchor()
+	return bx - mx, by - my, true
+}
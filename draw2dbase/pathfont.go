@@ -0,0 +1,154 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+
+	"github.com/llgcode/draw2d"
+)
+
+// LoadPathFont returns the bitmap or stroke font selected by fontData, if
+// cache is a draw2d.PathFontCache and has one
+func LoadPathFont(cache draw2d.FontCache, fontData draw2d.FontData) (draw2d.PathFont, bool) {
+	pathFonts, ok := cache.(draw2d.PathFontCache)
+	if !ok {
+		return nil, false
+	}
+	font, err := pathFonts.LoadPathFont(fontData)
+	return font, err == nil
+}
+
+// PathFontScale returns the size of a font unit of font drawn with an em of
+// size pixels. Bitmap fonts are scaled by a whole number, at least 1, so that
+// their pixels stay square and sharp.
+func PathFontScale(font draw2d.PathFont, size float64) float64 {
+	_, _, em := font.Metrics()
+	if em <= 0 {
+		return 1
+	}
+	if _, ok := font.(*draw2d.BitmapFont); ok {
+		return math.Max(1, math.Round(size/em))
+	}
+	return size / em
+}
+
+// PathFontMetrics returns the metrics of font drawn with font units of size
+// scale, the decoration lines are approximated
+func PathFontMetrics(font draw2d.PathFont, scale float64) FontMetrics {
+	ascent, descent, em := font.Metrics()
+	return ApproximateFontMetrics(em*scale, ascent*scale, descent*scale)
+}
+
+// layoutPathFont calls glyph with the path and the position of each glyph of
+// text, from left to right without kerning, and returns the advance of the
+// text. Runes without glyph are skipped.
+func layoutPathFont(font draw2d.PathFont, scale float64, text string, glyph func(r rune, path *draw2d.Path, x, advance float64)) float64 {
+	x := 0.0
+	for _, r := range text {
+		path, advance, ok := font.Glyph(r)
+		if !ok {
+			continue
+		}
+		glyph(r, path, x, advance*scale)
+		x += advance * scale
+	}
+	return x
+}
+
+// addScaledPath appends path scaled by scale and translated by (x, y)
+func addScaledPath(dst draw2d.PathBuilder, path *draw2d.Path, scale, x, y float64) {
+	i := 0
+	for _, cmp := range path.Components {
+		ps := path.Points[i:]
+		switch cmp {
+		case draw2d.MoveToCmp:
+			dst.MoveTo(ps[0]*scale+x, ps[1]*scale+y)
+			i += 2
+		case draw2d.LineToCmp:
+			dst.LineTo(ps[0]*scale+x, ps[1]*scale+y)
+			i += 2
+		case draw2d.QuadCurveToCmp:
+			dst.QuadCurveTo(ps[0]*scale+x, ps[1]*scale+y, ps[2]*scale+x, ps[3]*scale+y)
+			i += 4
+		case draw2d.CubicCurveToCmp:
+			dst.CubicCurveTo(ps[0]*scale+x, ps[1]*scale+y, ps[2]*scale+x, ps[3]*scale+y, ps[4]*scale+x, ps[5]*scale+y)
+			i += 6
+		case draw2d.ArcToCmp:
+			dst.ArcTo(ps[0]*scale+x, ps[1]*scale+y, ps[2]*scale, ps[3]*scale, ps[4], ps[5])
+			i += 6
+		case draw2d.CloseCmp:
+			dst.Close()
+		}
+	}
+}
+
+// AddPathFontString appends the glyphs of text drawn with font at x, y on the
+// baseline to path and returns the advance of the text. scale is the size of
+// a font unit, as returned by PathFontScale. Path fonts are laid out
+// horizontally, without shaping nor kerning.
+func AddPathFontString(path draw2d.PathBuilder, font draw2d.PathFont, scale float64, text string, x, y float64) float64 {
+	return layoutPathFont(font, scale, text, func(_ rune, glyph *draw2d.Path, dx, _ float64) {
+		addScaledPath(path, glyph, scale, x+dx, y)
+	})
+}
+
+// DrawPathFontString draws text with font at x, y as FillStringAt and
+// StrokeStringAt do with drawType, FillGlyph or StrokeGlyph. The glyphs of
+// stroke fonts are always stroked. When the pixels of a bitmap font are
+// scaled by a whole number and the transformation of gc is a translation, the
+// origin is moved to a whole pixel to draw every pixel of the glyphs exactly.
+// It returns the advance of the text.
+func DrawPathFontString(gc draw2d.GraphicContext, font draw2d.PathFont, scale float64, text string, x, y float64, drawType func(g *Glyph, gc draw2d.GraphicContext)) float64 {
+	if font.Stroked() {
+		drawType = StrokeGlyph
+	}
+	if tr := gc.GetMatrixTransform(); tr.IsTranslation() && scale == math.Trunc(scale) {
+		if _, ok := font.(*draw2d.BitmapFont); ok {
+			x = math.Round(x+tr[4]) - tr[4]
+			y = math.Round(y+tr[5]) - tr[5]
+		}
+	}
+	path := &draw2d.Path{}
+	advance := AddPathFontString(path, font, scale, text, x, y)
+	gc.Save()
+	defer gc.Restore()
+	gc.BeginPath()
+	// path fonts are always laid out horizontally, like their decorations
+	gc.SetWritingMode(draw2d.WritingModeHorizontal)
+	drawType(&Glyph{Path: path, Width: advance}, gc)
+	DrawDecorations(gc, PathFontMetrics(font, scale), x, y, advance, drawType)
+	return advance
+}
+
+// MeasurePathFont returns the metrics of text drawn with font, as
+// MeasureString does for TrueType fonts. The bounds of stroke fonts do not
+// include the width of the lines.
+func MeasurePathFont(font draw2d.PathFont, scale float64, decoration draw2d.TextDecoration, text string) draw2d.TextMetrics {
+	fm := PathFontMetrics(font, scale)
+	metrics := draw2d.TextMetrics{
+		Ascent:    fm.Ascent,
+		Descent:   fm.Descent,
+		CapHeight: fm.CapHeight,
+		XHeight:   fm.XHeight,
+	}
+	top, left, bottom, right := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	metrics.Width = layoutPathFont(font, scale, text, func(r rune, glyph *draw2d.Path, x, advance float64) {
+		for i := 0; i+1 < len(glyph.Points); i += 2 {
+			px, py := glyph.Points[i]*scale+x, glyph.Points[i+1]*scale
+			top = math.Min(top, py)
+			bottom = math.Max(bottom, py)
+			left = math.Min(left, px)
+			right = math.Max(right, px)
+		}
+		metrics.Glyphs = append(metrics.Glyphs, draw2d.GlyphMetrics{Rune: r, X: x, Advance: advance})
+	})
+	if l, t, r, b, ok := DecorationBounds(fm, decoration, draw2d.WritingModeHorizontal, metrics.Width); ok {
+		top, left, bottom, right = math.Min(top, t), math.Min(left, l), math.Max(bottom, b), math.Max(right, r)
+	}
+	if left <= right {
+		metrics.Left, metrics.Top, metrics.Right, metrics.Bottom = left, top, right, bottom
+	}
+	return metrics
+}
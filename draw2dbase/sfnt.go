@@ -0,0 +1,48 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+// Readers for the sfnt tables that the truetype package parses but does not
+// expose. See https://docs.microsoft.com/typography/opentype/spec/otff
+
+func u16(b []byte, i int) uint16 {
+	return uint16(b[i])<<8 | uint16(b[i+1])
+}
+
+func u32(b []byte, i int) uint32 {
+	return uint32(b[i])<<24 | uint32(b[i+1])<<16 | uint32(b[i+2])<<8 | uint32(b[i+3])
+}
+
+// FontTable returns the table with the given tag of the sfnt font data, or
+// nil if the font has no such table.
+func FontTable(data []byte, tag string) []byte {
+	if len(data) < 12 {
+		return nil
+	}
+	numTables := int(u16(data, 4))
+	for i := 0; i < numTables; i++ {
+		record := 12 + 16*i
+		if len(data) < record+16 {
+			return nil
+		}
+		if string(data[record:record+4]) != tag {
+			continue
+		}
+		offset, length := int(u32(data, record+8)), int(u32(data, record+12))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil
+		}
+		return data[offset : offset+length]
+	}
+	return nil
+}
+
+// int16At reads a signed 16 bits value of table at offset i, ok is false if
+// the table is too short.
+func int16At(table []byte, i int) (v int16, ok bool) {
+	if len(table) < i+2 {
+		return 0, false
+	}
+	return int16(u16(table, i)), true
+}
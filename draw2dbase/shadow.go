@@ -0,0 +1,42 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/llgcode/draw2d/draw2dfilter"
+)
+
+// ShadowMargin returns the number of pixels a shadow of blur spreads
+// around the shape casting it
+func ShadowMargin(blur float64) int {
+	// 3 standard deviations of the Gaussian blur
+	return int(math.Ceil(1.5 * blur))
+}
+
+// Shadow returns the shadow of color c cast by a shape of coverage mask,
+// blurred as SetShadow does. The shadow has the bounds of mask, which
+// should have a margin of ShadowMargin(blur) pixels around the shape.
+func Shadow(mask *image.Alpha16, blur float64, c color.Color) *image.RGBA {
+	r, g, b, a := c.RGBA()
+	bounds := mask.Bounds()
+	shadow := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			v := float64(mask.Alpha16At(x, y).A) / 0xffff
+			i := shadow.PixOffset(x, y)
+			shadow.Pix[i] = uint8(float64(r>>8)*v + 0.5)
+			shadow.Pix[i+1] = uint8(float64(g>>8)*v + 0.5)
+			shadow.Pix[i+2] = uint8(float64(b>>8)*v + 0.5)
+			shadow.Pix[i+3] = uint8(float64(a>>8)*v + 0.5)
+		}
+	}
+	if blur <= 0 {
+		return shadow
+	}
+	return draw2dfilter.NewGaussianBlur(blur / 2).Apply(shadow)
+}
@@ -0,0 +1,43 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestShadow(t *testing.T) {
+	blur := 4.0
+	margin := ShadowMargin(blur)
+	mask := image.NewAlpha16(image.Rect(0, 0, 10, 10).Inset(-margin))
+	for y := range 10 {
+		for x := range 10 {
+			mask.SetAlpha16(x, y, color.Opaque)
+		}
+	}
+	shadow := Shadow(mask, blur, color.NRGBA{0, 0, 0xff, 0x80})
+	if shadow.Bounds() != mask.Bounds() {
+		t.Fatalf("got bounds %v, want %v", shadow.Bounds(), mask.Bounds())
+	}
+	sum := 0
+	for i := 3; i < len(shadow.Pix); i += 4 {
+		sum += int(shadow.Pix[i])
+	}
+	// the blur keeps the area of the shadow
+	if want := 100 * 0x80; sum < want-100 || sum > want+100 {
+		t.Errorf("got a shadow of alpha sum %d, want %d", sum, want)
+	}
+	center, edge, corner := shadow.RGBAAt(5, 5), shadow.RGBAAt(-1, 5), shadow.RGBAAt(-margin, -margin)
+	if center.B != center.A || center.A < 0x70 || edge.A >= center.A || corner.A != 0 {
+		t.Errorf("unexpected shadow colors %v at the center, %v at the edge and %v at the corner", center, edge, corner)
+	}
+
+	// without blur, the shadow is the mask
+	shadow = Shadow(mask, 0, color.Black)
+	if shadow.RGBAAt(0, 0) != (color.RGBA{0, 0, 0, 0xff}) || shadow.RGBAAt(-1, 0).A != 0 {
+		t.Error("a shadow without blur should have the shape of the mask")
+	}
+}
@@ -0,0 +1,459 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"unicode"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// ShapedGlyph is a glyph of a shaped string, positioned relative to the
+// string origin
+type ShapedGlyph struct {
+	// Index of the glyph in the font
+	Index truetype.Index
+	// Rune is the first character the glyph was made from, mirrored in right
+	// to left runs
+	Rune rune
+	// Substituted is true when the glyph is not the one mapped to Rune by
+	// the font, for example a ligature or an arabic contextual form
+	Substituted bool
+	// X, Y is the glyph origin, Y going downwards
+	X, Y float64
+	// Advance is zero for marks attached to a base glyph
+	Advance float64
+	// Base is the index of the glyph a mark is attached to, -1 if the glyph
+	// is not attached
+	Base int
+	// Rotated is true for the glyphs of vertical text turned 90° clockwise
+	// around their origin
+	Rotated bool
+}
+
+// Shape converts text to glyphs of f at scale, the number of 26.6 fixed point
+// units in 1 em as in ContextStack.Scale. Runs of right to left text are
+// reordered with the Unicode bidirectional algorithm, arabic letters take their
+// contextual forms, ligatures of the GSUB table are applied and marks are
+// positioned on their base with the GPOS table when the font has one. The
+// glyphs are returned from left to right with the total advance of the text.
+//
+// OpenType tables are only available for fonts parsed with draw2d.ParseFont,
+// other fonts fall back on the arabic presentation forms of their cmap and
+// on centering marks over their base.
+func Shape(f *truetype.Font, scale float64, text string) (glyphs []ShapedGlyph, width float64) {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return nil, 0
+	}
+	s := &shaper{font: f, scale: scale, layout: getLayout(f)}
+	for _, run := range bidiRuns(runes) {
+		s.shapeRun(runes[run.start:run.end], run.rtl())
+	}
+	return s.position()
+}
+
+// shapingGlyph is a glyph during shaping
+type shapingGlyph struct {
+	index       truetype.Index
+	r           rune
+	substituted bool
+	form        int
+	advance     float64
+	// base is the index of the glyph a mark is attached to, -1 if none,
+	// dx, dy the offset from the base origin in pixels
+	base   int
+	dx, dy float64
+}
+
+type shaper struct {
+	font   *truetype.Font
+	scale  float64
+	layout *otLayout
+	// glyphs of the shaped runs in visual order
+	glyphs []shapingGlyph
+	// run holds the glyphs of the current run in logical order
+	run []shapingGlyph
+}
+
+// arabic joining forms
+const (
+	formNone = iota
+	formIsolated
+	formFinal
+	formInitial
+	formMedial
+)
+
+var formFeatures = [...]string{formIsolated: "isol", formFinal: "fina", formInitial: "init", formMedial: "medi"}
+
+func (s *shaper) shapeRun(runes []rune, rtl bool) {
+	s.run = s.run[:0]
+	for _, r := range runes {
+		if rtl {
+			r = mirror(r)
+		}
+		s.run = append(s.run, shapingGlyph{index: s.font.Index(r), r: r, base: -1})
+	}
+	script := runScript(runes)
+	s.substitute(script, "ccmp", nil)
+	if script == "arab" {
+		s.joinArabic()
+	}
+	for _, feature := range []string{"rlig", "liga", "clig"} {
+		s.substitute(script, feature, nil)
+	}
+	for i := range s.run {
+		g := &s.run[i]
+		g.advance = float64(s.font.HMetric(fixed.Int26_6(s.scale), g.index).AdvanceWidth) / 64
+	}
+	s.attachMarks(script)
+
+	offset, n := len(s.glyphs), len(s.run)
+	for i := range s.run {
+		g := s.run[i]
+		if rtl {
+			g = s.run[n-1-i]
+			if g.base >= 0 {
+				g.base = n - 1 - g.base
+			}
+		}
+		if g.base >= 0 {
+			g.base += offset
+		}
+		s.glyphs = append(s.glyphs, g)
+	}
+}
+
+// position lays out the glyphs from left to right, kerning the adjacent
+// glyphs that are not attached to a base
+func (s *shaper) position() ([]ShapedGlyph, float64) {
+	shaped := make([]ShapedGlyph, len(s.glyphs))
+	cursor := 0.0
+	prev := -1
+	for i, g := range s.glyphs {
+		if g.base >= 0 {
+			continue
+		}
+		if prev >= 0 {
+			cursor += float64(s.font.Kern(fixed.Int26_6(s.scale), s.glyphs[prev].index, g.index)) / 64
+		}
+		shaped[i] = ShapedGlyph{X: cursor, Advance: g.advance}
+		cursor += g.advance
+		prev = i
+	}
+	for i, g := range s.glyphs {
+		if g.base >= 0 {
+			// follow the chain of marks down to the base glyph
+			x, y := 0.0, 0.0
+			j := i
+			for s.glyphs[j].base >= 0 {
+				x += s.glyphs[j].dx
+				y += s.glyphs[j].dy
+				j = s.glyphs[j].base
+			}
+			shaped[i] = ShapedGlyph{X: shaped[j].X + x, Y: y}
+		}
+		shaped[i].Base = g.base
+		shaped[i].Index, shaped[i].Rune, shaped[i].Substituted = g.index, g.r, g.substituted
+	}
+	return shaped, cursor
+}
+
+// runScript returns the OpenType script tag of a run
+func runScript(runes []rune) string {
+	for _, r := range runes {
+		switch {
+		case unicode.Is(unicode.Arabic, r):
+			return "arab"
+		case unicode.Is(unicode.Hebrew, r):
+			return "hebr"
+		}
+	}
+	return "latn"
+}
+
+// isMark returns true if g is a mark, as classified by the GDEF table or
+// by the general category of its rune
+func (s *shaper) isMark(g shapingGlyph) bool {
+	if mark, ok := s.layout.isMark(g.index); ok {
+		return mark
+	}
+	return unicode.In(g.r, unicode.Mn, unicode.Me)
+}
+
+// substitute applies the GSUB lookups of feature to the glyphs of the run
+// for which apply returns true, or to all of them if apply is nil
+func (s *shaper) substitute(script, feature string, apply func(g shapingGlyph) bool) {
+	for _, lookup := range s.layout.featureLookups(s.layout.gsub, "GSUB", script, feature) {
+		ignoreMarks := lookup.flag&lookupIgnoreMarks != 0
+		skip := func(g shapingGlyph) bool {
+			return ignoreMarks && s.isMark(g)
+		}
+		for i := 0; i < len(s.run); i++ {
+			g := s.run[i]
+			if skip(g) || (apply != nil && !apply(g)) {
+				continue
+			}
+			switch lookup.kind {
+			case 1:
+				for _, subtable := range lookup.subtables {
+					if index, ok := singleSubstitution(subtable, g.index); ok {
+						s.run[i].index, s.run[i].substituted = index, true
+						break
+					}
+				}
+			case 4:
+				s.ligate(lookup, i, skip)
+			}
+		}
+	}
+}
+
+// ligate replaces the glyphs starting at i by a ligature of lookup, the
+// skipped glyphs are kept after the ligature
+func (s *shaper) ligate(lookup otLookup, i int, skip func(g shapingGlyph) bool) {
+	// components holds the indices of the glyphs following i that are not skipped
+	var components []int
+	next := func(n int) (truetype.Index, bool) {
+		for len(components) < n {
+			j := i + 1
+			if len(components) > 0 {
+				j = components[len(components)-1] + 1
+			}
+			for j < len(s.run) && skip(s.run[j]) {
+				j++
+			}
+			if j >= len(s.run) {
+				return 0, false
+			}
+			components = append(components, j)
+		}
+		return s.run[components[n-1]].index, true
+	}
+	for _, subtable := range lookup.subtables {
+		ligature, count := ligatureSubstitution(subtable, s.run[i].index, next)
+		if count == 0 {
+			continue
+		}
+		s.run[i].index, s.run[i].substituted = ligature, true
+		// remove the components, last first
+		components = components[:count-1]
+		for k := len(components) - 1; k >= 0; k-- {
+			j := components[k]
+			s.run = append(s.run[:j], s.run[j+1:]...)
+		}
+		return
+	}
+}
+
+// joinArabic sets the contextual form of the arabic letters and substitutes
+// their glyphs, with the font features when it has them and with the arabic
+// presentation forms of its cmap otherwise
+func (s *shaper) joinArabic() {
+	// previous letter skipping transparent characters
+	prev := -1
+	for i := range s.run {
+		t := joiningType(s.run[i].r)
+		if t == 'T' {
+			continue
+		}
+		if prev >= 0 {
+			pt := joiningType(s.run[prev].r)
+			if (pt == 'D' || pt == 'C') && (t == 'R' || t == 'D' || t == 'C') {
+				s.run[prev].form = joinNext(s.run[prev].form)
+				s.run[i].form = formFinal
+			}
+		}
+		if s.run[i].form == formNone && (t == 'R' || t == 'D') {
+			s.run[i].form = formIsolated
+		}
+		prev = i
+	}
+	for i := range s.run {
+		if t := joiningType(s.run[i].r); t != 'R' && t != 'D' {
+			s.run[i].form = formNone
+		}
+	}
+
+	hasForms := false
+	for _, feature := range formFeatures[formIsolated:] {
+		if len(s.layout.featureLookups(s.layout.gsub, "GSUB", "arab", feature)) > 0 {
+			hasForms = true
+		}
+	}
+	if !hasForms {
+		s.presentationForms()
+		return
+	}
+	for form := formIsolated; form <= formMedial; form++ {
+		form := form
+		s.substitute("arab", formFeatures[form], func(g shapingGlyph) bool { return g.form == form })
+	}
+}
+
+// joinNext returns the form of a letter that also joins the next letter
+func joinNext(form int) int {
+	if form == formFinal || form == formMedial {
+		return formMedial
+	}
+	return formInitial
+}
+
+// presentationForms substitutes the arabic letters of the run by the
+// characters of the Arabic Presentation Forms-B block
+func (s *shaper) presentationForms() {
+	for i := 0; i < len(s.run); i++ {
+		g := &s.run[i]
+		if g.r == 0x0644 && i+1 < len(s.run) {
+			if ligature, ok := lamAlef[s.run[i+1].r]; ok {
+				if g.form == formFinal || g.form == formMedial {
+					ligature++
+				}
+				if index := s.font.Index(ligature); index != 0 {
+					g.index, g.substituted = index, true
+					s.run = append(s.run[:i+1], s.run[i+2:]...)
+					continue
+				}
+			}
+		}
+		first, count := presentationForm(g.r)
+		if count == 0 || g.form == formNone {
+			continue
+		}
+		form := g.form - formIsolated
+		if form >= count {
+			// letters without initial and medial forms, like alef maksura
+			form -= 2
+		}
+		if index := s.font.Index(first + rune(form)); index != 0 {
+			g.index, g.substituted = index, true
+		}
+	}
+}
+
+// lamAlef maps the alef following a lam to the isolated form of their
+// ligature, the final form follows it
+var lamAlef = map[rune]rune{
+	0x0622: 0xFEF5,
+	0x0623: 0xFEF7,
+	0x0625: 0xFEF9,
+	0x0627: 0xFEFB,
+}
+
+// presentationFormCounts holds the number of forms of the letters U+0621 to
+// U+063A and U+0641 to U+064A in the Arabic Presentation Forms-B block,
+// which lists them in the same order from U+FE80
+var presentationFormCounts = []int{
+	1, 2, 2, 2, 2, 4, 2, 4, 2, 4, 4, 4, 4, 4, 2, 2, 2, 2, 4, 4, 4, 4, 4, 4, 4, 4, // U+0621 - U+063A
+	4, 4, 4, 4, 4, 4, 4, 2, 2, 4, // U+0641 - U+064A
+}
+
+// presentationForm returns the isolated form of r in the Arabic Presentation
+// Forms-B block and the number of its forms, zero if it has none
+func presentationForm(r rune) (rune, int) {
+	var n int
+	switch {
+	case r >= 0x0621 && r <= 0x063A:
+		n = int(r - 0x0621)
+	case r >= 0x0641 && r <= 0x064A:
+		n = int(r-0x0641) + 0x063A - 0x0621 + 1
+	default:
+		return 0, 0
+	}
+	first := rune(0xFE80)
+	for _, count := range presentationFormCounts[:n] {
+		first += rune(count)
+	}
+	return first, presentationFormCounts[n]
+}
+
+// joiningType returns the arabic joining type of r: 'R' for right joining
+// letters, 'D' for dual joining ones, 'C' for join causing characters, 'T'
+// for transparent marks and 'U' for non joining characters.
+// See https://www.unicode.org/Public/UCD/latest/ucd/ArabicShaping.txt
+func joiningType(r rune) byte {
+	switch {
+	case r == 0x0640 || r == 0x200D:
+		return 'C'
+	case r == 0x0622 || r == 0x0623 || r == 0x0624 || r == 0x0625 || r == 0x0627 || r == 0x0629,
+		r >= 0x062F && r <= 0x0632, r == 0x0648,
+		r >= 0x0671 && r <= 0x0673, r >= 0x0675 && r <= 0x0677, r >= 0x0688 && r <= 0x0699,
+		r == 0x06C0, r >= 0x06C3 && r <= 0x06CB, r == 0x06CD, r == 0x06CF, r == 0x06D2, r == 0x06D3, r == 0x06D5:
+		return 'R'
+	case r == 0x0620 || r == 0x0626 || r == 0x0628, r >= 0x062A && r <= 0x062E, r >= 0x0633 && r <= 0x063F,
+		r >= 0x0641 && r <= 0x0647, r == 0x0649 || r == 0x064A, r == 0x066E || r == 0x066F,
+		r >= 0x0678 && r <= 0x0687, r >= 0x069A && r <= 0x06BF, r == 0x06C1 || r == 0x06C2,
+		r == 0x06CC, r == 0x06CE, r == 0x06D0 || r == 0x06D1:
+		return 'D'
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) && r != 0x200C:
+		return 'T'
+	}
+	return 'U'
+}
+
+// attachMarks positions the marks of the run on their base glyph, with the
+// mark and mkmk features of the GPOS table or by centering them on the base
+func (s *shaper) attachMarks(script string) {
+	// pixels per font unit
+	px := s.scale / 64 / float64(s.font.FUnitsPerEm())
+	markToBase := s.layout.featureLookups(s.layout.gpos, "GPOS", script, "mark")
+	markToMark := s.layout.featureLookups(s.layout.gpos, "GPOS", script, "mkmk")
+	base := -1
+	for i := range s.run {
+		g := &s.run[i]
+		if !s.isMark(*g) {
+			base = i
+			continue
+		}
+		if base < 0 {
+			continue
+		}
+		attached := false
+		if i > 0 && i-1 != base {
+			// the previous glyph is a mark too
+			if dx, dy, ok := attach(markToMark, 6, g.index, s.run[i-1].index); ok {
+				g.base, g.dx, g.dy, attached = i-1, float64(dx)*px, -float64(dy)*px, true
+			}
+		}
+		if !attached {
+			if dx, dy, ok := attach(markToBase, 4, g.index, s.run[base].index); ok {
+				g.base, g.dx, g.dy, attached = base, float64(dx)*px, -float64(dy)*px, true
+			}
+		}
+		if !attached {
+			g.base, g.dy = base, 0
+			g.dx = s.center(s.run[base]) - s.center(*g)
+		}
+		g.advance = 0
+	}
+}
+
+// attach returns the offset of mark to base given by the first lookup of
+// kind that has them
+func attach(lookups []otLookup, kind int, mark, base truetype.Index) (dx, dy int, ok bool) {
+	for _, lookup := range lookups {
+		if lookup.kind != kind {
+			continue
+		}
+		for _, subtable := range lookup.subtables {
+			if dx, dy, ok := markAttachment(subtable, mark, base); ok {
+				return dx, dy, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// center returns the horizontal center of the ink of g from its origin, or
+// the middle of its advance for a glyph without outline
+func (s *shaper) center(g shapingGlyph) float64 {
+	glyphBuf := &truetype.GlyphBuf{}
+	if err := glyphBuf.Load(s.font, fixed.Int26_6(s.scale), g.index, font.HintingNone); err != nil || len(glyphBuf.Ends) == 0 {
+		return float64(s.font.HMetric(fixed.Int26_6(s.scale), g.index).AdvanceWidth) / 128
+	}
+	return float64(glyphBuf.Bounds.Min.X+glyphBuf.Bounds.Max.X) / 128
+}
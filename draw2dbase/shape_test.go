@@ -0,0 +1,235 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/math/fixed"
+)
+
+// be16 encodes values as big endian 16 bits integers
+func be16(values ...int) []byte {
+	b := make([]byte, 0, 2*len(values))
+	for _, v := range values {
+		b = append(b, byte(uint16(v)>>8), byte(v))
+	}
+	return b
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, part := range parts {
+		b = append(b, part...)
+	}
+	return b
+}
+
+// layoutTable builds a GSUB or GPOS table with a single feature for the
+// latn script made of a single lookup
+func layoutTable(feature string, kind, flag int, subtable []byte) otTable {
+	scriptList := concat(be16(1), []byte("latn"), be16(8), be16(4, 0), be16(0, 0xFFFF, 1, 0))
+	featureList := concat(be16(1), []byte(feature), be16(8), be16(0, 1, 0))
+	lookupList := concat(be16(1, 4), be16(kind, flag, 1, 8), subtable)
+	header := be16(1, 0, 10, 10+len(scriptList), 10+len(scriptList)+len(featureList))
+	return otTable(concat(header, scriptList, featureList, lookupList))
+}
+
+// markGDEF builds a GDEF table classifying glyph as a mark
+func markGDEF(glyph truetype.Index) otTable {
+	return otTable(concat(be16(1, 0, 12, 0, 0, 0), be16(2, 1, int(glyph), int(glyph), 3)))
+}
+
+func testShaper(f *truetype.Font, layout *otLayout) *shaper {
+	layout.lookups = make(map[string][]otLookup)
+	return &shaper{font: f, scale: 20 * 64, layout: layout}
+}
+
+func TestShapeLatin(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	scale := 20.0 * 64
+	text := "AVAVA To, World!"
+	glyphs, width := Shape(f, scale, text)
+	if len(glyphs) != len([]rune(text)) {
+		t.Fatalf("got %d glyphs, want %d", len(glyphs), len([]rune(text)))
+	}
+	// latin text is laid out rune by rune with the kern table
+	cursor := 0.0
+	for i, r := range []rune(text) {
+		index := f.Index(r)
+		if i > 0 {
+			cursor += float64(f.Kern(fixed.Int26_6(scale), glyphs[i-1].Index, index)) / 64
+		}
+		g := glyphs[i]
+		if g.Index != index || g.Rune != r || g.Substituted || g.Base != -1 {
+			t.Errorf("glyph %d is %+v, want the glyph of %q", i, g, r)
+		}
+		if g.X != cursor || g.Y != 0 {
+			t.Errorf("glyph %d is at %v, %v, want %v, 0", i, g.X, g.Y, cursor)
+		}
+		cursor += float64(f.HMetric(fixed.Int26_6(scale), index).AdvanceWidth) / 64
+	}
+	if width != cursor {
+		t.Errorf("width is %v, want %v", width, cursor)
+	}
+}
+
+func TestShapeRightToLeft(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	glyphs, _ := Shape(f, 20*64, "a (שלום) b")
+	var visual []rune
+	for i, g := range glyphs {
+		visual = append(visual, g.Rune)
+		if i > 0 && g.X < glyphs[i-1].X {
+			t.Errorf("glyph %d is left of the previous one", i)
+		}
+	}
+	if string(visual) != "a (םולש) b" {
+		t.Errorf("glyphs are in order %q", string(visual))
+	}
+}
+
+func TestShapeLigature(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	first, second, ligature := f.Index('f'), f.Index('i'), f.Index('X')
+	subtable := concat(be16(1, 8, 1, 14), be16(1, 1, int(first)), be16(1, 4), be16(int(ligature), 2, int(second)))
+	s := testShaper(f, &otLayout{gsub: layoutTable("liga", 4, 0, subtable)})
+	s.shapeRun([]rune("fif"), false)
+	glyphs, width := s.position()
+	if len(glyphs) != 2 {
+		t.Fatalf("got %d glyphs, want 2", len(glyphs))
+	}
+	if glyphs[0].Index != ligature || !glyphs[0].Substituted || glyphs[0].Rune != 'f' {
+		t.Errorf("first glyph is %+v, want the ligature", glyphs[0])
+	}
+	if glyphs[1].Index != first || glyphs[1].Substituted {
+		t.Errorf("second glyph is %+v, want 'f'", glyphs[1])
+	}
+	want := glyphs[1].X + glyphs[1].Advance
+	if width != want {
+		t.Errorf("width is %v, want %v", width, want)
+	}
+}
+
+func TestShapeLigatureIgnoreMarks(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	first, second, ligature, mark := f.Index('f'), f.Index('i'), f.Index('X'), f.Index('^')
+	subtable := concat(be16(1, 8, 1, 14), be16(1, 1, int(first)), be16(1, 4), be16(int(ligature), 2, int(second)))
+	s := testShaper(f, &otLayout{gsub: layoutTable("liga", 4, lookupIgnoreMarks, subtable), gdef: markGDEF(mark)})
+	s.shapeRun([]rune("f^i"), false)
+	glyphs, _ := s.position()
+	if len(glyphs) != 2 || glyphs[0].Index != ligature || glyphs[1].Index != mark {
+		t.Fatalf("glyphs are %+v, want the ligature followed by the mark", glyphs)
+	}
+	if glyphs[1].Base != 0 || glyphs[1].Advance != 0 {
+		t.Errorf("mark should be attached to the ligature, got %+v", glyphs[1])
+	}
+}
+
+func TestShapeSingleSubstitution(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	a, b := f.Index('a'), f.Index('b')
+	// format 1 adds a delta to the glyph index
+	subtable := concat(be16(1, 6, int(b)-int(a)), be16(1, 1, int(a)))
+	s := testShaper(f, &otLayout{gsub: layoutTable("ccmp", 1, 0, subtable)})
+	s.shapeRun([]rune("ca"), false)
+	glyphs, _ := s.position()
+	if glyphs[0].Index != f.Index('c') || glyphs[1].Index != b || !glyphs[1].Substituted {
+		t.Errorf("glyphs are %+v, want 'c' and 'b'", glyphs)
+	}
+}
+
+func TestShapeMarkToBase(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	base, mark := f.Index('e'), f.Index('^')
+	subtable := concat(be16(1, 12, 18, 1, 24, 36), be16(1, 1, int(mark)), be16(1, 1, int(base)),
+		be16(1, 0, 6), be16(1, 100, -50), be16(1, 4), be16(1, 600, 1200))
+	s := testShaper(f, &otLayout{gpos: layoutTable("mark", 4, 0, subtable), gdef: markGDEF(mark)})
+	s.shapeRun([]rune("e^e"), false)
+	glyphs, width := s.position()
+	px := s.scale / 64 / float64(f.FUnitsPerEm())
+	if glyphs[1].Base != 0 || glyphs[1].Advance != 0 {
+		t.Fatalf("mark should be attached to the first glyph, got %+v", glyphs[1])
+	}
+	if math.Abs(glyphs[1].X-500*px) > 1e-9 || math.Abs(glyphs[1].Y+1250*px) > 1e-9 {
+		t.Errorf("mark is at %v, %v, want %v, %v", glyphs[1].X, glyphs[1].Y, 500*px, -1250*px)
+	}
+	// the mark does not advance the cursor
+	if glyphs[2].X != glyphs[0].Advance || width != 2*glyphs[0].Advance {
+		t.Errorf("second base is at %v, want %v", glyphs[2].X, glyphs[0].Advance)
+	}
+}
+
+func TestShapeMarkFallback(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	base, mark := f.Index('o'), f.Index('^')
+	s := testShaper(f, &otLayout{gdef: markGDEF(mark)})
+	s.shapeRun([]rune("o^"), false)
+	glyphs, _ := s.position()
+	center := func(g ShapedGlyph) float64 {
+		return g.X + s.center(shapingGlyph{index: g.Index})
+	}
+	if glyphs[1].Base != 0 || glyphs[0].Index != base {
+		t.Fatalf("mark should be attached to the first glyph, got %+v", glyphs[1])
+	}
+	if math.Abs(center(glyphs[0])-center(glyphs[1])) > 1e-9 {
+		t.Errorf("mark should be centered on its base")
+	}
+}
+
+func TestArabicJoining(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	tests := []struct {
+		text  string
+		forms []int
+	}{
+		{"بيت", []int{formInitial, formMedial, formFinal}},
+		{"دار", []int{formIsolated, formIsolated, formIsolated}},
+		{"بدب", []int{formInitial, formFinal, formIsolated}},
+		// marks are transparent
+		{"بَت", []int{formInitial, formNone, formFinal}},
+		// tatweel joins but has no form
+		{"بـ", []int{formInitial, formNone}},
+		// zero width non joiner breaks the joining
+		{"ب‌ب", []int{formIsolated, formNone, formIsolated}},
+	}
+	for _, test := range tests {
+		s := testShaper(f, &otLayout{})
+		for _, r := range test.text {
+			s.run = append(s.run, shapingGlyph{index: f.Index(r), r: r, base: -1})
+		}
+		s.joinArabic()
+		for i, g := range s.run {
+			if g.form != test.forms[i] {
+				t.Errorf("%q: form of rune %d is %d, want %d", test.text, i, g.form, test.forms[i])
+			}
+		}
+	}
+}
+
+func TestPresentationForm(t *testing.T) {
+	tests := []struct {
+		r     rune
+		first rune
+		count int
+	}{
+		{0x0621, 0xFE80, 1},
+		{0x0627, 0xFE8D, 2},
+		{0x0628, 0xFE8F, 4},
+		{0x063A, 0xFECD, 4},
+		{0x0641, 0xFED1, 4},
+		{0x0649, 0xFEEF, 2},
+		{0x064A, 0xFEF1, 4},
+		{'a', 0, 0},
+	}
+	for _, test := range tests {
+		first, count := presentationForm(test.r)
+		if first != test.first || count != test.count {
+			t.Errorf("presentationForm(%U) = %U, %d, want %U, %d", test.r, first, count, test.first, test.count)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+
+	"github.com/llgcode/draw2d"
+)
+
+// SnapStroke returns a copy of path of which the horizontal and vertical
+// lines are moved, once transformed by tr, to the centre of the pixels if
+// the transformed lineWidth rounds to an odd number of pixels, and to their
+// edges otherwise, so that they are stroked on whole pixels
+func SnapStroke(path *draw2d.Path, tr draw2d.Matrix, lineWidth float64) *draw2d.Path {
+	offset := 0.0
+	if n := math.Round(lineWidth * tr.GetScale()); n < 1 || math.Mod(n, 2) == 1 {
+		offset = 0.5
+	}
+	return snapPath(path, tr, offset)
+}
+
+// SnapFill returns a copy of path of which the horizontal and vertical lines
+// are moved, once transformed by tr, to the edges of the pixels, so that the
+// filled shape covers whole pixels along them
+func SnapFill(path *draw2d.Path, tr draw2d.Matrix) *draw2d.Path {
+	return snapPath(path, tr, 0)
+}
+
+// snapPath moves the ends of the horizontal and vertical lines of path to
+// the nearest pixel coordinate plus offset in device space. The control
+// points of curves and the arcs are left unchanged.
+func snapPath(path *draw2d.Path, tr draw2d.Matrix, offset float64) *draw2d.Path {
+	snapped := path.Copy()
+	if tr.Determinant() == 0 {
+		return snapped
+	}
+	n := len(path.Points) / 2
+	// device coordinates of the points and the ones to snap
+	xs, ys := make([]float64, n), make([]float64, n)
+	snapX, snapY := make([]bool, n), make([]bool, n)
+	for i := range n {
+		xs[i], ys[i] = tr.TransformPoint(path.Points[2*i], path.Points[2*i+1])
+	}
+	const epsilon = 1e-6
+	// line marks the points of the line from the point p to the point q
+	line := func(p, q int) {
+		if p < 0 || q < 0 {
+			return
+		}
+		if math.Abs(ys[p]-ys[q]) < epsilon {
+			snapY[p], snapY[q] = true, true
+		}
+		if math.Abs(xs[p]-xs[q]) < epsilon {
+			snapX[p], snapX[q] = true, true
+		}
+	}
+	// indices of the current point and of the start of the sub path, -1 if
+	// they are not a point of the path
+	current, start := -1, -1
+	i := 0
+	for _, cmp := range path.Components {
+		switch cmp {
+		case draw2d.MoveToCmp:
+			current, start = i/2, i/2
+			i += 2
+		case draw2d.LineToCmp:
+			line(current, i/2)
+			current = i / 2
+			i += 2
+		case draw2d.QuadCurveToCmp:
+			current = i/2 + 1
+			i += 4
+		case draw2d.CubicCurveToCmp:
+			current = i/2 + 2
+			i += 6
+		case draw2d.ArcToCmp:
+			current = -1
+			i += 6
+		case draw2d.CloseCmp:
+			line(current, start)
+			current = start
+		}
+	}
+	snap := func(v float64) float64 {
+		return math.Floor(v-offset+0.5) + offset
+	}
+	for i := range n {
+		if !snapX[i] && !snapY[i] {
+			continue
+		}
+		x, y := xs[i], ys[i]
+		if snapX[i] {
+			x = snap(x)
+		}
+		if snapY[i] {
+			y = snap(y)
+		}
+		snapped.Points[2*i], snapped.Points[2*i+1] = tr.InverseTransformPoint(x, y)
+	}
+	return snapped
+}
@@ -0,0 +1,73 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+)
+
+func TestSnapStroke(t *testing.T) {
+	path := new(draw2d.Path)
+	path.MoveTo(1.2, 1.2)
+	path.LineTo(8.7, 1.2)
+	path.LineTo(8.7, 6.1)
+	path.LineTo(2, 9)
+	path.Close()
+
+	tests := []struct {
+		lineWidth float64
+		tr        draw2d.Matrix
+		want      []float64
+	}{
+		// the odd widths are centred on pixels, the third point is only
+		// snapped horizontally as the end of a vertical line
+		{1, draw2d.NewIdentityMatrix(), []float64{1.2, 1.5, 8.5, 1.5, 8.5, 6.1, 2, 9}},
+		{3, draw2d.NewIdentityMatrix(), []float64{1.2, 1.5, 8.5, 1.5, 8.5, 6.1, 2, 9}},
+		{0.2, draw2d.NewIdentityMatrix(), []float64{1.2, 1.5, 8.5, 1.5, 8.5, 6.1, 2, 9}},
+		// the even widths are on pixel edges
+		{2, draw2d.NewIdentityMatrix(), []float64{1.2, 1, 9, 1, 9, 6.1, 2, 9}},
+		// the width and the coordinates are snapped in device space
+		{1, draw2d.NewScaleMatrix(2, 2), []float64{1.2, 1, 8.5, 1, 8.5, 6.1, 2, 9}},
+		{1.5, draw2d.NewScaleMatrix(2, 2), []float64{1.2, 1.25, 8.75, 1.25, 8.75, 6.1, 2, 9}},
+	}
+	for _, test := range tests {
+		got := SnapStroke(path, test.tr, test.lineWidth)
+		if len(got.Points) != len(test.want) {
+			t.Fatalf("unexpected points %v", got.Points)
+		}
+		for i := range got.Points {
+			if math.Abs(got.Points[i]-test.want[i]) > 1e-9 {
+				t.Errorf("SnapStroke(%v, %v) = %v, want %v", test.lineWidth, test.tr, got.Points, test.want)
+				break
+			}
+		}
+	}
+	if path.Points[1] != 1.2 {
+		t.Error("the path should not be modified")
+	}
+}
+
+func TestSnapFill(t *testing.T) {
+	path := new(draw2d.Path)
+	path.MoveTo(0.6, 0.4)
+	path.LineTo(5.5, 0.4)
+	path.QuadCurveTo(7, 2, 5.5, 4.7)
+	path.LineTo(0.6, 4.7)
+	path.Close()
+	got := SnapFill(path, draw2d.NewTranslationMatrix(0.25, 0.25))
+	// the curve is unchanged but for its end, which starts a horizontal line
+	want := []float64{0.75, 0.75, 5.5, 0.75, 7, 2, 5.5, 4.75, 0.75, 4.75}
+	for i := range want {
+		if math.Abs(got.Points[i]-want[i]) > 1e-9 {
+			t.Fatalf("SnapFill() = %v, want %v", got.Points, want)
+		}
+	}
+	if !reflect.DeepEqual(got.Components, path.Components) {
+		t.Errorf("the components should be unchanged, got %v", got.Components)
+	}
+}
@@ -32,6 +32,23 @@ type ContextStack struct {
 	Join        draw2d.LineJoin
 	FontSize    float64
 	FontData    draw2d.FontData
+	// TextPathAnchor anchors text drawn along a path
+	TextPathAnchor draw2d.TextAnchor
+	// TextDecoration holds the lines drawn with text
+	TextDecoration draw2d.TextDecoration
+	// WritingMode is the direction of text
+	WritingMode draw2d.WritingMode
+	// Antialias smooths the edges of shapes and text
+	Antialias bool
+	// CrispEdges snaps horizontal and vertical lines to the pixel grid
+	CrispEdges bool
+	// ShadowOffsetX, ShadowOffsetY and ShadowBlur are the offset and the
+	// blur of the shadows, in device space. ShadowColor is their color, no
+	// shadow is drawn if it is transparent.
+	ShadowOffsetX, ShadowOffsetY, ShadowBlur float64
+	ShadowColor                              color.Color
+	// Mask modulates the drawings, nil if they are not masked
+	Mask *Mask
 
 	Font *truetype.Font
 	// fontSize and dpi are used to calculate scale. scale is the number of
@@ -41,10 +58,21 @@ type ContextStack struct {
 	Previous *ContextStack
 }
 
+// HasShadow returns whether the drawings cast a shadow, which is the case
+// if the shadow color is not transparent and the shadow is offset or blurred
+func (cs *ContextStack) HasShadow() bool {
+	if cs.ShadowColor == nil {
+		return false
+	}
+	_, _, _, a := cs.ShadowColor.RGBA()
+	return a != 0 && (cs.ShadowOffsetX != 0 || cs.ShadowOffsetY != 0 || cs.ShadowBlur > 0)
+}
+
 // GetFontName gets the current FontData with fontSize as a string
 func (cs *ContextStack) GetFontName() string {
 	fontData := cs.FontData
-	return fmt.Sprintf("%s:%d:%d:%9.2f", fontData.Name, fontData.Family, fontData.Style, cs.FontSize)
+	return fmt.Sprintf("%s:%d:%d:%d:%d:%9.2f", fontData.Name, fontData.Family, fontData.Style,
+		fontData.ResolvedWeight(), fontData.ResolvedStretch(), cs.FontSize)
 }
 
 
@@ -64,6 +92,8 @@ func NewStackGraphicContext() *StackGraphicContext {
 	gc.Current.Join = draw2d.RoundJoin
 	gc.Current.FontSize = 10
 	gc.Current.FontData = DefaultFontData
+	gc.Current.Antialias = true
+	gc.Current.ShadowColor = color.Transparent
 	return gc
 }
 
@@ -136,6 +166,84 @@ func (gc *StackGraphicContext) GetFontData() draw2d.FontData {
 	return gc.Current.FontData
 }
 
+func (gc *StackGraphicContext) SetTextPathAnchor(anchor draw2d.TextAnchor) {
+	gc.Current.TextPathAnchor = anchor
+}
+
+func (gc *StackGraphicContext) GetTextPathAnchor() draw2d.TextAnchor {
+	return gc.Current.TextPathAnchor
+}
+
+func (gc *StackGraphicContext) SetTextDecoration(decoration draw2d.TextDecoration) {
+	gc.Current.TextDecoration = decoration
+}
+
+func (gc *StackGraphicContext) GetTextDecoration() draw2d.TextDecoration {
+	return gc.Current.TextDecoration
+}
+
+func (gc *StackGraphicContext) SetWritingMode(mode draw2d.WritingMode) {
+	gc.Current.WritingMode = mode
+}
+
+func (gc *StackGraphicContext) GetWritingMode() draw2d.WritingMode {
+	return gc.Current.WritingMode
+}
+
+func (gc *StackGraphicContext) SetAntialias(antialias bool) {
+	gc.Current.Antialias = antialias
+}
+
+func (gc *StackGraphicContext) GetAntialias() bool {
+	return gc.Current.Antialias
+}
+
+func (gc *StackGraphicContext) SetCrispEdges(crisp bool) {
+	gc.Current.CrispEdges = crisp
+}
+
+func (gc *StackGraphicContext) GetCrispEdges() bool {
+	return gc.Current.CrispEdges
+}
+
+func (gc *StackGraphicContext) SetShadow(offsetX, offsetY, blur float64, c color.Color) {
+	gc.Current.ShadowOffsetX = offsetX
+	gc.Current.ShadowOffsetY = offsetY
+	gc.Current.ShadowBlur = max(blur, 0)
+	gc.Current.ShadowColor = c
+}
+
+func (gc *StackGraphicContext) SetMask(mask image.Image, m draw2d.Matrix) {
+	gc.setImageMask(mask, m, false)
+}
+
+func (gc *StackGraphicContext) SetLuminanceMask(mask image.Image, m draw2d.Matrix) {
+	gc.setImageMask(mask, m, true)
+}
+
+func (gc *StackGraphicContext) setImageMask(mask image.Image, m draw2d.Matrix, luminance bool) {
+	if mask == nil {
+		gc.Current.Mask = nil
+		return
+	}
+	tr := gc.Current.Tr.Copy()
+	tr.Compose(m)
+	gc.Current.Mask = &Mask{Image: mask, Luminance: luminance, Tr: tr}
+}
+
+// MaskWithPath sets the mask to the fill of the paths and of the current
+// path, which is cleared as by Fill
+func (gc *StackGraphicContext) MaskWithPath(paths ...*draw2d.Path) {
+	mask := &Mask{FillRule: gc.Current.FillRule, Tr: gc.Current.Tr.Copy()}
+	// the paths are not appended to, their backing array is the caller's
+	for _, p := range paths {
+		mask.Paths = append(mask.Paths, p.Copy())
+	}
+	mask.Paths = append(mask.Paths, gc.Current.Path.Copy())
+	gc.Current.Mask = mask
+	gc.Current.Path.Clear()
+}
+
 func (gc *StackGraphicContext) BeginPath() {
 	gc.Current.Path.Clear()
 }
@@ -180,6 +288,16 @@ func (gc *StackGraphicContext) Save() {
 	context := new(ContextStack)
 	context.FontSize = gc.Current.FontSize
 	context.FontData = gc.Current.FontData
+	context.TextPathAnchor = gc.Current.TextPathAnchor
+	context.TextDecoration = gc.Current.TextDecoration
+	context.WritingMode = gc.Current.WritingMode
+	context.Antialias = gc.Current.Antialias
+	context.CrispEdges = gc.Current.CrispEdges
+	context.ShadowOffsetX = gc.Current.ShadowOffsetX
+	context.ShadowOffsetY = gc.Current.ShadowOffsetY
+	context.ShadowBlur = gc.Current.ShadowBlur
+	context.ShadowColor = gc.Current.ShadowColor
+	context.Mask = gc.Current.Mask
 	context.LineWidth = gc.Current.LineWidth
 	context.StrokeColor = gc.Current.StrokeColor
 	context.FillColor = gc.Current.FillColor
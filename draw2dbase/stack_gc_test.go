@@ -227,6 +227,36 @@ func TestStackGraphicContext_SaveRestore(t *testing.T) {
 	}
 }
 
+func TestStackGraphicContext_TextDecoration(t *testing.T) {
+	gc := NewStackGraphicContext()
+	if gc.GetTextDecoration() != draw2d.TextDecorationNone {
+		t.Error("text should not be decorated by default")
+	}
+	gc.SetTextDecoration(draw2d.TextDecorationUnderline)
+	gc.Save()
+	gc.SetTextDecoration(draw2d.TextDecorationLineThrough)
+	gc.Restore()
+	if gc.GetTextDecoration() != draw2d.TextDecorationUnderline {
+		t.Errorf("After Restore, TextDecoration = %v, want underline", gc.GetTextDecoration())
+	}
+}
+
+func TestStackGraphicContext_WritingMode(t *testing.T) {
+	gc := NewStackGraphicContext()
+	if gc.GetWritingMode() != draw2d.WritingModeHorizontal {
+		t.Error("text should be horizontal by default")
+	}
+	gc.Save()
+	gc.SetWritingMode(draw2d.WritingModeVertical)
+	if gc.GetWritingMode() != draw2d.WritingModeVertical {
+		t.Error("SetWritingMode should set the writing mode")
+	}
+	gc.Restore()
+	if gc.GetWritingMode() != draw2d.WritingModeHorizontal {
+		t.Errorf("After Restore, WritingMode = %v, want horizontal-tb", gc.GetWritingMode())
+	}
+}
+
 func TestStackGraphicContext_SaveRestore_MatrixIndependence(t *testing.T) {
 	gc := NewStackGraphicContext()
 	// Save
@@ -358,3 +388,86 @@ func TestStackGraphicContext_Close(t *testing.T) {
 		t.Error("Close should add CloseCmp")
 	}
 }
+
+func TestStackGraphicContext_Antialias(t *testing.T) {
+	gc := NewStackGraphicContext()
+	if !gc.GetAntialias() || gc.GetCrispEdges() {
+		t.Error("shapes should be antialiased without crisp edges by default")
+	}
+	gc.Save()
+	gc.SetAntialias(false)
+	gc.SetCrispEdges(true)
+	gc.Save()
+	if gc.GetAntialias() || !gc.GetCrispEdges() {
+		t.Error("Save should keep the antialiasing and crisp edges")
+	}
+	gc.Restore()
+	gc.Restore()
+	if !gc.GetAntialias() || gc.GetCrispEdges() {
+		t.Error("Restore should restore the antialiasing and crisp edges")
+	}
+}
+
+func TestStackGraphicContext_Shadow(t *testing.T) {
+	gc := NewStackGraphicContext()
+	if gc.Current.HasShadow() {
+		t.Error("there should be no shadow by default")
+	}
+	gc.SetShadow(2, 3, 4, image.Black)
+	gc.Save()
+	if !gc.Current.HasShadow() || gc.Current.ShadowOffsetY != 3 || gc.Current.ShadowBlur != 4 {
+		t.Error("Save should keep the shadow")
+	}
+	gc.SetShadow(0, 0, 0, image.Black)
+	if gc.Current.HasShadow() {
+		t.Error("a shadow neither offset nor blurred should not be drawn")
+	}
+	gc.Restore()
+	if !gc.Current.HasShadow() {
+		t.Error("Restore should restore the shadow")
+	}
+	gc.SetShadow(2, 3, 4, image.Transparent)
+	if gc.Current.HasShadow() {
+		t.Error("a transparent shadow should not be drawn")
+	}
+}
+
+func TestStackGraphicContext_Mask(t *testing.T) {
+	gc := NewStackGraphicContext()
+	mask := image.NewAlpha(image.Rect(0, 0, 10, 10))
+	gc.Translate(5, 0)
+	gc.SetMask(mask, draw2d.NewScaleMatrix(2, 2))
+	gc.Save()
+	if m := gc.Current.Mask; m == nil || m.Image != mask || m.Luminance {
+		t.Fatal("Save should keep the alpha mask")
+	}
+	if x0, y0, x1, y1, _ := gc.Current.Mask.Bounds(); x0 != 5 || y0 != 0 || x1 != 25 || y1 != 20 {
+		t.Errorf("got mask bounds %v %v %v %v, want the image scaled then translated", x0, y0, x1, y1)
+	}
+	gc.MoveTo(0, 0)
+	gc.LineTo(10, 0)
+	gc.LineTo(0, 10)
+	gc.MaskWithPath()
+	if m := gc.Current.Mask; m.Image != nil || len(m.Paths) != 1 || m.Paths[0].IsEmpty() {
+		t.Fatal("MaskWithPath should mask with the current path")
+	}
+	if !gc.Current.Path.IsEmpty() {
+		t.Error("MaskWithPath should clear the current path")
+	}
+	// the spare capacity of the paths of the caller is not written
+	paths := make([]*draw2d.Path, 1, 2)
+	paths[0] = &draw2d.Path{}
+	paths[0].MoveTo(0, 0)
+	gc.MaskWithPath(paths...)
+	if paths[:2][1] != nil {
+		t.Error("MaskWithPath should not append to the paths")
+	}
+	gc.SetLuminanceMask(nil, draw2d.NewIdentityMatrix())
+	if gc.Current.Mask != nil {
+		t.Error("a nil mask should remove the mask")
+	}
+	gc.Restore()
+	if m := gc.Current.Mask; m == nil || m.Image != mask {
+		t.Error("Restore should restore the mask")
+	}
+}
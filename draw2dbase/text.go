@@ -38,6 +38,8 @@ func renderGlyph(gc draw2d.GraphicContext, fontName string, chr rune) *Glyph {
 	gc.Save()
 	defer gc.Restore()
 	gc.BeginPath()
+	// glyphs are cached upright, DrawString turns them
+	gc.SetWritingMode(draw2d.WritingModeHorizontal)
 	width := gc.CreateStringPath(string(chr), 0, 0)
 	path := gc.GetPath()
 	return &Glyph{
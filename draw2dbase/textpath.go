@@ -0,0 +1,147 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+)
+
+// PathWalker measures a flattened path and finds the points at a given
+// distance along it. Sub paths are walked one after the other, jumps
+// between them do not count in the length.
+type PathWalker struct {
+	// segments holds x0, y0, x1, y1 of each segment
+	segments []float64
+	// starts holds the distance at which each segment begins
+	starts []float64
+	length float64
+	// current position while flattening
+	x, y, startX, startY float64
+}
+
+// NewPathWalker flattens path and returns a PathWalker for it
+func NewPathWalker(path *draw2d.Path) *PathWalker {
+	walker := &PathWalker{}
+	Flatten(path, walker, 1)
+	return walker
+}
+
+// MoveTo implements Flattener
+func (w *PathWalker) MoveTo(x, y float64) {
+	w.x, w.y = x, y
+	w.startX, w.startY = x, y
+}
+
+// LineTo implements Flattener
+func (w *PathWalker) LineTo(x, y float64) {
+	l := math.Hypot(x-w.x, y-w.y)
+	if l > 0 {
+		w.segments = append(w.segments, w.x, w.y, x, y)
+		w.starts = append(w.starts, w.length)
+		w.length += l
+	}
+	w.x, w.y = x, y
+}
+
+// LineJoin implements Flattener
+func (w *PathWalker) LineJoin() {}
+
+// Close implements Flattener
+func (w *PathWalker) Close() {
+	w.LineTo(w.startX, w.startY)
+}
+
+// End implements Flattener
+func (w *PathWalker) End() {}
+
+// Length returns the total length of the path
+func (w *PathWalker) Length() float64 {
+	return w.length
+}
+
+// PointAt returns the point at distance along the path and the angle in
+// radian of the tangent at that point. ok is false if distance is outside
+// the path.
+func (w *PathWalker) PointAt(distance float64) (x, y, angle float64, ok bool) {
+	if distance < 0 || distance > w.length || len(w.starts) == 0 {
+		return 0, 0, 0, false
+	}
+	// find the last segment starting before distance
+	lo, hi := 0, len(w.starts)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if w.starts[mid] <= distance {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	s := w.segments[lo*4 : lo*4+4]
+	dx, dy := s[2]-s[0], s[3]-s[1]
+	t := (distance - w.starts[lo]) / math.Hypot(dx, dy)
+	return s[0] + dx*t, s[1] + dy*t, math.Atan2(dy, dx), true
+}
+
+// AnchorOffset returns the distance along the path at which text of the
+// given width starts when anchored at offset
+func AnchorOffset(anchor draw2d.TextAnchor, offset, width float64) float64 {
+	switch anchor {
+	case draw2d.TextAnchorMiddle:
+		return offset - width/2
+	case draw2d.TextAnchorEnd:
+		return offset - width
+	}
+	return offset
+}
+
+// DrawStringOnPath draws text along path with the glyphs of glyphCache,
+// placing the middle of each glyph on the path and rotating it to the tangent
+// at that point, marks follow their base glyph. Glyphs falling outside the
+// path are not drawn. font and scale are the current font of gc and its
+// scale, used to shape the text. It returns the width of the text.
+func DrawStringOnPath(gc draw2d.GraphicContext, glyphCache GlyphCache, font *truetype.Font, scale float64,
+	text string, path *draw2d.Path, offset float64, drawType func(g *Glyph, gc draw2d.GraphicContext)) float64 {
+	fontName := gc.GetFontName()
+	shaped, width := Shape(font, scale, text)
+	glyphs := make([]*Glyph, len(shaped))
+	for i, g := range shaped {
+		glyphs[i] = FetchShapedGlyph(gc, glyphCache, fontName, font, scale, g)
+	}
+
+	walker := NewPathWalker(path)
+	start := AnchorOffset(gc.GetTextPathAnchor(), offset, width)
+	for i, glyph := range glyphs {
+		// marks are placed with their base
+		base := i
+		for shaped[base].Base >= 0 {
+			base = shaped[base].Base
+		}
+		middle := start + shaped[base].X + shaped[base].Advance/2
+		x, y, angle, ok := walker.PointAt(middle)
+		if !ok {
+			continue
+		}
+		gc.Save()
+		gc.BeginPath()
+		gc.Translate(x, y)
+		gc.Rotate(angle)
+		gc.Translate(shaped[i].X-shaped[base].X-shaped[base].Advance/2, shaped[i].Y)
+		drawType(glyph, gc)
+		gc.Restore()
+	}
+	return width
+}
+
+// FillGlyph fills the glyph path at (0, 0), it can be given to DrawStringOnPath
+func FillGlyph(g *Glyph, gc draw2d.GraphicContext) {
+	gc.Fill(g.Path)
+}
+
+// StrokeGlyph strokes the glyph path at (0, 0), it can be given to DrawStringOnPath
+func StrokeGlyph(g *Glyph, gc draw2d.GraphicContext) {
+	gc.Stroke(g.Path)
+}
@@ -0,0 +1,79 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+)
+
+func TestPathWalker_Polyline(t *testing.T) {
+	path := &draw2d.Path{}
+	path.MoveTo(0, 0)
+	path.LineTo(10, 0)
+	path.LineTo(10, 10)
+
+	walker := NewPathWalker(path)
+	if walker.Length() != 20 {
+		t.Fatalf("Length() = %v, want 20", walker.Length())
+	}
+	x, y, angle, ok := walker.PointAt(5)
+	if !ok || x != 5 || y != 0 || angle != 0 {
+		t.Errorf("PointAt(5) = %v, %v, %v, %v", x, y, angle, ok)
+	}
+	x, y, angle, ok = walker.PointAt(15)
+	if !ok || x != 10 || y != 5 || math.Abs(angle-math.Pi/2) > 1e-9 {
+		t.Errorf("PointAt(15) = %v, %v, %v, %v", x, y, angle, ok)
+	}
+	if _, _, _, ok = walker.PointAt(21); ok {
+		t.Error("PointAt beyond the path should not be ok")
+	}
+}
+
+func TestPathWalker_SubPathsAndClose(t *testing.T) {
+	path := &draw2d.Path{}
+	path.MoveTo(0, 0)
+	path.LineTo(10, 0)
+	path.MoveTo(100, 100)
+	path.LineTo(110, 100)
+	path.LineTo(110, 110)
+	path.Close()
+
+	walker := NewPathWalker(path)
+	want := 10 + 20 + 10*math.Sqrt2
+	if math.Abs(walker.Length()-want) > 1e-9 {
+		t.Errorf("Length() = %v, want %v", walker.Length(), want)
+	}
+	x, y, _, _ := walker.PointAt(12)
+	if x != 102 || y != 100 {
+		t.Errorf("PointAt(12) = %v, %v, want 102, 100", x, y)
+	}
+}
+
+func TestPathWalker_Arc(t *testing.T) {
+	path := &draw2d.Path{}
+	path.ArcTo(0, 0, 50, 50, 0, math.Pi)
+	walker := NewPathWalker(path)
+	if math.Abs(walker.Length()-50*math.Pi) > 1 {
+		t.Errorf("Length() = %v, want about %v", walker.Length(), 50*math.Pi)
+	}
+}
+
+func TestAnchorOffset(t *testing.T) {
+	tests := []struct {
+		anchor   draw2d.TextAnchor
+		expected float64
+	}{
+		{draw2d.TextAnchorStart, 100},
+		{draw2d.TextAnchorMiddle, 80},
+		{draw2d.TextAnchorEnd, 60},
+	}
+	for _, tt := range tests {
+		if got := AnchorOffset(tt.anchor, 100, 40); got != tt.expected {
+			t.Errorf("AnchorOffset(%v) = %v, want %v", tt.anchor, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// uprightRanges are the blocks of characters drawn upright in vertical text:
+// hangul, CJK radicals, symbols and punctuation, kana, ideographs, yi,
+// vertical forms and fullwidth forms
+var uprightRanges = [][2]rune{
+	{0x1100, 0x11FF},
+	{0x2E80, 0x2FFF},
+	{0x3000, 0x303F},
+	{0x3040, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xAC00, 0xD7AF},
+	{0xF900, 0xFAFF},
+	{0xFE10, 0xFE1F},
+	{0xFE30, 0xFE4F},
+	{0xFF01, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x20000, 0x3FFFD},
+}
+
+// IsUpright returns true if r is drawn upright in vertical text, an
+// approximation of the Vertical_Orientation property of Unicode UAX #50.
+// Other characters are turned sideways.
+func IsUpright(r rune) bool {
+	for _, rg := range uprightRanges {
+		if r < rg[0] {
+			return false
+		}
+		if r <= rg[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// ShapeString shapes text for the writing mode, with Shape or ShapeVertical.
+// The returned advance is the width of horizontal text and the height of
+// vertical text.
+func ShapeString(f *truetype.Font, scale float64, mode draw2d.WritingMode, text string) (glyphs []ShapedGlyph, advance float64) {
+	if mode == draw2d.WritingModeVertical {
+		return ShapeVertical(f, scale, text)
+	}
+	return Shape(f, scale, text)
+}
+
+// ShapeVertical lays out text in a column from top to bottom, its origin is
+// the top of the column on its center line. CJK characters are upright and
+// centered on the line, they take their vertical alternates from the GSUB
+// 'vert' feature and advance by the vertical metrics of the vhea and vmtx
+// tables, or by the height of the font if it has none. Runs of other
+// characters are shaped with Shape and turned 90° clockwise, their baseline
+// placed so that the ascent and descent of the font are centered on the line.
+func ShapeVertical(f *truetype.Font, scale float64, text string) (glyphs []ShapedGlyph, height float64) {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return nil, 0
+	}
+	m := GetFontMetrics(f, scale)
+	hasVmtx := FontTable(draw2d.GetFontFileData(f), "vmtx") != nil
+	s := &shaper{font: f, scale: scale, layout: getLayout(f)}
+	glyphBuf := &truetype.GlyphBuf{}
+	for start := 0; start < len(runes); {
+		upright := IsUpright(runes[start])
+		end := start + 1
+		for end < len(runes) && IsUpright(runes[end]) == upright {
+			end++
+		}
+		if !upright {
+			run, width := Shape(f, scale, string(runes[start:end]))
+			offset := len(glyphs)
+			for _, g := range run {
+				g.X, g.Y = SidewaysBaseline(m)-g.Y, height+g.X
+				g.Rotated = true
+				if g.Base >= 0 {
+					g.Base += offset
+				}
+				glyphs = append(glyphs, g)
+			}
+			height += width
+			start = end
+			continue
+		}
+
+		s.run = s.run[:0]
+		for _, r := range runes[start:end] {
+			s.run = append(s.run, shapingGlyph{index: f.Index(r), r: r, base: -1})
+		}
+		s.substitute("hani", "vert", nil)
+		for _, g := range s.run {
+			advanceWidth := float64(f.HMetric(fixed.Int26_6(scale), g.index).AdvanceWidth) / 64
+			// top is the distance from the top of the glyph cell to its baseline
+			advance, top := m.Ascent+m.Descent, m.Ascent
+			if hasVmtx {
+				v := f.VMetric(fixed.Int26_6(scale), g.index)
+				advance = float64(v.AdvanceHeight) / 64
+				top = float64(v.TopSideBearing) / 64
+				if err := glyphBuf.Load(f, fixed.Int26_6(scale), g.index, font.HintingNone); err == nil {
+					top += float64(glyphBuf.Bounds.Max.Y) / 64
+				}
+			}
+			glyphs = append(glyphs, ShapedGlyph{
+				Index:       g.index,
+				Rune:        g.r,
+				Substituted: g.substituted,
+				X:           -advanceWidth / 2,
+				Y:           height + top,
+				Advance:     advance,
+				Base:        -1,
+			})
+			height += advance
+		}
+		start = end
+	}
+	return glyphs, height
+}
+
+// SidewaysBaseline returns the horizontal offset from the center line of a
+// column to the baseline of the sideways text
+func SidewaysBaseline(m FontMetrics) float64 {
+	return -(m.Ascent - m.Descent) / 2
+}
+
+// GlyphPoint returns the position of the glyph outline point p relative to
+// the origin of the text g belongs to
+func GlyphPoint(p truetype.Point, g ShapedGlyph) (x, y float64) {
+	x, y = pointToF64Point(p)
+	if g.Rotated {
+		x, y = -y, x
+	}
+	return g.X + x, g.Y + y
+}
+
+// rotatedPath turns the points added to a PathBuilder 90° clockwise around
+// the origin and translates them to x, y
+type rotatedPath struct {
+	draw2d.PathBuilder
+	x, y float64
+}
+
+func (p rotatedPath) MoveTo(x, y float64) {
+	p.PathBuilder.MoveTo(p.x-y, p.y+x)
+}
+
+func (p rotatedPath) LineTo(x, y float64) {
+	p.PathBuilder.LineTo(p.x-y, p.y+x)
+}
+
+func (p rotatedPath) QuadCurveTo(cx, cy, x, y float64) {
+	p.PathBuilder.QuadCurveTo(p.x-cy, p.y+cx, p.x-y, p.y+x)
+}
+
+// AddGlyph adds the outline of the shaped glyph g of f at scale to path,
+// for a text drawn at x, y
+func AddGlyph(path draw2d.PathBuilder, f *truetype.Font, scale float64, g ShapedGlyph, x, y float64) error {
+	glyphBuf := &truetype.GlyphBuf{}
+	if err := glyphBuf.Load(f, fixed.Int26_6(scale), g.Index, font.HintingNone); err != nil {
+		return err
+	}
+	dx, dy := x+g.X, y+g.Y
+	if g.Rotated {
+		path, dx, dy = rotatedPath{path, dx, dy}, 0, 0
+	}
+	e0 := 0
+	for _, e1 := range glyphBuf.Ends {
+		DrawContour(path, glyphBuf.Points[e0:e1], dx, dy)
+		e0 = e1
+	}
+	return nil
+}
+
+// DrawString draws text at x, y in the writing mode of gc and with its
+// decoration lines, the glyphs coming from glyphCache. font and scale are
+// the current font of gc and its scale, drawType is FillGlyph or StrokeGlyph.
+// Upright glyphs are fitted to the pixel grid as set by rendering when the
+// transformation of gc is a translation. It returns the advance of the text.
+func DrawString(gc draw2d.GraphicContext, glyphCache GlyphCache, font *truetype.Font, scale float64, text string, x, y float64, rendering GlyphRendering, drawType func(g *Glyph, gc draw2d.GraphicContext)) float64 {
+	glyphs, advance := ShapeString(font, scale, gc.GetWritingMode(), text)
+	fontName := gc.GetFontName()
+	tr := gc.GetMatrixTransform()
+	aligned := rendering.aligned() && tr.IsTranslation()
+	for _, g := range glyphs {
+		if aligned && !g.Rotated {
+			px, py, variant := rendering.align(tr.TransformPoint(x+g.X, y+g.Y))
+			glyph := FetchGlyphVariant(gc, glyphCache, fontName, font, scale, g, variant)
+			gc.Save()
+			gc.BeginPath()
+			gc.SetMatrixTransform(draw2d.NewTranslationMatrix(px, py))
+			drawType(glyph, gc)
+			gc.Restore()
+			continue
+		}
+		glyph := FetchShapedGlyph(gc, glyphCache, fontName, font, scale, g)
+		gc.Save()
+		gc.BeginPath()
+		gc.Translate(x+g.X, y+g.Y)
+		if g.Rotated {
+			gc.Rotate(math.Pi / 2)
+		}
+		drawType(glyph, gc)
+		gc.Restore()
+	}
+	if gc.GetTextDecoration() != draw2d.TextDecorationNone {
+		DrawDecorations(gc, GetFontMetrics(font, scale), x, y, advance, drawType)
+	}
+	return advance
+}
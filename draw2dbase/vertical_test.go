@@ -0,0 +1,119 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dbase
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestIsUpright(t *testing.T) {
+	for _, r := range "漢かカ가。、Ａ" {
+		if !IsUpright(r) {
+			t.Errorf("%q should be upright", r)
+		}
+	}
+	for _, r := range "Aé1-«א" {
+		if IsUpright(r) {
+			t.Errorf("%q should be turned sideways", r)
+		}
+	}
+}
+
+func TestShapeVertical(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	scale := 20.0 * 64
+	m := GetFontMetrics(f, scale)
+	_, width := Shape(f, scale, "Ab")
+
+	glyphs, height := ShapeVertical(f, scale, "Ab漢c")
+	if len(glyphs) != 4 {
+		t.Fatalf("expected 4 glyphs, got %d", len(glyphs))
+	}
+	for i, g := range glyphs {
+		if g.Rotated != (i != 2) {
+			t.Errorf("glyph %d: only the ideograph should be upright, got %+v", i, g)
+		}
+	}
+	if glyphs[0].X != SidewaysBaseline(m) || glyphs[0].Y != 0 {
+		t.Errorf("the sideways run should start at the top of the column, got %+v", glyphs[0])
+	}
+	if glyphs[1].Y <= glyphs[0].Y {
+		t.Errorf("sideways glyphs should progress downwards, got %+v", glyphs[:2])
+	}
+
+	// luxi has vhea and vmtx tables, the ideograph is its missing glyph
+	ideograph := glyphs[2]
+	v := f.VMetric(fixed.Int26_6(scale), ideograph.Index)
+	if ideograph.Advance != float64(v.AdvanceHeight)/64 {
+		t.Errorf("upright advance %v should come from vmtx, want %v", ideograph.Advance, float64(v.AdvanceHeight)/64)
+	}
+	advanceWidth := float64(f.HMetric(fixed.Int26_6(scale), ideograph.Index).AdvanceWidth) / 64
+	if math.Abs(ideograph.X+advanceWidth/2) > 1e-9 || ideograph.Y <= width {
+		t.Errorf("upright glyph should be centered below the sideways run, got %+v", ideograph)
+	}
+	if glyphs[3].Y != width+ideograph.Advance {
+		t.Errorf("sideways run after the ideograph starts at %v, want %v", glyphs[3].Y, width+ideograph.Advance)
+	}
+	_, cWidth := Shape(f, scale, "c")
+	if math.Abs(height-(width+ideograph.Advance+cWidth)) > 1e-9 {
+		t.Errorf("unexpected column height %v", height)
+	}
+
+	// without the font data the vertical advance is the height of the font
+	f = loadTestFont(t, truetype.Parse)
+	m = GetFontMetrics(f, scale)
+	glyphs, height = ShapeVertical(f, scale, "漢")
+	if height != m.Ascent+m.Descent || glyphs[0].Y != m.Ascent {
+		t.Errorf("unexpected fallback metrics %+v, height %v", glyphs[0], height)
+	}
+}
+
+func TestMeasureString_Vertical(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	horizontal := MeasureString(f, 20*64, draw2d.WritingModeHorizontal, draw2d.TextDecorationNone, "Hello")
+	vertical := MeasureString(f, 20*64, draw2d.WritingModeVertical, draw2d.TextDecorationNone, "Hello")
+	if vertical.Width != horizontal.Width {
+		t.Errorf("the column height %v should be the width of the sideways text %v", vertical.Width, horizontal.Width)
+	}
+	// turning the text clockwise maps x to y and y to -x
+	offset := SidewaysBaseline(GetFontMetrics(f, 20*64))
+	for _, d := range []float64{
+		vertical.Top - horizontal.Left,
+		vertical.Bottom - horizontal.Right,
+		vertical.Left - (offset - horizontal.Bottom),
+		vertical.Right - (offset - horizontal.Top),
+	} {
+		if math.Abs(d) > 1e-9 {
+			t.Errorf("vertical bounds %+v do not match the rotated horizontal bounds %+v", vertical, horizontal)
+			break
+		}
+	}
+}
+
+func TestAddGlyph_Rotated(t *testing.T) {
+	f := loadTestFont(t, draw2d.ParseFont)
+	g := ShapedGlyph{Index: f.Index('l'), Base: -1}
+	upright, rotated := &draw2d.Path{}, &draw2d.Path{}
+	if err := AddGlyph(upright, f, 20*64, g, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	g.Rotated = true
+	if err := AddGlyph(rotated, f, 20*64, g, 10, 5); err != nil {
+		t.Fatal(err)
+	}
+	if len(upright.Points) == 0 || len(upright.Points) != len(rotated.Points) {
+		t.Fatalf("paths should have the same points, got %d and %d", len(upright.Points), len(rotated.Points))
+	}
+	for i := 0; i < len(upright.Points); i += 2 {
+		x, y := upright.Points[i], upright.Points[i+1]
+		if rotated.Points[i] != 10-y || rotated.Points[i+1] != 5+x {
+			t.Fatalf("point %v, %v should turn to %v, %v, got %v, %v", x, y, 10-y, 5+x, rotated.Points[i], rotated.Points[i+1])
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dfilter
+
+import (
+	"image"
+	"math"
+)
+
+// GaussianBlur blurs an image by a Gaussian of standard deviations
+// StdDeviationX and StdDeviationY pixels, as feGaussianBlur
+type GaussianBlur struct {
+	StdDeviationX, StdDeviationY float64
+}
+
+// NewGaussianBlur returns a Gaussian blur of standard deviation
+// stdDeviation in both directions
+func NewGaussianBlur(stdDeviation float64) GaussianBlur {
+	return GaussianBlur{stdDeviation, stdDeviation}
+}
+
+// Apply returns the blurred img
+func (f GaussianBlur) Apply(img *image.RGBA) *image.RGBA {
+	p := newPlanes(img)
+	p.separable(convolution(gaussianKernel(f.StdDeviationX)), convolution(gaussianKernel(f.StdDeviationY)))
+	return p.image(img.Rect)
+}
+
+// BoxBlur blurs an image by averaging the pixels of the box of
+// 2*RadiusX+1 x 2*RadiusY+1 pixels centered on each pixel
+type BoxBlur struct {
+	RadiusX, RadiusY int
+}
+
+// Apply returns the blurred img
+func (f BoxBlur) Apply(img *image.RGBA) *image.RGBA {
+	p := newPlanes(img)
+	p.separable(convolution(boxKernel(f.RadiusX)), convolution(boxKernel(f.RadiusY)))
+	return p.image(img.Rect)
+}
+
+// gaussianKernel returns the normalized weights of a Gaussian of standard
+// deviation sigma, from its center to 3 sigma
+func gaussianKernel(sigma float64) []float64 {
+	if sigma <= 0 {
+		return []float64{1}
+	}
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, radius+1)
+	sum := 0.0
+	for i := range kernel {
+		kernel[i] = math.Exp(-float64(i*i) / (2 * sigma * sigma))
+		sum += kernel[i]
+		if i > 0 {
+			sum += kernel[i]
+		}
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	return kernel
+}
+
+// boxKernel returns the weights of a box of radius pixels
+func boxKernel(radius int) []float64 {
+	radius = max(radius, 0)
+	kernel := make([]float64, radius+1)
+	for i := range kernel {
+		kernel[i] = 1 / float64(2*radius+1)
+	}
+	return kernel
+}
+
+// convolution returns the convolution of lines by the symmetric kernel, of
+// which kernel[i] is the weight of the values i apart. Values outside of
+// the lines are 0.
+func convolution(kernel []float64) func(dst, src []float64, n, step int) {
+	return func(dst, src []float64, n, step int) {
+		for i := range n {
+			v := src[i*step] * kernel[0]
+			for k := 1; k < len(kernel); k++ {
+				if i-k >= 0 {
+					v += src[(i-k)*step] * kernel[k]
+				}
+				if i+k < n {
+					v += src[(i+k)*step] * kernel[k]
+				}
+			}
+			dst[i*step] = v
+		}
+	}
+}
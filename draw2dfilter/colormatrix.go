@@ -0,0 +1,139 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dfilter
+
+import (
+	"image"
+	"math"
+)
+
+// ColorMatrix transforms the colors of an image, as feColorMatrix of type
+// matrix. Its rows compute the red, green, blue and alpha components from
+// the non premultiplied components, from 0 to 1, and an offset:
+//
+//	R' = m[0]*R + m[1]*G + m[2]*B + m[3]*A + m[4]
+//	G' = m[5]*R + ...
+type ColorMatrix [20]float64
+
+// IdentityMatrix is the color matrix leaving the colors unchanged
+var IdentityMatrix = ColorMatrix{
+	1, 0, 0, 0, 0,
+	0, 1, 0, 0, 0,
+	0, 0, 1, 0, 0,
+	0, 0, 0, 1, 0,
+}
+
+// Apply returns img of transformed colors
+func (m ColorMatrix) Apply(img *image.RGBA) *image.RGBA {
+	dst := image.NewRGBA(img.Rect)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		src, row := img.Pix[img.PixOffset(img.Rect.Min.X, y):], dst.Pix[dst.PixOffset(dst.Rect.Min.X, y):]
+		for x := range img.Rect.Dx() {
+			var c [4]float64
+			if a := src[4*x+3]; a != 0 {
+				for i := range 3 {
+					c[i] = float64(src[4*x+i]) / float64(a)
+				}
+				c[3] = float64(a) / 0xff
+			}
+			a := clamp(m[15]*c[0] + m[16]*c[1] + m[17]*c[2] + m[18]*c[3] + m[19])
+			row[4*x+3] = uint8(a*0xff + 0.5)
+			for i := range 3 {
+				v := clamp(m[5*i]*c[0] + m[5*i+1]*c[1] + m[5*i+2]*c[2] + m[5*i+3]*c[3] + m[5*i+4])
+				row[4*x+i] = uint8(v*a*0xff + 0.5)
+			}
+		}
+	}
+	return dst
+}
+
+// Grayscale returns the matrix converting the colors to gray, as the
+// grayscale function of css. amount is from 0, no change, to 1.
+func Grayscale(amount float64) ColorMatrix {
+	a := 1 - clamp(amount)
+	return ColorMatrix{
+		0.2126 + 0.7874*a, 0.7152 - 0.7152*a, 0.0722 - 0.0722*a, 0, 0,
+		0.2126 - 0.2126*a, 0.7152 + 0.2848*a, 0.0722 - 0.0722*a, 0, 0,
+		0.2126 - 0.2126*a, 0.7152 - 0.7152*a, 0.0722 + 0.9278*a, 0, 0,
+		0, 0, 0, 1, 0,
+	}
+}
+
+// Sepia returns the matrix converting the colors to sepia, as the sepia
+// function of css. amount is from 0, no change, to 1.
+func Sepia(amount float64) ColorMatrix {
+	a := 1 - clamp(amount)
+	return ColorMatrix{
+		0.393 + 0.607*a, 0.769 - 0.769*a, 0.189 - 0.189*a, 0, 0,
+		0.349 - 0.349*a, 0.686 + 0.314*a, 0.168 - 0.168*a, 0, 0,
+		0.272 - 0.272*a, 0.534 - 0.534*a, 0.131 + 0.869*a, 0, 0,
+		0, 0, 0, 1, 0,
+	}
+}
+
+// Saturate returns the matrix saturating the colors by s, as feColorMatrix
+// of type saturate: 0 converts them to gray, 1 leaves them unchanged and
+// greater values over saturate them
+func Saturate(s float64) ColorMatrix {
+	s = math.Max(s, 0)
+	return ColorMatrix{
+		0.213 + 0.787*s, 0.715 - 0.715*s, 0.072 - 0.072*s, 0, 0,
+		0.213 - 0.213*s, 0.715 + 0.285*s, 0.072 - 0.072*s, 0, 0,
+		0.213 - 0.213*s, 0.715 - 0.715*s, 0.072 + 0.928*s, 0, 0,
+		0, 0, 0, 1, 0,
+	}
+}
+
+// HueRotate returns the matrix rotating the hue of the colors by angle
+// radians, as feColorMatrix of type hueRotate
+func HueRotate(angle float64) ColorMatrix {
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	return ColorMatrix{
+		0.213 + cos*0.787 - sin*0.213, 0.715 - cos*0.715 - sin*0.715, 0.072 - cos*0.072 + sin*0.928, 0, 0,
+		0.213 - cos*0.213 + sin*0.143, 0.715 + cos*0.285 + sin*0.140, 0.072 - cos*0.072 - sin*0.283, 0, 0,
+		0.213 - cos*0.213 - sin*0.787, 0.715 - cos*0.715 + sin*0.715, 0.072 + cos*0.928 + sin*0.072, 0, 0,
+		0, 0, 0, 1, 0,
+	}
+}
+
+// Brightness returns the matrix multiplying the colors by amount, as the
+// brightness function of css: 0 makes them black and 1 leaves them
+// unchanged
+func Brightness(amount float64) ColorMatrix {
+	b := math.Max(amount, 0)
+	return ColorMatrix{
+		b, 0, 0, 0, 0,
+		0, b, 0, 0, 0,
+		0, 0, b, 0, 0,
+		0, 0, 0, 1, 0,
+	}
+}
+
+// Contrast returns the matrix scaling the colors around the middle gray by
+// amount, as the contrast function of css: 0 makes them gray and 1 leaves
+// them unchanged
+func Contrast(amount float64) ColorMatrix {
+	c := math.Max(amount, 0)
+	o := 0.5 - 0.5*c
+	return ColorMatrix{
+		c, 0, 0, 0, o,
+		0, c, 0, 0, o,
+		0, 0, c, 0, o,
+		0, 0, 0, 1, 0,
+	}
+}
+
+// Mul returns the matrix applying m and then n
+func (m ColorMatrix) Mul(n ColorMatrix) ColorMatrix {
+	var r ColorMatrix
+	for i := range 4 {
+		for j := range 5 {
+			for k := range 4 {
+				r[5*i+j] += n[5*i+k] * m[5*k+j]
+			}
+		}
+		r[5*i+4] += n[5*i+4]
+	}
+	return r
+}
@@ -0,0 +1,96 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dfilter
+
+import (
+	"image"
+)
+
+// Convolution convolves an image with a kernel of OrderX x OrderY weights,
+// as feConvolveMatrix with the edge mode none. The kernel is centered on
+// the pixel at OrderX/2, OrderY/2 and is rotated by 180 degrees, as in
+// svg. The result is divided by Divisor, or by the sum of the weights if
+// zero and the sum is not, and Bias is added.
+type Convolution struct {
+	OrderX, OrderY int
+	Kernel         []float64
+	Divisor, Bias  float64
+	// PreserveAlpha convolves the non premultiplied color components and
+	// keeps the alpha, instead of convolving all the premultiplied
+	// components
+	PreserveAlpha bool
+}
+
+// Sharpen returns a 3x3 convolution sharpening the images by amount, 0
+// leaving them unchanged
+func Sharpen(amount float64) Convolution {
+	return Convolution{OrderX: 3, OrderY: 3, PreserveAlpha: true, Kernel: []float64{
+		0, -amount, 0,
+		-amount, 1 + 4*amount, -amount,
+		0, -amount, 0,
+	}}
+}
+
+// Apply returns the convolved img. It returns img unchanged if the kernel
+// does not have OrderX x OrderY weights.
+func (f Convolution) Apply(img *image.RGBA) *image.RGBA {
+	if f.OrderX <= 0 || f.OrderY <= 0 || len(f.Kernel) != f.OrderX*f.OrderY {
+		return img
+	}
+	divisor := f.Divisor
+	if divisor == 0 {
+		for _, w := range f.Kernel {
+			divisor += w
+		}
+		if divisor == 0 {
+			divisor = 1
+		}
+	}
+	src := newPlanes(img)
+	components := 4
+	if f.PreserveAlpha {
+		components = 3
+		for i, a := range src.rgba[3] {
+			if a != 0 {
+				for c := range 3 {
+					src.rgba[c][i] /= a
+				}
+			}
+		}
+	}
+	dst := &planes{width: src.width, height: src.height}
+	for c := range dst.rgba {
+		dst.rgba[c] = make([]float64, src.width*src.height)
+	}
+	copy(dst.rgba[3], src.rgba[3])
+	targetX, targetY := f.OrderX/2, f.OrderY/2
+	for y := range src.height {
+		for x := range src.width {
+			i := y*src.width + x
+			for c := range components {
+				v := 0.0
+				for ky := range f.OrderY {
+					sy := y - targetY + ky
+					if sy < 0 || sy >= src.height {
+						continue
+					}
+					for kx := range f.OrderX {
+						sx := x - targetX + kx
+						if sx < 0 || sx >= src.width {
+							continue
+						}
+						v += src.rgba[c][sy*src.width+sx] * f.Kernel[(f.OrderY-1-ky)*f.OrderX+f.OrderX-1-kx]
+					}
+				}
+				dst.rgba[c][i] = v/divisor + f.Bias
+			}
+			if f.PreserveAlpha {
+				for c := range 3 {
+					dst.rgba[c][i] = clamp(dst.rgba[c][i]) * dst.rgba[3][i]
+				}
+			}
+		}
+	}
+	return dst.image(img.Rect)
+}
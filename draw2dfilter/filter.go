@@ -0,0 +1,93 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+// Package draw2dfilter provides image filter effects modelled after the
+// filter primitives of svg: blurs, color matrices, morphology and
+// convolution. They filter the images drawn by draw2dimg and the layers of
+// its groups, and are emitted as svg filters by draw2dsvg. As the filter
+// functions of css, they operate on sRGB colors.
+package draw2dfilter
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Filter is an image filter effect
+type Filter interface {
+	// Apply returns the filtered img, of the same bounds. The pixels outside
+	// of img are transparent.
+	Apply(img *image.RGBA) *image.RGBA
+}
+
+// Apply applies the filters to img in order
+func Apply(img image.Image, filters ...Filter) *image.RGBA {
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(img.Bounds())
+		draw.Draw(rgba, rgba.Rect, img, rgba.Rect.Min, draw.Src)
+	}
+	for _, f := range filters {
+		rgba = f.Apply(rgba)
+	}
+	return rgba
+}
+
+// planes holds the premultiplied components of an image, from 0 to 1, in
+// rows of width values
+type planes struct {
+	width, height int
+	rgba          [4][]float64
+}
+
+// newPlanes returns the planes of the components of img
+func newPlanes(img *image.RGBA) *planes {
+	p := &planes{width: img.Rect.Dx(), height: img.Rect.Dy()}
+	for c := range p.rgba {
+		p.rgba[c] = make([]float64, p.width*p.height)
+	}
+	for y := range p.height {
+		row := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+		for x := range p.width {
+			for c := range p.rgba {
+				p.rgba[c][y*p.width+x] = float64(row[4*x+c]) / 0xff
+			}
+		}
+	}
+	return p
+}
+
+// image returns the image of the planes, of bounds r, clamping the color
+// components to the alpha
+func (p *planes) image(r image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(r)
+	for i := range p.width * p.height {
+		a := clamp(p.rgba[3][i])
+		img.Pix[4*i+3] = uint8(a*0xff + 0.5)
+		for c := range 3 {
+			img.Pix[4*i+c] = uint8(min(clamp(p.rgba[c][i]), a)*0xff + 0.5)
+		}
+	}
+	return img
+}
+
+// clamp clamps v to [0, 1]
+func clamp(v float64) float64 {
+	return max(0, min(v, 1))
+}
+
+// separable transforms the rows of the planes with rows and then their
+// columns with columns, which transform the n values of src step apart
+// into dst
+func (p *planes) separable(rows, columns func(dst, src []float64, n, step int)) {
+	tmp := make([]float64, p.width*p.height)
+	for c := range p.rgba {
+		plane := p.rgba[c]
+		for y := range p.height {
+			rows(tmp[y*p.width:], plane[y*p.width:], p.width, 1)
+		}
+		for x := range p.width {
+			columns(plane[x:], tmp[x:], p.height, p.width)
+		}
+	}
+}
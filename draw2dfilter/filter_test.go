@@ -0,0 +1,147 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dfilter
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// square returns an image of 20x20 pixels with a square of c from 5 to 15
+func square(c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(10, 10, 30, 30))
+	for y := 15; y < 25; y++ {
+		for x := 15; x < 25; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func alphaSum(img *image.RGBA) int {
+	sum := 0
+	for i := 3; i < len(img.Pix); i += 4 {
+		sum += int(img.Pix[i])
+	}
+	return sum
+}
+
+func TestGaussianBlur(t *testing.T) {
+	img := square(color.RGBA{0, 0x80, 0, 0x80})
+	blurred := Apply(img, NewGaussianBlur(1.5))
+	if blurred.Rect != img.Rect {
+		t.Fatalf("got bounds %v, want %v", blurred.Rect, img.Rect)
+	}
+	if got, want := alphaSum(blurred), alphaSum(img); math.Abs(float64(got-want)) > 200 {
+		t.Errorf("the blur should keep the alpha sum, got %d, want %d", got, want)
+	}
+	if c := blurred.RGBAAt(14, 20); c.A == 0 || c.A >= 0x40 || c.G != c.A {
+		t.Errorf("unexpected color %v outside of the square", c)
+	}
+	if blurred.RGBAAt(14, 20) != blurred.RGBAAt(25, 20) || blurred.RGBAAt(20, 14) != blurred.RGBAAt(14, 20) {
+		t.Error("the blur should be symmetric")
+	}
+	if c := blurred.RGBAAt(20, 20); c != (color.RGBA{0, 0x80, 0, 0x80}) {
+		t.Errorf("got %v at the center of the square", c)
+	}
+}
+
+func TestBoxBlur(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 9, 9))
+	img.SetRGBA(4, 4, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	blurred := BoxBlur{RadiusX: 1, RadiusY: 2}.Apply(img)
+	for y := range 9 {
+		for x := range 9 {
+			want := uint8(0)
+			if x >= 3 && x <= 5 && y >= 2 && y <= 6 {
+				want = 0x11 // 0xff / 15
+			}
+			if got := blurred.RGBAAt(x, y).A; got != want {
+				t.Errorf("pixel (%d, %d): got alpha %#x, want %#x", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestColorMatrix(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	for _, test := range []struct {
+		name   string
+		matrix ColorMatrix
+		src    color.RGBA
+		want   color.RGBA
+	}{
+		{"identity", IdentityMatrix, color.RGBA{0x20, 0x40, 0x60, 0x80}, color.RGBA{0x20, 0x40, 0x60, 0x80}},
+		{"grayscale", Grayscale(1), red, color.RGBA{0x36, 0x36, 0x36, 0xff}},
+		{"half grayscale", Grayscale(0.5), red, color.RGBA{0x9b, 0x1b, 0x1b, 0xff}},
+		{"sepia", Sepia(1), red, color.RGBA{0x64, 0x59, 0x45, 0xff}},
+		{"saturate", Saturate(1), red, red},
+		{"hue rotate", HueRotate(0), red, red},
+		{"brightness", Brightness(0.5), color.RGBA{0x40, 0x80, 0x80, 0x80}, color.RGBA{0x20, 0x40, 0x40, 0x80}},
+		{"contrast", Contrast(0), red, color.RGBA{0x80, 0x80, 0x80, 0xff}},
+		{"product", Grayscale(1).Mul(Brightness(0.5)), red, color.RGBA{0x1b, 0x1b, 0x1b, 0xff}},
+	} {
+		img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		img.SetRGBA(0, 0, test.src)
+		if got := test.matrix.Apply(img).RGBAAt(0, 0); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+	// a hue rotation of 120 degrees turns red to green, roughly
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.SetRGBA(0, 0, red)
+	if c := HueRotate(2*math.Pi/3).Apply(img).RGBAAt(0, 0); c.G <= c.R || c.G <= c.B {
+		t.Errorf("got %v, want a green", c)
+	}
+}
+
+func TestMorphology(t *testing.T) {
+	img := square(color.RGBA{0xff, 0, 0, 0xff})
+	for _, test := range []struct {
+		op     MorphologyOperator
+		inside image.Rectangle
+	}{
+		{Erode, image.Rect(17, 16, 23, 24)},
+		{Dilate, image.Rect(13, 14, 27, 26)},
+	} {
+		filtered := Morphology{Operator: test.op, RadiusX: 2, RadiusY: 1}.Apply(img)
+		for y := 10; y < 30; y++ {
+			for x := 10; x < 30; x++ {
+				want := image.Pt(x, y).In(test.inside)
+				if got := filtered.RGBAAt(x, y).A == 0xff; got != want {
+					t.Fatalf("%v: pixel (%d, %d) opaque %v, want %v", test.op, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestConvolution(t *testing.T) {
+	img := square(color.RGBA{0xff, 0, 0, 0xff})
+	if got := Sharpen(0).Apply(img); string(got.Pix) != string(img.Pix) {
+		t.Error("a sharpen of 0 should leave the image unchanged")
+	}
+	// the kernel is rotated, its first weight takes the pixel on the right
+	shift := Convolution{OrderX: 3, OrderY: 1, Kernel: []float64{1, 0, 0}}.Apply(img)
+	for x := 10; x < 30; x++ {
+		want := x >= 14 && x < 24
+		if got := shift.RGBAAt(x, 20).A == 0xff; got != want {
+			t.Errorf("pixel (%d, 20) opaque %v, want %v", x, got, want)
+		}
+	}
+	if got := (Convolution{OrderX: 2, OrderY: 2, Kernel: []float64{1}}).Apply(img); got != img {
+		t.Error("an invalid kernel should leave the image unchanged")
+	}
+	// sharpening keeps the alpha and increases the contrast at the edges
+	img.SetRGBA(20, 20, color.RGBA{0x80, 0, 0, 0xff})
+	sharp := Sharpen(0.5).Apply(img)
+	if c := sharp.RGBAAt(20, 20); c.A != 0xff || c.R >= 0x80 {
+		t.Errorf("got %v, want a darker red", c)
+	}
+	if c := sharp.RGBAAt(14, 20); c.A != 0 {
+		t.Errorf("got %v, the transparent pixels should stay transparent", c)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dfilter
+
+import (
+	"image"
+	"math"
+)
+
+// MorphologyOperator is the operator of a Morphology
+type MorphologyOperator int
+
+const (
+	// Erode keeps the minimum of the components of the box, thinning the
+	// shapes
+	Erode MorphologyOperator = iota
+	// Dilate keeps the maximum of the components of the box, fattening the
+	// shapes
+	Dilate
+)
+
+func (op MorphologyOperator) String() string {
+	return map[MorphologyOperator]string{
+		Erode:  "erode",
+		Dilate: "dilate",
+	}[op]
+}
+
+// Morphology erodes or dilates an image, keeping for each pixel the
+// minimum or maximum of the components of the box of 2*RadiusX+1 x
+// 2*RadiusY+1 pixels centered on it, as feMorphology
+type Morphology struct {
+	Operator         MorphologyOperator
+	RadiusX, RadiusY int
+}
+
+// Apply returns the eroded or dilated img
+func (f Morphology) Apply(img *image.RGBA) *image.RGBA {
+	p := newPlanes(img)
+	p.separable(f.line(f.RadiusX), f.line(f.RadiusY))
+	return p.image(img.Rect)
+}
+
+// line returns the erosion or dilation of lines by a segment of radius
+// pixels, the values outside of the lines being 0
+func (f Morphology) line(radius int) func(dst, src []float64, n, step int) {
+	pick, start := math.Max, math.Inf(-1)
+	if f.Operator == Erode {
+		pick, start = math.Min, math.Inf(1)
+	}
+	return func(dst, src []float64, n, step int) {
+		for i := range n {
+			v := start
+			for k := i - radius; k <= i+radius; k++ {
+				if k < 0 || k >= n {
+					v = pick(v, 0)
+				} else {
+					v = pick(v, src[k*step])
+				}
+			}
+			dst[i*step] = v
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/llgcode/draw2d"
 	"github.com/llgcode/draw2d/draw2dbase"
 	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/internal/floatraster"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
@@ -122,12 +123,13 @@ func NewPainter() *Painter {
 type GraphicContext struct {
 	*draw2dbase.StackGraphicContext
 	painter          *Painter
-	fillRasterizer   *raster.Rasterizer
-	strokeRasterizer *raster.Rasterizer
+	fillRasterizer   rasterizer
+	strokeRasterizer rasterizer
 	FontCache        draw2d.FontCache
 	glyphCache       draw2dbase.GlyphCache
 	glyphBuf         *truetype.GlyphBuf
 	DPI              int
+	width, height    int
 }
 
 // NewGraphicContext creates a new Graphic context from an image.
@@ -138,9 +140,11 @@ func NewGraphicContext(width, height int) *GraphicContext {
 		raster.NewRasterizer(width, height),
 		raster.NewRasterizer(width, height),
 		draw2d.GetGlobalFontCache(),
-		draw2dbase.NewGlyphCache(),
+		draw2dbase.NewLRUGlyphCache(draw2dbase.DefaultGlyphCacheSize),
 		&truetype.GlyphBuf{},
 		92,
+		width,
+		height,
 	}
 	return gc
 }
@@ -157,16 +161,16 @@ func (gc *GraphicContext) loadCurrentFont() (*truetype.Font, error) {
 	return font, err
 }
 
-func (gc *GraphicContext) drawGlyph(glyph truetype.Index, dx, dy float64) error {
-	if err := gc.glyphBuf.Load(gc.Current.Font, fixed.Int26_6(gc.Current.Scale), glyph, font.HintingNone); err != nil {
-		return err
-	}
-	e0 := 0
-	for _, e1 := range gc.glyphBuf.Ends {
-		DrawContour(gc, gc.glyphBuf.Points[e0:e1], dx, dy)
-		e0 = e1
-	}
-	return nil
+// loadPathFont returns the bitmap or stroke font selected by the current font
+// data, which is used instead of a TrueType font
+func (gc *GraphicContext) loadPathFont() (draw2d.PathFont, bool) {
+	return draw2dbase.LoadPathFont(gc.FontCache, gc.Current.FontData)
+}
+
+// pathFontScale returns the size in pixels of the font units of font drawn
+// with the current font size
+func (gc *GraphicContext) pathFontScale(font draw2d.PathFont) float64 {
+	return draw2dbase.PathFontScale(font, gc.Current.FontSize*float64(gc.DPI)/72)
 }
 
 // CreateStringPath creates a path from the string s at x, y, and returns the string width.
@@ -174,29 +178,27 @@ func (gc *GraphicContext) drawGlyph(glyph truetype.Index, dx, dy float64) error
 // and the baseline intersect at x, y. The majority of the affected pixels will be
 // above and to the right of the point, but some may be below or to the left.
 // For example, drawing a string that starts with a 'J' in an italic font may
-// affect pixels below and left of the point.
+// affect pixels below and left of the point. In vertical writing mode x, y is
+// the top of the column on its center line and the height of the column is
+// returned.
 func (gc *GraphicContext) CreateStringPath(s string, x, y float64) float64 {
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.AddPathFontString(gc, font, gc.pathFontScale(font), s, x, y)
+	}
 	f, err := gc.loadCurrentFont()
 	if err != nil {
 		log.Println(err)
 		return 0.0
 	}
-	startx := x
-	prev, hasPrev := truetype.Index(0), false
-	for _, rune := range s {
-		index := f.Index(rune)
-		if hasPrev {
-			x += fUnitsToFloat64(f.Kern(fixed.Int26_6(gc.Current.Scale), prev, index))
-		}
-		err := gc.drawGlyph(index, x, y)
+	glyphs, width := draw2dbase.ShapeString(f, gc.Current.Scale, gc.Current.WritingMode, s)
+	for _, g := range glyphs {
+		err := draw2dbase.AddGlyph(gc, f, gc.Current.Scale, g, x, y)
 		if err != nil {
 			log.Println(err)
-			return startx - x
+			return -g.X
 		}
-		x += fUnitsToFloat64(f.HMetric(fixed.Int26_6(gc.Current.Scale), index).AdvanceWidth)
-		prev, hasPrev = index, true
 	}
-	return x - startx
+	return width
 }
 
 // FillString draws the text at point (0, 0)
@@ -206,24 +208,37 @@ func (gc *GraphicContext) FillString(text string) (width float64) {
 
 // FillStringAt draws the text at the specified point (x, y)
 func (gc *GraphicContext) FillStringAt(text string, x, y float64) (width float64) {
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.DrawPathFontString(gc, font, gc.pathFontScale(font), text, x, y, draw2dbase.FillGlyph)
+	}
 	f, err := gc.loadCurrentFont()
 	if err != nil {
 		log.Println(err)
 		return 0.0
 	}
-	startx := x
-	prev, hasPrev := truetype.Index(0), false
-	fontName := gc.GetFontName()
-	for _, r := range text {
-		index := f.Index(r)
-		if hasPrev {
-			x += fUnitsToFloat64(f.Kern(fixed.Int26_6(gc.Current.Scale), prev, index))
-		}
-		glyph := gc.glyphCache.Fetch(gc, fontName, r)
-		x += glyph.Fill(gc, x, y)
-		prev, hasPrev = index, true
+	return draw2dbase.DrawString(gc, gc.glyphCache, f, gc.Current.Scale, text, x, y, draw2dbase.GlyphRendering{}, draw2dbase.FillGlyph)
+}
+
+// FillStringOnPath draws the text along path, starting at offset from the
+// beginning of the path, each glyph rotated to follow the path tangent
+func (gc *GraphicContext) FillStringOnPath(text string, path *draw2d.Path, offset float64) (width float64) {
+	f, err := gc.loadCurrentFont()
+	if err != nil {
+		log.Println(err)
+		return 0.0
+	}
+	return draw2dbase.DrawStringOnPath(gc, gc.glyphCache, f, gc.Current.Scale, text, path, offset, draw2dbase.FillGlyph)
+}
+
+// StrokeStringOnPath draws the contour of the text along path, starting at
+// offset from the beginning of the path
+func (gc *GraphicContext) StrokeStringOnPath(text string, path *draw2d.Path, offset float64) (width float64) {
+	f, err := gc.loadCurrentFont()
+	if err != nil {
+		log.Println(err)
+		return 0.0
 	}
-	return x - startx
+	return draw2dbase.DrawStringOnPath(gc, gc.glyphCache, f, gc.Current.Scale, text, path, offset, draw2dbase.StrokeGlyph)
 }
 
 // GetStringBounds returns the approximate pixel bounds of the string s at x, y.
@@ -231,20 +246,19 @@ func (gc *GraphicContext) FillStringAt(text string, x, y float64) (width float64
 // and the baseline intersect at 0, 0 in the returned coordinates.
 // Therefore the top and left coordinates may well be negative.
 func (gc *GraphicContext) GetStringBounds(s string) (left, top, right, bottom float64) {
+	if font, ok := gc.loadPathFont(); ok {
+		m := draw2dbase.MeasurePathFont(font, gc.pathFontScale(font), gc.Current.TextDecoration, s)
+		return m.Left, m.Top, m.Right, m.Bottom
+	}
 	f, err := gc.loadCurrentFont()
 	if err != nil {
 		log.Println(err)
 		return 0, 0, 0, 0
 	}
 	top, left, bottom, right = 10e6, 10e6, -10e6, -10e6
-	cursor := 0.0
-	prev, hasPrev := truetype.Index(0), false
-	for _, rune := range s {
-		index := f.Index(rune)
-		if hasPrev {
-			cursor += fUnitsToFloat64(f.Kern(fixed.Int26_6(gc.Current.Scale), prev, index))
-		}
-		if err := gc.glyphBuf.Load(gc.Current.Font, fixed.Int26_6(gc.Current.Scale), index, font.HintingNone); err != nil {
+	glyphs, width := draw2dbase.ShapeString(f, gc.Current.Scale, gc.Current.WritingMode, s)
+	for _, g := range glyphs {
+		if err := gc.glyphBuf.Load(gc.Current.Font, fixed.Int26_6(gc.Current.Scale), g.Index, font.HintingNone); err != nil {
 			log.Println(err)
 			return 0, 0, 0, 0
 		}
@@ -252,19 +266,37 @@ func (gc *GraphicContext) GetStringBounds(s string) (left, top, right, bottom fl
 		for _, e1 := range gc.glyphBuf.Ends {
 			ps := gc.glyphBuf.Points[e0:e1]
 			for _, p := range ps {
-				x, y := pointToF64Point(p)
+				x, y := draw2dbase.GlyphPoint(p, g)
 				top = math.Min(top, y)
 				bottom = math.Max(bottom, y)
-				left = math.Min(left, x+cursor)
-				right = math.Max(right, x+cursor)
+				left = math.Min(left, x)
+				right = math.Max(right, x)
 			}
 		}
-		cursor += fUnitsToFloat64(f.HMetric(fixed.Int26_6(gc.Current.Scale), index).AdvanceWidth)
-		prev, hasPrev = index, true
+	}
+	if gc.Current.TextDecoration != draw2d.TextDecorationNone {
+		m := draw2dbase.GetFontMetrics(f, gc.Current.Scale)
+		if l, t, r, b, ok := draw2dbase.DecorationBounds(m, gc.Current.TextDecoration, gc.Current.WritingMode, width); ok {
+			top, left, bottom, right = math.Min(top, t), math.Min(left, l), math.Max(bottom, b), math.Max(right, r)
+		}
 	}
 	return left, top, right, bottom
 }
 
+// MeasureString returns the advance, ink bounds, font metrics and glyph
+// positions of the text drawn with the current font
+func (gc *GraphicContext) MeasureString(text string) draw2d.TextMetrics {
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.MeasurePathFont(font, gc.pathFontScale(font), gc.Current.TextDecoration, text)
+	}
+	f, err := gc.loadCurrentFont()
+	if err != nil {
+		log.Println(err)
+		return draw2d.TextMetrics{}
+	}
+	return draw2dbase.MeasureString(f, gc.Current.Scale, gc.Current.WritingMode, gc.Current.TextDecoration, text)
+}
+
 // StrokeString draws the contour of the text at point (0, 0)
 func (gc *GraphicContext) StrokeString(text string) (width float64) {
 	return gc.StrokeStringAt(text, 0, 0)
@@ -272,24 +304,15 @@ func (gc *GraphicContext) StrokeString(text string) (width float64) {
 
 // StrokeStringAt draws the contour of the text at point (x, y)
 func (gc *GraphicContext) StrokeStringAt(text string, x, y float64) (width float64) {
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.DrawPathFontString(gc, font, gc.pathFontScale(font), text, x, y, draw2dbase.StrokeGlyph)
+	}
 	f, err := gc.loadCurrentFont()
 	if err != nil {
 		log.Println(err)
 		return 0.0
 	}
-	startx := x
-	prev, hasPrev := truetype.Index(0), false
-	fontName := gc.GetFontName()
-	for _, r := range text {
-		index := f.Index(r)
-		if hasPrev {
-			x += fUnitsToFloat64(f.Kern(fixed.Int26_6(gc.Current.Scale), prev, index))
-		}
-		glyph := gc.glyphCache.Fetch(gc, fontName, r)
-		x += glyph.Stroke(gc, x, y)
-		prev, hasPrev = index, true
-	}
-	return x - startx
+	return draw2dbase.DrawString(gc, gc.glyphCache, f, gc.Current.Scale, text, x, y, draw2dbase.GlyphRendering{}, draw2dbase.StrokeGlyph)
 }
 
 // recalc recalculates scale and bounds values from the font size, screen
@@ -303,6 +326,13 @@ func (gc *GraphicContext) SetDPI(dpi int) {
 	gc.recalc()
 }
 
+// SetGlyphCache sets the cache of the glyphs drawn by FillString and
+// StrokeString. A draw2dbase.LRUGlyphCache can be shared by several graphic
+// contexts, by default each context has its own.
+func (gc *GraphicContext) SetGlyphCache(glyphCache draw2dbase.GlyphCache) {
+	gc.glyphCache = glyphCache
+}
+
 // SetFont sets the font used to draw text.
 func (gc *GraphicContext) SetFont(font *truetype.Font) {
 	gc.Current.Font = font
@@ -328,12 +358,67 @@ func (gc *GraphicContext) ClearRect(x1, y1, x2, y2 int) {
 	panic("not implemented")
 }
 
-//TODO
+// DrawImage draws the raster image as a texture, transformed by the
+// current transformation
 func (gc *GraphicContext) DrawImage(img image.Image) {
-	panic("not implemented")
+	gc.painter.Flush()
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return
+	}
+	// the colors of the texture are not premultiplied, as the ones painted
+	// by the painter
+	pixels := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(pixels, pixels.Rect, img, bounds.Min, draw.Src)
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(bounds.Dx()), int32(bounds.Dy()), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels.Pix))
+	gl.Enable(gl.TEXTURE_2D)
+	gl.Color4ub(0xff, 0xff, 0xff, 0xff)
+	gl.Begin(gl.QUADS)
+	for _, corner := range [4][2]int{{0, 0}, {1, 0}, {1, 1}, {0, 1}} {
+		x, y := gc.Current.Tr.TransformPoint(float64(bounds.Min.X+corner[0]*bounds.Dx()), float64(bounds.Min.Y+corner[1]*bounds.Dy()))
+		gl.TexCoord2i(int32(corner[0]), int32(corner[1]))
+		gl.Vertex2d(x, y)
+	}
+	gl.End()
+	gl.Disable(gl.TEXTURE_2D)
+	gl.DeleteTextures(1, &texture)
+}
+
+// DrawImageScaled draws the raster image at x, y, scaled and aligned as
+// specified by scaling
+func (gc *GraphicContext) DrawImageScaled(img image.Image, x, y float64, scaling draw2d.ImageScaling) {
+	draw2dbase.DrawImageScaled(gc, img, x, y, scaling)
+}
+
+// DrawImageRect draws the rectangle srcRect of the raster image scaled to
+// the rectangle dstRect of the user space
+func (gc *GraphicContext) DrawImageRect(img image.Image, srcRect image.Rectangle, dstRect [4]float64) {
+	draw2dbase.DrawImageRect(gc, img, srcRect, dstRect)
+}
+
+// PushGroup starts a group. Groups are not supported by the opengl
+// backend, the drawings of a group are painted one by one.
+func (gc *GraphicContext) PushGroup() {
 }
 
-func (gc *GraphicContext) paint(rasterizer *raster.Rasterizer, color color.Color) {
+// PopGroup ends the last group started by PushGroup. The opacity and the
+// blend mode are ignored, see PushGroup.
+func (gc *GraphicContext) PopGroup(opacity float64, blend draw2d.BlendMode) {
+}
+
+// NOTE: the masks set by SetMask, SetLuminanceMask and MaskWithPath are
+// saved and restored with the context, but the opengl backend does not
+// mask the drawings.
+
+func (gc *GraphicContext) paint(rasterizer rasterizer, color color.Color) {
 	gc.painter.SetColor(color)
 	rasterizer.Rasterize(gc.painter)
 	rasterizer.Clear()
@@ -343,9 +428,9 @@ func (gc *GraphicContext) paint(rasterizer *raster.Rasterizer, color color.Color
 
 func (gc *GraphicContext) Stroke(paths ...*draw2d.Path) {
 	paths = append(paths, gc.Current.Path)
-	gc.strokeRasterizer.UseNonZeroWinding = true
+	setNonZeroWinding(gc.strokeRasterizer, true)
 
-	stroker := draw2dbase.NewLineStroker(gc.Current.Cap, gc.Current.Join, draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: draw2dimg.FtLineBuilder{Adder: gc.strokeRasterizer}})
+	stroker := draw2dbase.NewLineStroker(gc.Current.Cap, gc.Current.Join, draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: lineBuilder(gc.strokeRasterizer)})
 	stroker.HalfLineWidth = gc.Current.LineWidth / 2
 
 	var liner draw2dbase.Flattener
@@ -363,10 +448,10 @@ func (gc *GraphicContext) Stroke(paths ...*draw2d.Path) {
 
 func (gc *GraphicContext) Fill(paths ...*draw2d.Path) {
 	paths = append(paths, gc.Current.Path)
-	gc.fillRasterizer.UseNonZeroWinding = useNonZeroWinding(gc.Current.FillRule)
+	setNonZeroWinding(gc.fillRasterizer, useNonZeroWinding(gc.Current.FillRule))
 
 	/**** first method ****/
-	flattener := draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: draw2dimg.FtLineBuilder{Adder: gc.fillRasterizer}}
+	flattener := draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: lineBuilder(gc.fillRasterizer)}
 	for _, p := range paths {
 		draw2dbase.Flatten(p, flattener, gc.Current.Tr.GetScale())
 	}
@@ -376,12 +461,12 @@ func (gc *GraphicContext) Fill(paths ...*draw2d.Path) {
 
 func (gc *GraphicContext) FillStroke(paths ...*draw2d.Path) {
 	paths = append(paths, gc.Current.Path)
-	gc.fillRasterizer.UseNonZeroWinding = useNonZeroWinding(gc.Current.FillRule)
-	gc.strokeRasterizer.UseNonZeroWinding = true
+	setNonZeroWinding(gc.fillRasterizer, useNonZeroWinding(gc.Current.FillRule))
+	setNonZeroWinding(gc.strokeRasterizer, true)
 
-	flattener := draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: draw2dimg.FtLineBuilder{Adder: gc.fillRasterizer}}
+	flattener := draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: lineBuilder(gc.fillRasterizer)}
 
-	stroker := draw2dbase.NewLineStroker(gc.Current.Cap, gc.Current.Join, draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: draw2dimg.FtLineBuilder{Adder: gc.strokeRasterizer}})
+	stroker := draw2dbase.NewLineStroker(gc.Current.Cap, gc.Current.Join, draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: lineBuilder(gc.strokeRasterizer)})
 	stroker.HalfLineWidth = gc.Current.LineWidth / 2
 
 	var liner draw2dbase.Flattener
@@ -411,3 +496,39 @@ func useNonZeroWinding(f draw2d.FillRule) bool {
 	}
 	return false
 }
+
+// rasterizer accumulates the lines of paths and paints their spans
+type rasterizer interface {
+	Rasterize(p raster.Painter)
+	Clear()
+}
+
+// SetRasterization sets the rasterizer of the fills and strokes, see
+// draw2dimg.Rasterization. The default is draw2dimg.FixedRasterization.
+func (gc *GraphicContext) SetRasterization(r draw2dimg.Rasterization) {
+	if r == draw2dimg.FloatRasterization {
+		gc.fillRasterizer = floatraster.NewRasterizer(gc.width, gc.height)
+		gc.strokeRasterizer = floatraster.NewRasterizer(gc.width, gc.height)
+	} else {
+		gc.fillRasterizer = raster.NewRasterizer(gc.width, gc.height)
+		gc.strokeRasterizer = raster.NewRasterizer(gc.width, gc.height)
+	}
+}
+
+// lineBuilder returns the flattener adding lines to r
+func lineBuilder(r rasterizer) draw2dbase.Flattener {
+	if flattener, ok := r.(draw2dbase.Flattener); ok {
+		return flattener
+	}
+	return draw2dimg.FtLineBuilder{Adder: r.(raster.Adder)}
+}
+
+// setNonZeroWinding sets the fill rule of r
+func setNonZeroWinding(r rasterizer, nonZero bool) {
+	switch r := r.(type) {
+	case *raster.Rasterizer:
+		r.UseNonZeroWinding = nonZero
+	case *floatraster.Rasterizer:
+		r.UseNonZeroWinding = nonZero
+	}
+}
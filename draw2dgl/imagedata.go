@@ -0,0 +1,73 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dgl
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// GetImageData reads back the pixels of the rectangle of width x height
+// pixels at x, y of the frame buffer. The rectangle is in device space, it
+// is not transformed by the current transformation. The copy has the bounds
+// (0, 0, width, height), its pixels outside of the frame buffer are
+// transparent.
+func (gc *GraphicContext) GetImageData(x, y, width, height int) *image.RGBA {
+	gc.painter.Flush()
+	data := image.NewRGBA(image.Rect(0, 0, max(width, 0), max(height, 0)))
+	r := image.Rect(x, y, x+width, y+height).Intersect(image.Rect(0, 0, gc.width, gc.height))
+	if r.Empty() {
+		return data
+	}
+	// the colors of the frame buffer are not premultiplied, and its rows go
+	// up from the bottom
+	pixels := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(int32(r.Min.X), int32(gc.height-r.Max.Y), int32(r.Dx()), int32(r.Dy()), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels.Pix))
+	flipRows(pixels)
+	draw.Draw(data, r.Sub(image.Pt(x, y)), pixels, image.Point{}, draw.Src)
+	return data
+}
+
+// PutImageData replaces the pixels of the frame buffer by the ones of img,
+// its top left pixel at x, y in device space. The pixels are not
+// transformed nor blended.
+func (gc *GraphicContext) PutImageData(img image.Image, x, y int) {
+	gc.painter.Flush()
+	bounds := img.Bounds()
+	r := bounds.Sub(bounds.Min).Add(image.Pt(x, y)).Intersect(image.Rect(0, 0, gc.width, gc.height))
+	if r.Empty() {
+		return
+	}
+	pixels := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(pixels, pixels.Rect, img, r.Min.Sub(image.Pt(x, y)).Add(bounds.Min), draw.Src)
+	flipRows(pixels)
+	blend := gl.IsEnabled(gl.BLEND)
+	gl.Disable(gl.BLEND)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.WindowPos2i(int32(r.Min.X), int32(gc.height-r.Max.Y))
+	gl.DrawPixels(int32(r.Dx()), int32(r.Dy()), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels.Pix))
+	if blend {
+		gl.Enable(gl.BLEND)
+	}
+}
+
+// Snapshot reads back a copy of the frame buffer
+func (gc *GraphicContext) Snapshot() *image.RGBA {
+	return gc.GetImageData(0, 0, gc.width, gc.height)
+}
+
+// flipRows reverses the order of the rows of img, from the order of opengl
+// to the one of image
+func flipRows(img *image.NRGBA) {
+	for top, bottom := 0, img.Rect.Dy()-1; top < bottom; top, bottom = top+1, bottom-1 {
+		a := img.Pix[top*img.Stride : top*img.Stride+img.Stride]
+		b := img.Pix[bottom*img.Stride : bottom*img.Stride+img.Stride]
+		for i := range a {
+			a[i], b[i] = b[i], a[i]
+		}
+	}
+}
@@ -3,58 +3,14 @@ package draw2dgl
 import (
 	"github.com/golang/freetype/truetype"
 	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
 
 	"golang.org/x/image/math/fixed"
 )
 
 // DrawContour draws the given closed contour at the given sub-pixel offset.
 func DrawContour(path draw2d.PathBuilder, ps []truetype.Point, dx, dy float64) {
-	if len(ps) == 0 {
-		return
-	}
-	startX, startY := pointToF64Point(ps[0])
-	var others []truetype.Point
-	if ps[0].Flags&0x01 != 0 {
-		others = ps[1:]
-	} else {
-		lastX, lastY := pointToF64Point(ps[len(ps)-1])
-		if ps[len(ps)-1].Flags&0x01 != 0 {
-			startX, startY = lastX, lastY
-			others = ps[:len(ps)-1]
-		} else {
-			startX = (startX + lastX) / 2
-			startY = (startY + lastY) / 2
-			others = ps
-		}
-	}
-	path.MoveTo(startX+dx, startY+dy)
-	q0X, q0Y, on0 := startX, startY, true
-	for _, p := range others {
-		qX, qY := pointToF64Point(p)
-		on := p.Flags&0x01 != 0
-		if on {
-			if on0 {
-				path.LineTo(qX+dx, qY+dy)
-			} else {
-				path.QuadCurveTo(q0X+dx, q0Y+dy, qX+dx, qY+dy)
-			}
-		} else {
-			if on0 {
-				// No-op.
-			} else {
-				midX := (q0X + qX) / 2
-				midY := (q0Y + qY) / 2
-				path.QuadCurveTo(q0X+dx, q0Y+dy, midX+dx, midY+dy)
-			}
-		}
-		q0X, q0Y, on0 = qX, qY, on
-	}
-	// Close the curve.
-	if on0 {
-		path.LineTo(startX+dx, startY+dy)
-	} else {
-		path.QuadCurveTo(q0X+dx, q0Y+dy, startX+dx, startY+dy)
-	}
+	draw2dbase.DrawContour(path, ps, dx, dy)
 }
 
 func pointToF64Point(p truetype.Point) (x, y float64) {
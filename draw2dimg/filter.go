@@ -0,0 +1,16 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"github.com/llgcode/draw2d/draw2dfilter"
+	"golang.org/x/image/draw"
+)
+
+// ApplyFilters applies the filters to the image, or to the layer of the
+// current group if any, which is then composited filtered by PopGroup
+func (gc *GraphicContext) ApplyFilters(filters ...draw2dfilter.Filter) {
+	filtered := draw2dfilter.Apply(gc.img, filters...)
+	draw.Draw(gc.img, filtered.Rect, filtered, filtered.Rect.Min, draw.Src)
+}
@@ -0,0 +1,43 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dfilter"
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+func TestGraphicContext_ApplyFilters(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 30, 30))
+	gc := NewGraphicContext(img)
+	gc.SetFillColor(color.RGBA{0xff, 0, 0, 0xff})
+	draw2dkit.Rectangle(gc, 0, 0, 30, 10)
+	gc.Fill()
+
+	// the filters of a group only apply to its layer
+	gc.PushGroup()
+	draw2dkit.Rectangle(gc, 10, 15, 20, 25)
+	gc.Fill()
+	gc.ApplyFilters(draw2dfilter.NewGaussianBlur(2))
+	gc.PopGroup(1, draw2d.BlendNormal)
+	if c := img.RGBAAt(15, 20); c.A == 0xff || c.A < 0x80 {
+		t.Errorf("got %v, want a blurred red at the center of the group", c)
+	}
+	if c := img.RGBAAt(15, 13); c.A == 0 {
+		t.Error("the blur should spread around the group")
+	}
+	if c := img.RGBAAt(15, 5); c != (color.RGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("got %v, the drawings below the group should not be blurred", c)
+	}
+
+	gc.ApplyFilters(draw2dfilter.Grayscale(1))
+	if c := img.RGBAAt(15, 5); c != (color.RGBA{0x36, 0x36, 0x36, 0xff}) {
+		t.Errorf("got %v, want gray", c)
+	}
+}
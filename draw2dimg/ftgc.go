@@ -32,13 +32,32 @@ type GraphicContext struct {
 	*draw2dbase.StackGraphicContext
 	img              draw.Image
 	painter          Painter
-	fillRasterizer   *raster.Rasterizer
-	strokeRasterizer *raster.Rasterizer
+	fillRasterizer   rasterizer
+	strokeRasterizer rasterizer
 	FontCache        draw2d.FontCache
 	glyphCache       draw2dbase.GlyphCache
 	glyphBuf         *truetype.GlyphBuf
 	DPI              int
 	Filter           ImageFilter
+	glyphRendering   draw2dbase.GlyphRendering
+	subpixelOrder    SubpixelOrder
+	// linearPainter composites in linear light, nil unless enabled by
+	// SetLinearBlending
+	linearPainter Painter
+	// rasterization and workers select the fill and stroke rasterizers, see
+	// SetRasterization and SetParallelRendering
+	rasterization Rasterization
+	workers       int
+	// groups are the destinations saved by PushGroup
+	groups []group
+	// mask is the last mask drawn with and maskCoverage its coverage
+	mask         *draw2dbase.Mask
+	maskCoverage *image.Alpha
+	// mipmaps reduce the images scaled down by DrawImage, see SetMipmapCache
+	mipmaps *MipmapCache
+	// lcd rasterizes the text drawn with subpixel antialiasing, nil until
+	// it is drawn
+	lcd *lcdRasterizer
 }
 
 // ImageFilter defines the type of filter to use
@@ -51,19 +70,18 @@ const (
 	BilinearFilter
 	// BicubicFilter defines a bicubic filter
 	BicubicFilter
+	// LanczosFilter defines a Lanczos filter of 3 lobes, sharper than the
+	// bicubic filter
+	LanczosFilter
+	// AreaFilter defines a filter averaging the pixels covered by each
+	// pixel of the destination, suited to scaling down
+	AreaFilter
 )
 
-// NewGraphicContext creates a new Graphic context from an image.
+// NewGraphicContext creates a new Graphic context from an image, painted by
+// the Painter returned by NewPainter.
 func NewGraphicContext(img draw.Image) *GraphicContext {
-
-	var painter Painter
-	switch selectImage := img.(type) {
-	case *image.RGBA:
-		painter = raster.NewRGBAPainter(selectImage)
-	default:
-		panic("Image type not supported")
-	}
-	return NewGraphicContextWithPainter(img, painter)
+	return NewGraphicContextWithPainter(img, NewPainter(img))
 }
 
 // NewGraphicContextWithPainter creates a new Graphic context from an image and a Painter (see Freetype-go)
@@ -77,10 +95,20 @@ func NewGraphicContextWithPainter(img draw.Image, painter Painter) *GraphicConte
 		raster.NewRasterizer(width, height),
 		raster.NewRasterizer(width, height),
 		draw2d.GetGlobalFontCache(),
-		draw2dbase.NewGlyphCache(),
+		draw2dbase.NewLRUGlyphCache(draw2dbase.DefaultGlyphCacheSize),
 		&truetype.GlyphBuf{},
 		dpi,
 		BilinearFilter,
+		draw2dbase.GlyphRendering{},
+		SubpixelNone,
+		nil,
+		FixedRasterization,
+		0,
+		nil,
+		nil,
+		nil,
+		NewMipmapCache(DefaultMipmapCacheSize),
+		nil,
 	}
 	return gc
 }
@@ -104,21 +132,82 @@ func (gc *GraphicContext) ClearRect(x1, y1, x2, y2 int) {
 
 // DrawImage draws an image into dest using an affine transformation matrix, an op and a filter
 func DrawImage(src image.Image, dest draw.Image, tr draw2d.Matrix, op draw.Op, filter ImageFilter) {
-	var transformer draw.Transformer
+	transformer(filter).Transform(dest, f64.Aff3{tr[0], tr[2], tr[4], tr[1], tr[3], tr[5]}, src, src.Bounds(), op, nil)
+}
+
+// transformer returns the transformer scaling the images with filter
+func transformer(filter ImageFilter) draw.Transformer {
 	switch filter {
 	case LinearFilter:
-		transformer = draw.NearestNeighbor
-	case BilinearFilter:
-		transformer = draw.BiLinear
+		return draw.NearestNeighbor
 	case BicubicFilter:
-		transformer = draw.CatmullRom
+		return draw.CatmullRom
+	case LanczosFilter:
+		return lanczos3
+	case AreaFilter:
+		return box
 	}
-	transformer.Transform(dest, f64.Aff3{tr[0], tr[1], tr[4], tr[2], tr[3], tr[5]}, src, src.Bounds(), op, nil)
+	return draw.BiLinear
 }
 
-// DrawImage draws the raster image in the current canvas
+// lanczos3 is the Lanczos kernel of 3 lobes
+var lanczos3 = &draw.Kernel{Support: 3, At: func(t float64) float64 {
+	if t == 0 {
+		return 1
+	}
+	if t >= 3 {
+		return 0
+	}
+	t *= math.Pi
+	return 3 * math.Sin(t) * math.Sin(t/3) / (t * t)
+}}
+
+// box is the box kernel, which averages the covered pixels as it is widened
+// by the scale when scaling down
+var box = &draw.Kernel{Support: 0.5, At: func(t float64) float64 {
+	if t >= 0.5 {
+		return 0
+	}
+	return 1
+}}
+
+// DrawImage draws the raster image in the current canvas. The images scaled
+// down by more than 2 are first reduced with the mipmap cache.
 func (gc *GraphicContext) DrawImage(img image.Image) {
-	DrawImage(img, gc.img, gc.Current.Tr, draw.Over, gc.Filter)
+	gc.drawImage(img, img.Bounds(), gc.Current.Tr)
+}
+
+// drawImage draws the rectangle sr of img transformed by tr
+func (gc *GraphicContext) drawImage(img image.Image, sr image.Rectangle, tr draw2d.Matrix) {
+	if gc.Current.Mask != nil {
+		x0, y0, x1, y1 := tr.TransformRectangle(float64(sr.Min.X), float64(sr.Min.Y), float64(sr.Max.X), float64(sr.Max.Y))
+		rect := gc.deviceRect(gc.img.Bounds(), x0, y0, x1, y1)
+		gc.drawMasked(rect, func() { gc.drawImage(img, sr, tr) })
+		return
+	}
+	if gc.mipmaps != nil {
+		img, sr, tr = gc.mipmaps.reduce(img, sr, tr)
+	}
+	transformer(gc.Filter).Transform(gc.img, f64.Aff3{tr[0], tr[2], tr[4], tr[1], tr[3], tr[5]}, img, sr, draw.Over, nil)
+}
+
+// DrawImageScaled draws the raster image at x, y, scaled and aligned as
+// specified by scaling
+func (gc *GraphicContext) DrawImageScaled(img image.Image, x, y float64, scaling draw2d.ImageScaling) {
+	draw2dbase.DrawImageScaled(gc, img, x, y, scaling)
+}
+
+// DrawImageRect draws the rectangle srcRect of the raster image scaled to
+// the rectangle dstRect of the user space. The parts of srcRect outside of
+// the image are not drawn.
+func (gc *GraphicContext) DrawImageRect(img image.Image, srcRect image.Rectangle, dstRect [4]float64) {
+	sr := srcRect.Intersect(img.Bounds())
+	if srcRect.Empty() || sr.Empty() {
+		return
+	}
+	tr := gc.Current.Tr.Copy()
+	tr.Compose(draw2d.NewMatrixFromRects([4]float64{float64(srcRect.Min.X), float64(srcRect.Min.Y), float64(srcRect.Max.X), float64(srcRect.Max.Y)}, dstRect))
+	gc.drawImage(img, sr, tr)
 }
 
 // FillString draws the text at point (0, 0)
@@ -128,24 +217,22 @@ func (gc *GraphicContext) FillString(text string) (width float64) {
 
 // FillStringAt draws the text at the specified point (x, y)
 func (gc *GraphicContext) FillStringAt(text string, x, y float64) (width float64) {
+	if gc.Current.HasShadow() {
+		gc.drawShadowed(gc.stringRect(text, x, y, 0), func() { width = gc.FillStringAt(text, x, y) })
+		return width
+	}
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.DrawPathFontString(gc, font, gc.pathFontScale(font), text, x, y, draw2dbase.FillGlyph)
+	}
 	f, err := gc.loadCurrentFont()
 	if err != nil {
 		log.Println(err)
 		return 0.0
 	}
-	startx := x
-	prev, hasPrev := truetype.Index(0), false
-	fontName := gc.GetFontName()
-	for _, r := range text {
-		index := f.Index(r)
-		if hasPrev {
-			x += fUnitsToFloat64(f.Kern(fixed.Int26_6(gc.Current.Scale), prev, index))
-		}
-		glyph := gc.glyphCache.Fetch(gc, fontName, r)
-		x += glyph.Fill(gc, x, y)
-		prev, hasPrev = index, true
+	if gc.subpixelOrder != SubpixelNone {
+		return gc.drawStringLCD(f, text, x, y, gc.Current.FillColor, false)
 	}
-	return x - startx
+	return draw2dbase.DrawString(gc, gc.glyphCache, f, gc.Current.Scale, text, x, y, gc.glyphRendering, draw2dbase.FillGlyph)
 }
 
 // StrokeString draws the contour of the text at point (0, 0)
@@ -155,24 +242,52 @@ func (gc *GraphicContext) StrokeString(text string) (width float64) {
 
 // StrokeStringAt draws the contour of the text at point (x, y)
 func (gc *GraphicContext) StrokeStringAt(text string, x, y float64) (width float64) {
+	if gc.Current.HasShadow() {
+		gc.drawShadowed(gc.stringRect(text, x, y, gc.strokeMargin()), func() { width = gc.StrokeStringAt(text, x, y) })
+		return width
+	}
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.DrawPathFontString(gc, font, gc.pathFontScale(font), text, x, y, draw2dbase.StrokeGlyph)
+	}
 	f, err := gc.loadCurrentFont()
 	if err != nil {
 		log.Println(err)
 		return 0.0
 	}
-	startx := x
-	prev, hasPrev := truetype.Index(0), false
-	fontName := gc.GetFontName()
-	for _, r := range text {
-		index := f.Index(r)
-		if hasPrev {
-			x += fUnitsToFloat64(f.Kern(fixed.Int26_6(gc.Current.Scale), prev, index))
-		}
-		glyph := gc.glyphCache.Fetch(gc, fontName, r)
-		x += glyph.Stroke(gc, x, y)
-		prev, hasPrev = index, true
+	if gc.subpixelOrder != SubpixelNone {
+		return gc.drawStringLCD(f, text, x, y, gc.Current.StrokeColor, true)
 	}
-	return x - startx
+	return draw2dbase.DrawString(gc, gc.glyphCache, f, gc.Current.Scale, text, x, y, gc.glyphRendering, draw2dbase.StrokeGlyph)
+}
+
+// FillStringOnPath draws the text along path, starting at offset from the
+// beginning of the path, each glyph rotated to follow the path tangent
+func (gc *GraphicContext) FillStringOnPath(text string, path *draw2d.Path, offset float64) (width float64) {
+	if gc.Current.HasShadow() {
+		gc.drawShadowed(gc.stringOnPathRect(text, path, 0), func() { width = gc.FillStringOnPath(text, path, offset) })
+		return width
+	}
+	f, err := gc.loadCurrentFont()
+	if err != nil {
+		log.Println(err)
+		return 0.0
+	}
+	return draw2dbase.DrawStringOnPath(gc, gc.glyphCache, f, gc.Current.Scale, text, path, offset, draw2dbase.FillGlyph)
+}
+
+// StrokeStringOnPath draws the contour of the text along path, starting at
+// offset from the beginning of the path
+func (gc *GraphicContext) StrokeStringOnPath(text string, path *draw2d.Path, offset float64) (width float64) {
+	if gc.Current.HasShadow() {
+		gc.drawShadowed(gc.stringOnPathRect(text, path, gc.strokeMargin()), func() { width = gc.StrokeStringOnPath(text, path, offset) })
+		return width
+	}
+	f, err := gc.loadCurrentFont()
+	if err != nil {
+		log.Println(err)
+		return 0.0
+	}
+	return draw2dbase.DrawStringOnPath(gc, gc.glyphCache, f, gc.Current.Scale, text, path, offset, draw2dbase.StrokeGlyph)
 }
 
 func (gc *GraphicContext) loadCurrentFont() (*truetype.Font, error) {
@@ -187,50 +302,48 @@ func (gc *GraphicContext) loadCurrentFont() (*truetype.Font, error) {
 	return font, err
 }
 
+// loadPathFont returns the bitmap or stroke font selected by the current font
+// data, which is used instead of a TrueType font
+func (gc *GraphicContext) loadPathFont() (draw2d.PathFont, bool) {
+	return draw2dbase.LoadPathFont(gc.FontCache, gc.Current.FontData)
+}
+
+// pathFontScale returns the size in pixels of the font units of font drawn
+// with the current font size
+func (gc *GraphicContext) pathFontScale(font draw2d.PathFont) float64 {
+	return draw2dbase.PathFontScale(font, gc.Current.FontSize*float64(gc.DPI)/72)
+}
+
 // p is a truetype.Point measured in FUnits and positive Y going upwards.
 // The returned value is the same thing measured in floating point and positive Y
 // going downwards.
 
-func (gc *GraphicContext) drawGlyph(glyph truetype.Index, dx, dy float64) error {
-	if err := gc.glyphBuf.Load(gc.Current.Font, fixed.Int26_6(gc.Current.Scale), glyph, font.HintingNone); err != nil {
-		return err
-	}
-	e0 := 0
-	for _, e1 := range gc.glyphBuf.Ends {
-		DrawContour(gc, gc.glyphBuf.Points[e0:e1], dx, dy)
-		e0 = e1
-	}
-	return nil
-}
-
 // CreateStringPath creates a path from the string s at x, y, and returns the string width.
 // The text is placed so that the left edge of the em square of the first character of s
 // and the baseline intersect at x, y. The majority of the affected pixels will be
 // above and to the right of the point, but some may be below or to the left.
 // For example, drawing a string that starts with a 'J' in an italic font may
-// affect pixels below and left of the point.
+// affect pixels below and left of the point. In vertical writing mode x, y is
+// the top of the column on its center line and the height of the column is
+// returned.
 func (gc *GraphicContext) CreateStringPath(s string, x, y float64) float64 {
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.AddPathFontString(gc, font, gc.pathFontScale(font), s, x, y)
+	}
 	f, err := gc.loadCurrentFont()
 	if err != nil {
 		log.Println(err)
 		return 0.0
 	}
-	startx := x
-	prev, hasPrev := truetype.Index(0), false
-	for _, rune := range s {
-		index := f.Index(rune)
-		if hasPrev {
-			x += fUnitsToFloat64(f.Kern(fixed.Int26_6(gc.Current.Scale), prev, index))
-		}
-		err := gc.drawGlyph(index, x, y)
+	glyphs, width := draw2dbase.ShapeString(f, gc.Current.Scale, gc.Current.WritingMode, s)
+	for _, g := range glyphs {
+		err := draw2dbase.AddGlyph(gc, f, gc.Current.Scale, g, x, y)
 		if err != nil {
 			log.Println(err)
-			return startx - x
+			return -g.X
 		}
-		x += fUnitsToFloat64(f.HMetric(fixed.Int26_6(gc.Current.Scale), index).AdvanceWidth)
-		prev, hasPrev = index, true
 	}
-	return x - startx
+	return width
 }
 
 // GetStringBounds returns the approximate pixel bounds of the string s at x, y.
@@ -238,20 +351,19 @@ func (gc *GraphicContext) CreateStringPath(s string, x, y float64) float64 {
 // and the baseline intersect at 0, 0 in the returned coordinates.
 // Therefore the top and left coordinates may well be negative.
 func (gc *GraphicContext) GetStringBounds(s string) (left, top, right, bottom float64) {
+	if font, ok := gc.loadPathFont(); ok {
+		m := draw2dbase.MeasurePathFont(font, gc.pathFontScale(font), gc.Current.TextDecoration, s)
+		return m.Left, m.Top, m.Right, m.Bottom
+	}
 	f, err := gc.loadCurrentFont()
 	if err != nil {
 		log.Println(err)
 		return 0, 0, 0, 0
 	}
 	top, left, bottom, right = 10e6, 10e6, -10e6, -10e6
-	cursor := 0.0
-	prev, hasPrev := truetype.Index(0), false
-	for _, rune := range s {
-		index := f.Index(rune)
-		if hasPrev {
-			cursor += fUnitsToFloat64(f.Kern(fixed.Int26_6(gc.Current.Scale), prev, index))
-		}
-		if err := gc.glyphBuf.Load(gc.Current.Font, fixed.Int26_6(gc.Current.Scale), index, font.HintingNone); err != nil {
+	glyphs, width := draw2dbase.ShapeString(f, gc.Current.Scale, gc.Current.WritingMode, s)
+	for _, g := range glyphs {
+		if err := gc.glyphBuf.Load(gc.Current.Font, fixed.Int26_6(gc.Current.Scale), g.Index, font.HintingNone); err != nil {
 			log.Println(err)
 			return 0, 0, 0, 0
 		}
@@ -259,19 +371,37 @@ func (gc *GraphicContext) GetStringBounds(s string) (left, top, right, bottom fl
 		for _, e1 := range gc.glyphBuf.Ends {
 			ps := gc.glyphBuf.Points[e0:e1]
 			for _, p := range ps {
-				x, y := pointToF64Point(p)
+				x, y := draw2dbase.GlyphPoint(p, g)
 				top = math.Min(top, y)
 				bottom = math.Max(bottom, y)
-				left = math.Min(left, x+cursor)
-				right = math.Max(right, x+cursor)
+				left = math.Min(left, x)
+				right = math.Max(right, x)
 			}
 		}
-		cursor += fUnitsToFloat64(f.HMetric(fixed.Int26_6(gc.Current.Scale), index).AdvanceWidth)
-		prev, hasPrev = index, true
+	}
+	if gc.Current.TextDecoration != draw2d.TextDecorationNone {
+		m := draw2dbase.GetFontMetrics(f, gc.Current.Scale)
+		if l, t, r, b, ok := draw2dbase.DecorationBounds(m, gc.Current.TextDecoration, gc.Current.WritingMode, width); ok {
+			top, left, bottom, right = math.Min(top, t), math.Min(left, l), math.Max(bottom, b), math.Max(right, r)
+		}
 	}
 	return left, top, right, bottom
 }
 
+// MeasureString returns the advance, ink bounds, font metrics and glyph
+// positions of the text drawn with the current font
+func (gc *GraphicContext) MeasureString(text string) draw2d.TextMetrics {
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.MeasurePathFont(font, gc.pathFontScale(font), gc.Current.TextDecoration, text)
+	}
+	f, err := gc.loadCurrentFont()
+	if err != nil {
+		log.Println(err)
+		return draw2d.TextMetrics{}
+	}
+	return draw2dbase.MeasureString(f, gc.Current.Scale, gc.Current.WritingMode, gc.Current.TextDecoration, text)
+}
+
 // recalc recalculates scale and bounds values from the font size, screen
 // resolution and font metrics, and invalidates the glyph cache.
 func (gc *GraphicContext) recalc() {
@@ -289,6 +419,41 @@ func (gc *GraphicContext) SetDPI(dpi int) {
 	gc.recalc()
 }
 
+// SetHinting sets the hinting of the glyphs drawn by FillString and
+// StrokeString, font.HintingVertical or font.HintingFull fit their outlines
+// to the pixel grid for crisp small text. Hinted glyphs are drawn at whole
+// pixel positions, unless subpixel positioning is set. Hinting only applies
+// when the current transformation is a translation. The default is
+// font.HintingNone.
+func (gc *GraphicContext) SetHinting(hinting font.Hinting) {
+	gc.glyphRendering.Hinting = hinting
+}
+
+// SetSubpixelPositioning sets the number of horizontal positions within a
+// pixel the glyphs drawn by FillString and StrokeString can take, each one
+// cached as a variant of the glyph. 1 draws glyphs at whole pixel positions,
+// 0, the default, draws them at their exact position unless they are
+// hinted. Like hinting, it only applies when the current transformation is
+// a translation.
+func (gc *GraphicContext) SetSubpixelPositioning(subpixels int) {
+	gc.glyphRendering.Subpixels = subpixels
+}
+
+// SetGlyphCache sets the cache of the glyphs drawn by FillString and
+// StrokeString. A draw2dbase.LRUGlyphCache can be shared by several graphic
+// contexts, by default each context has its own.
+func (gc *GraphicContext) SetGlyphCache(glyphCache draw2dbase.GlyphCache) {
+	gc.glyphCache = glyphCache
+}
+
+// SetMipmapCache sets the cache of the mipmaps with which DrawImage reduces
+// the images scaled down by more than 2, nil not to reduce them. A
+// MipmapCache can be shared by several graphic contexts, by default each
+// context has its own.
+func (gc *GraphicContext) SetMipmapCache(mipmaps *MipmapCache) {
+	gc.mipmaps = mipmaps
+}
+
 // SetFont sets the font used to draw text.
 func (gc *GraphicContext) SetFont(font *truetype.Font) {
 	gc.Current.Font = font
@@ -300,19 +465,34 @@ func (gc *GraphicContext) SetFontSize(fontSize float64) {
 	gc.recalc()
 }
 
-func (gc *GraphicContext) paint(rasterizer *raster.Rasterizer, color color.Color) {
-	gc.painter.SetColor(color)
-	rasterizer.Rasterize(gc.painter)
+func (gc *GraphicContext) paint(rasterizer rasterizer, color color.Color) {
+	painter := gc.painter
+	if gc.linearPainter != nil {
+		painter = gc.linearPainter
+	}
+	painter.SetColor(color)
+	if mask := gc.currentMask(); mask != nil {
+		painter = maskedPainter{painter, mask}
+	}
+	if gc.Current.Antialias {
+		rasterizer.Rasterize(painter)
+	} else {
+		rasterizer.Rasterize(aliasedPainter{painter})
+	}
 	rasterizer.Clear()
 	gc.Current.Path.Clear()
 }
 
 // Stroke strokes the paths with the color specified by SetStrokeColor
 func (gc *GraphicContext) Stroke(paths ...*draw2d.Path) {
+	if gc.Current.HasShadow() {
+		gc.drawShadowed(gc.pathsRect(paths, gc.strokeMargin()), func() { gc.Stroke(paths...) })
+		return
+	}
 	paths = append(paths, gc.Current.Path)
-	gc.strokeRasterizer.UseNonZeroWinding = true
+	setNonZeroWinding(gc.strokeRasterizer, true)
 
-	stroker := draw2dbase.NewLineStroker(gc.Current.Cap, gc.Current.Join, draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: FtLineBuilder{Adder: gc.strokeRasterizer}})
+	stroker := draw2dbase.NewLineStroker(gc.Current.Cap, gc.Current.Join, draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: lineBuilder(gc.strokeRasterizer)})
 	stroker.HalfLineWidth = gc.Current.LineWidth / 2
 
 	var liner draw2dbase.Flattener
@@ -322,6 +502,9 @@ func (gc *GraphicContext) Stroke(paths ...*draw2d.Path) {
 		liner = stroker
 	}
 	for _, p := range paths {
+		if gc.Current.CrispEdges {
+			p = draw2dbase.SnapStroke(p, gc.Current.Tr, gc.Current.LineWidth)
+		}
 		draw2dbase.Flatten(p, liner, gc.Current.Tr.GetScale())
 	}
 
@@ -330,12 +513,19 @@ func (gc *GraphicContext) Stroke(paths ...*draw2d.Path) {
 
 // Fill fills the paths with the color specified by SetFillColor
 func (gc *GraphicContext) Fill(paths ...*draw2d.Path) {
+	if gc.Current.HasShadow() {
+		gc.drawShadowed(gc.pathsRect(paths, 0), func() { gc.Fill(paths...) })
+		return
+	}
 	paths = append(paths, gc.Current.Path)
-	gc.fillRasterizer.UseNonZeroWinding = gc.Current.FillRule == draw2d.FillRuleWinding
+	setNonZeroWinding(gc.fillRasterizer, gc.Current.FillRule == draw2d.FillRuleWinding)
 
 	/**** first method ****/
-	flattener := draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: FtLineBuilder{Adder: gc.fillRasterizer}}
+	flattener := draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: lineBuilder(gc.fillRasterizer)}
 	for _, p := range paths {
+		if gc.Current.CrispEdges {
+			p = draw2dbase.SnapFill(p, gc.Current.Tr)
+		}
 		draw2dbase.Flatten(p, flattener, gc.Current.Tr.GetScale())
 	}
 
@@ -344,13 +534,17 @@ func (gc *GraphicContext) Fill(paths ...*draw2d.Path) {
 
 // FillStroke first fills the paths and than strokes them
 func (gc *GraphicContext) FillStroke(paths ...*draw2d.Path) {
+	if gc.Current.HasShadow() {
+		gc.drawShadowed(gc.pathsRect(paths, gc.strokeMargin()), func() { gc.FillStroke(paths...) })
+		return
+	}
 	paths = append(paths, gc.Current.Path)
-	gc.fillRasterizer.UseNonZeroWinding = gc.Current.FillRule == draw2d.FillRuleWinding
-	gc.strokeRasterizer.UseNonZeroWinding = true
+	setNonZeroWinding(gc.fillRasterizer, gc.Current.FillRule == draw2d.FillRuleWinding)
+	setNonZeroWinding(gc.strokeRasterizer, true)
 
-	flattener := draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: FtLineBuilder{Adder: gc.fillRasterizer}}
+	flattener := draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: lineBuilder(gc.fillRasterizer)}
 
-	stroker := draw2dbase.NewLineStroker(gc.Current.Cap, gc.Current.Join, draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: FtLineBuilder{Adder: gc.strokeRasterizer}})
+	stroker := draw2dbase.NewLineStroker(gc.Current.Cap, gc.Current.Join, draw2dbase.Transformer{Tr: gc.Current.Tr, Flattener: lineBuilder(gc.strokeRasterizer)})
 	stroker.HalfLineWidth = gc.Current.LineWidth / 2
 
 	var liner draw2dbase.Flattener
@@ -360,9 +554,17 @@ func (gc *GraphicContext) FillStroke(paths ...*draw2d.Path) {
 		liner = stroker
 	}
 
-	demux := draw2dbase.DemuxFlattener{Flatteners: []draw2dbase.Flattener{flattener, liner}}
-	for _, p := range paths {
-		draw2dbase.Flatten(p, demux, gc.Current.Tr.GetScale())
+	if gc.Current.CrispEdges {
+		// the fill and the stroke are not snapped to the same pixel coordinates
+		for _, p := range paths {
+			draw2dbase.Flatten(draw2dbase.SnapFill(p, gc.Current.Tr), flattener, gc.Current.Tr.GetScale())
+			draw2dbase.Flatten(draw2dbase.SnapStroke(p, gc.Current.Tr, gc.Current.LineWidth), liner, gc.Current.Tr.GetScale())
+		}
+	} else {
+		demux := draw2dbase.DemuxFlattener{Flatteners: []draw2dbase.Flattener{flattener, liner}}
+		for _, p := range paths {
+			draw2dbase.Flatten(p, demux, gc.Current.Tr.GetScale())
+		}
 	}
 
 	// Fill
@@ -392,3 +594,15 @@ func toFtJoin(j draw2d.LineJoin) raster.Joiner {
 	}
 	return raster.RoundJoiner
 }
+
+// pathsBounds returns the bounds in device space of paths drawn with tr,
+// widened by margin for their stroke, ok being false if there is no point
+func pathsBounds(paths []*draw2d.Path, tr draw2d.Matrix, margin float64) (x0, y0, x1, y1 float64, ok bool) {
+	x0, y0, x1, y1, ok = draw2dbase.PathsBounds(paths)
+	if !ok {
+		return
+	}
+	x0, y0, x1, y1 = x0-margin, y0-margin, x1+margin, y1+margin
+	x0, y0, x1, y1 = tr.TransformRectangle(x0, y0, x1, y1)
+	return x0, y0, x1, y1, true
+}
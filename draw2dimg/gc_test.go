@@ -9,6 +9,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/llgcode/draw2d"
 	"github.com/llgcode/draw2d/draw2dkit"
 )
 
@@ -20,15 +21,18 @@ func TestNewGraphicContext_RGBA(t *testing.T) {
 	}
 }
 
-func TestNewGraphicContext_UnsupportedImageType(t *testing.T) {
-	// Test related to issue #143: Unsupported image types should panic
-	defer func() {
-		if r := recover(); r == nil {
-			t.Error("NewGraphicContext should panic for unsupported image type")
-		}
-	}()
+func TestNewGraphicContext_EmptyPalette(t *testing.T) {
+	// Test related to issue #143: paletted images are supported, drawing
+	// on an image without palette does nothing
 	img := image.NewPaletted(image.Rect(0, 0, 100, 100), nil)
-	NewGraphicContext(img)
+	gc := NewGraphicContext(img)
+	draw2dkit.Rectangle(gc, 10, 10, 90, 90)
+	gc.Fill()
+	for _, index := range img.Pix {
+		if index != 0 {
+			t.Fatalf("unexpected palette index %d", index)
+		}
+	}
 }
 
 func TestGraphicContext_Clear(t *testing.T) {
@@ -231,3 +235,70 @@ func TestGraphicContext_Circle_Fill(t *testing.T) {
 		t.Errorf("Circle Fill should color center pixel, got RGB(%d, %d, %d)", r>>8, g>>8, b>>8)
 	}
 }
+
+func TestGraphicContext_CrispEdges(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	gc := NewGraphicContext(img)
+	gc.SetCrispEdges(true)
+	gc.SetLineCap(draw2d.ButtCap)
+	gc.SetStrokeColor(color.Black)
+	gc.Translate(0.3, 0.2)
+	// a horizontal hairline between two rows of pixels and a vertical line
+	// of 2 pixels across three columns
+	gc.MoveTo(10, 10)
+	gc.LineTo(30, 10)
+	gc.Stroke()
+	gc.SetLineWidth(2)
+	gc.MoveTo(5.1, 12)
+	gc.LineTo(5.1, 30)
+	gc.Stroke()
+	gc.SetFillColor(color.Black)
+	draw2dkit.Rectangle(gc, 20.1, 20.1, 30.6, 30.6)
+	gc.Fill()
+
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			var want uint8
+			switch {
+			case y == 10 && (x == 10 || x == 30), x >= 4 && x < 6 && (y == 12 || y == 30):
+				// the ends of the lines are not snapped
+				continue
+			case y == 10 && x >= 11 && x < 30,
+				x >= 4 && x < 6 && y >= 13 && y < 30,
+				x >= 20 && x < 31 && y >= 20 && y < 31:
+				want = 0xff
+			}
+			if a := img.RGBAAt(x, y).A; a != want {
+				t.Fatalf("pixel (%d, %d) has alpha %#x, want %#x", x, y, a, want)
+			}
+		}
+	}
+}
+
+func TestGraphicContext_SetAntialias(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	gc := NewGraphicContext(img)
+	gc.SetAntialias(false)
+	gc.SetFillColor(color.Black)
+	gc.SetStrokeColor(color.Black)
+	draw2dkit.Circle(gc, 20, 20, 12.3)
+	gc.Fill()
+	gc.MoveTo(2, 3)
+	gc.LineTo(37, 30)
+	gc.Stroke()
+
+	covered := 0
+	for i := 3; i < len(img.Pix); i += 4 {
+		switch img.Pix[i] {
+		case 0:
+		case 0xff:
+			covered++
+		default:
+			t.Fatalf("unexpected partial coverage %#x", img.Pix[i])
+		}
+	}
+	// the area of the disc is about 475 pixels
+	if covered < 450 || covered > 600 {
+		t.Errorf("unexpected number of covered pixels %d", covered)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/draw"
+)
+
+// group is the destination of the drawings saved by PushGroup
+type group struct {
+	img                    draw.Image
+	painter, linearPainter Painter
+}
+
+// PushGroup starts a group: the following drawings are painted on a
+// transparent layer of the size of the image, composited on the image by
+// PopGroup
+func (gc *GraphicContext) PushGroup() {
+	gc.pushLayer(gc.img.Bounds())
+}
+
+// pushLayer starts a group of which the layer has the bounds rect, the
+// drawings outside of it being clipped
+func (gc *GraphicContext) pushLayer(rect image.Rectangle) {
+	gc.groups = append(gc.groups, group{gc.img, gc.painter, gc.linearPainter})
+	layer := image.NewRGBA(rect)
+	gc.img = layer
+	gc.painter = NewPainter(layer)
+	if gc.linearPainter != nil {
+		gc.linearPainter = NewLinearPainter(layer)
+	}
+}
+
+// PopGroup ends the last group started by PushGroup and composites its
+// layer with opacity and blend, in linear light if enabled by
+// SetLinearBlending. It does nothing if there is no group.
+func (gc *GraphicContext) PopGroup(opacity float64, blend draw2d.BlendMode) {
+	if len(gc.groups) == 0 {
+		return
+	}
+	layer := gc.img.(*image.RGBA)
+	gc.popGroup()
+	compositeLayer(gc.img, layer, opacity, blend, gc.linearPainter != nil)
+}
+
+// popGroup restores the destination saved by the last PushGroup
+func (gc *GraphicContext) popGroup() {
+	g := gc.groups[len(gc.groups)-1]
+	gc.groups = gc.groups[:len(gc.groups)-1]
+	gc.img, gc.painter, gc.linearPainter = g.img, g.painter, g.linearPainter
+}
+
+// compositeLayer composites layer on dst with opacity and blend
+func compositeLayer(dst draw.Image, layer *image.RGBA, opacity float64, blend draw2d.BlendMode, linear bool) {
+	opacity = math.Max(0, math.Min(opacity, 1))
+	if blend == draw2d.BlendNormal && !linear {
+		mask := image.NewUniform(color.Alpha16{A: uint16(opacity*0xffff + 0.5)})
+		draw.DrawMask(dst, layer.Rect, layer, layer.Rect.Min, mask, image.Point{}, draw.Over)
+		return
+	}
+	bounds := layer.Rect.Intersect(dst.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			s := layer.RGBA64At(x, y)
+			if s.A == 0 {
+				continue
+			}
+			d := color.RGBA64Model.Convert(dst.At(x, y)).(color.RGBA64)
+			if linear {
+				s, d = toLinear(s), toLinear(d)
+			}
+			c := blendColors(d, s, opacity, blend)
+			if linear {
+				c = fromLinear(c)
+			}
+			dst.Set(x, y, c)
+		}
+	}
+}
+
+// blendColors composites the premultiplied color s with opacity over the
+// premultiplied color d, mixing them with blend
+func blendColors(d, s color.RGBA64, opacity float64, blend draw2d.BlendMode) color.RGBA64 {
+	sa, da := float64(s.A)/0xffff*opacity, float64(d.A)/0xffff
+	component := func(dc, sc uint16) uint16 {
+		// the non premultiplied components of the colors
+		cb, cs := 0.0, float64(sc)/float64(s.A)
+		if d.A != 0 {
+			cb = float64(dc) / float64(d.A)
+		}
+		c := sa*(1-da)*cs + sa*da*blendComponent(cb, cs, blend) + (1-sa)*da*cb
+		return uint16(math.Max(0, math.Min(c, 1))*0xffff + 0.5)
+	}
+	a := sa + da - sa*da
+	return color.RGBA64{component(d.R, s.R), component(d.G, s.G), component(d.B, s.B), uint16(a*0xffff + 0.5)}
+}
+
+// blendComponent mixes the component cb of the backdrop and cs of the
+// source, from 0 to 1, with blend
+func blendComponent(cb, cs float64, blend draw2d.BlendMode) float64 {
+	switch blend {
+	case draw2d.BlendMultiply:
+		return cb * cs
+	case draw2d.BlendScreen:
+		return cb + cs - cb*cs
+	case draw2d.BlendOverlay:
+		return blendComponent(cs, cb, draw2d.BlendHardLight)
+	case draw2d.BlendDarken:
+		return math.Min(cb, cs)
+	case draw2d.BlendLighten:
+		return math.Max(cb, cs)
+	case draw2d.BlendColorDodge:
+		switch {
+		case cb == 0:
+			return 0
+		case cs >= 1:
+			return 1
+		}
+		return math.Min(1, cb/(1-cs))
+	case draw2d.BlendColorBurn:
+		switch {
+		case cb >= 1:
+			return 1
+		case cs == 0:
+			return 0
+		}
+		return 1 - math.Min(1, (1-cb)/cs)
+	case draw2d.BlendHardLight:
+		if cs <= 0.5 {
+			return cb * 2 * cs
+		}
+		return blendComponent(cb, 2*cs-1, draw2d.BlendScreen)
+	case draw2d.BlendSoftLight:
+		if cs <= 0.5 {
+			return cb - (1-2*cs)*cb*(1-cb)
+		}
+		d := math.Sqrt(cb)
+		if cb <= 0.25 {
+			d = ((16*cb-12)*cb + 4) * cb
+		}
+		return cb + (2*cs-1)*(d-cb)
+	case draw2d.BlendDifference:
+		return math.Abs(cb - cs)
+	case draw2d.BlendExclusion:
+		return cb + cs - 2*cb*cs
+	}
+	return cs
+}
@@ -0,0 +1,74 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+func TestGraphicContext_PushGroup(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 30, 10))
+	gc := NewGraphicContext(img)
+	gc.SetFillColor(color.White)
+	gc.Clear()
+	gc.PushGroup()
+	gc.SetFillColor(color.NRGBA{0, 0, 0xff, 0xff})
+	draw2dkit.Rectangle(gc, 0, 0, 20, 10)
+	gc.Fill()
+	draw2dkit.Rectangle(gc, 10, 0, 30, 10)
+	gc.Fill()
+	gc.PopGroup(0.5, draw2d.BlendNormal)
+
+	// the overlap is not darker than the rest of the group
+	want := img.RGBAAt(5, 5)
+	if want != (color.RGBA{0x80, 0x80, 0xff, 0xff}) && want != (color.RGBA{0x7f, 0x7f, 0xff, 0xff}) {
+		t.Fatalf("unexpected color %v of the group at half opacity", want)
+	}
+	for _, x := range []int{15, 25} {
+		if got := img.RGBAAt(x, 5); got != want {
+			t.Errorf("pixel (%d, 5): got %v, want %v", x, got, want)
+		}
+	}
+
+	// unbalanced pops are ignored
+	gc.PopGroup(0, draw2d.BlendNormal)
+	if got := img.RGBAAt(5, 5); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGraphicContext_PopGroup_Blend(t *testing.T) {
+	for _, test := range []struct {
+		blend draw2d.BlendMode
+		want  color.RGBA
+	}{
+		{draw2d.BlendNormal, color.RGBA{0x80, 0xff, 0, 0xff}},
+		{draw2d.BlendMultiply, color.RGBA{0x64, 0x64, 0, 0xff}},
+		{draw2d.BlendScreen, color.RGBA{0xe4, 0xff, 0x32, 0xff}},
+		{draw2d.BlendDarken, color.RGBA{0x80, 0x64, 0, 0xff}},
+		{draw2d.BlendLighten, color.RGBA{0xc8, 0xff, 0x32, 0xff}},
+		{draw2d.BlendDifference, color.RGBA{0x48, 0x9b, 0x32, 0xff}},
+	} {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		gc := NewGraphicContext(img)
+		gc.SetFillColor(color.RGBA{200, 100, 50, 0xff})
+		gc.Clear()
+		gc.PushGroup()
+		gc.SetFillColor(color.RGBA{0x80, 0xff, 0, 0xff})
+		draw2dkit.Rectangle(gc, 0, 0, 2, 4)
+		gc.Fill()
+		gc.PopGroup(1, test.blend)
+		if got := img.RGBAAt(1, 1); got != test.want {
+			t.Errorf("%v: got %v, want %v", test.blend, got, test.want)
+		}
+		if got := img.RGBAAt(3, 1); got != (color.RGBA{200, 100, 50, 0xff}) {
+			t.Errorf("%v: the backdrop outside of the group should be unchanged, got %v", test.blend, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+)
+
+// quadrants returns an image of 20 x 20 pixels of which the quadrants are
+// red, green, blue and white
+func quadrants() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	colors := []color.RGBA{{0xff, 0, 0, 0xff}, {0, 0xff, 0, 0xff}, {0, 0, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff}}
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			img.SetRGBA(x, y, colors[x/10+2*(y/10)])
+		}
+	}
+	return img
+}
+
+func TestGraphicContext_DrawImageRotated(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	gc := NewGraphicContext(img)
+	gc.SetFilter(LinearFilter)
+	// a quarter turn around the center of the image
+	gc.Translate(20, 20)
+	gc.Rotate(math.Pi / 2)
+	gc.Translate(-10, -10)
+	gc.DrawImage(quadrants())
+	// the red top left quadrant turns to the top right
+	if got := img.RGBAAt(25, 15); got != (color.RGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("got %v, want the red quadrant turned clockwise", got)
+	}
+	if got := img.RGBAAt(15, 15); got != (color.RGBA{0, 0, 0xff, 0xff}) {
+		t.Errorf("got %v, want the blue quadrant turned clockwise", got)
+	}
+}
+
+func TestGraphicContext_DrawImageRect(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	gc := NewGraphicContext(img)
+	gc.SetFilter(LinearFilter)
+	gc.Translate(5, 0)
+	// the green quadrant, stretched
+	gc.DrawImageRect(quadrants(), image.Rect(10, 0, 20, 10), [4]float64{0, 0, 30, 20})
+	if got := img.RGBAAt(20, 10); got != (color.RGBA{0, 0xff, 0, 0xff}) {
+		t.Errorf("got %v, want the green quadrant", got)
+	}
+	if got := img.RGBAAt(36, 10); got != (color.RGBA{}) {
+		t.Errorf("got %v outside of the rectangle, want transparent", got)
+	}
+	if tr := gc.GetMatrixTransform(); !tr.Equals(draw2d.NewTranslationMatrix(5, 0)) {
+		t.Errorf("the transformation should be restored, got %v", tr)
+	}
+
+	img = image.NewRGBA(image.Rect(0, 0, 40, 40))
+	gc = NewGraphicContext(img)
+	gc.SetFilter(LinearFilter)
+	gc.DrawImageScaled(quadrants(), 0, 0, draw2d.ImageScaling{
+		Width: 40, Height: 20, ScalingPolicy: draw2d.ScalingFit, Halign: draw2d.HalignRight,
+	})
+	if got := img.RGBAAt(25, 5); got != (color.RGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("got %v, want the red quadrant of the image fitted to the right", got)
+	}
+	if got := img.RGBAAt(15, 5); got != (color.RGBA{}) {
+		t.Errorf("got %v left of the fitted image, want transparent", got)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// GetImageData returns a copy of the pixels of the rectangle of width x
+// height pixels at x, y of the image, or of the layer of the current group
+// if any. The rectangle is in device space, it is not transformed by the
+// current transformation. The copy has the bounds (0, 0, width, height), its
+// pixels outside of the image are transparent.
+func (gc *GraphicContext) GetImageData(x, y, width, height int) *image.RGBA {
+	data := image.NewRGBA(image.Rect(0, 0, max(width, 0), max(height, 0)))
+	draw.Draw(data, data.Rect, gc.img, image.Pt(x, y), draw.Src)
+	return data
+}
+
+// PutImageData replaces the pixels of the image, or of the layer of the
+// current group if any, by the ones of img, its top left pixel at x, y in
+// device space. The pixels are not transformed, masked nor composited.
+func (gc *GraphicContext) PutImageData(img image.Image, x, y int) {
+	bounds := img.Bounds()
+	draw.Draw(gc.img, bounds.Sub(bounds.Min).Add(image.Pt(x, y)), img, bounds.Min, draw.Src)
+}
+
+// Snapshot returns a copy of the image, or of the layer of the current
+// group if any, of the same bounds
+func (gc *GraphicContext) Snapshot() *image.RGBA {
+	bounds := gc.img.Bounds()
+	snapshot := image.NewRGBA(bounds)
+	draw.Draw(snapshot, bounds, gc.img, bounds.Min, draw.Src)
+	return snapshot
+}
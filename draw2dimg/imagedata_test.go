@@ -0,0 +1,60 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+func TestGraphicContext_ImageData(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	gc := NewGraphicContext(img)
+	gc.SetFillColor(color.NRGBA{0xff, 0, 0, 0x80})
+	draw2dkit.Rectangle(gc, 0, 0, 10, 10)
+	gc.Fill()
+	snapshot := gc.Snapshot()
+
+	// the transformation is ignored and the pixels outside of the image are
+	// transparent
+	gc.Translate(5, 5)
+	data := gc.GetImageData(-2, 8, 4, 4)
+	if data.Rect != image.Rect(0, 0, 4, 4) {
+		t.Fatalf("got bounds %v, want (0, 0, 4, 4)", data.Rect)
+	}
+	if got, want := data.RGBAAt(3, 0), img.RGBAAt(1, 8); got != want || got.A == 0 {
+		t.Errorf("got %v, want the pixel of the image %v", got, want)
+	}
+	if got := data.RGBAAt(0, 0); got != (color.RGBA{}) {
+		t.Errorf("got %v outside of the image, want transparent", got)
+	}
+	if got := data.RGBAAt(3, 3); got != (color.RGBA{}) {
+		t.Errorf("got %v outside of the fill, want transparent", got)
+	}
+
+	// the pixels are replaced, not composited
+	gc.SetFillColor(color.White)
+	draw2dkit.Rectangle(gc, -5, -5, 15, 15)
+	gc.Fill()
+	gc.PutImageData(data, -2, 8)
+	if got, want := img.RGBAAt(1, 8), data.RGBAAt(3, 0); got != want {
+		t.Errorf("got %v, want the put pixel %v", got, want)
+	}
+	if got := img.RGBAAt(1, 10); got != (color.RGBA{}) {
+		t.Errorf("got %v, want the transparent pixel put", got)
+	}
+
+	// the snapshot is a copy, which restores the image
+	if snapshot.RGBAAt(15, 15) != (color.RGBA{}) {
+		t.Error("the snapshot should not change with the image")
+	}
+	gc.PutImageData(snapshot, 0, 0)
+	if !bytes.Equal(img.Pix, snapshot.Pix) {
+		t.Error("putting the snapshot should restore the image")
+	}
+}
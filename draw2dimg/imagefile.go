@@ -0,0 +1,284 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// ImageFileOptions are the options of the encoders of SaveImageFile
+type ImageFileOptions struct {
+	// JPEGQuality is the quality of JPEG images, from 1 to 100,
+	// jpeg.DefaultQuality if 0
+	JPEGQuality int
+	// PNGCompression is the compression level of PNG images
+	PNGCompression png.CompressionLevel
+	// DPI is the resolution embedded in PNG and JPEG images, typically the
+	// one returned by GetDPI, none if 0
+	DPI int
+}
+
+// LoadImageFile opens an image file of which the format is detected among
+// PNG, JPEG, GIF, BMP, TIFF and WebP. The images of which the EXIF
+// orientation is not the default one are turned upright.
+func LoadImageFile(filePath string) (image.Image, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var orientation int
+	switch format {
+	case "jpeg":
+		orientation = jpegOrientation(data)
+	case "tiff":
+		orientation = tiffOrientation(data)
+	}
+	return orient(img, orientation), nil
+}
+
+// SaveImageFile creates and saves an image to a file in the format of its
+// extension: .png, .jpg or .jpeg, .gif, .bmp, .tif or .tiff. options may be
+// nil for the default options.
+func SaveImageFile(filePath string, m image.Image, options *ImageFileOptions) error {
+	if options == nil {
+		options = &ImageFileOptions{}
+	}
+	var encode func(w io.Writer) error
+	switch ext := strings.ToLower(filepath.Ext(filePath)); ext {
+	case ".png":
+		encode = func(w io.Writer) error { return encodePNG(w, m, options) }
+	case ".jpg", ".jpeg":
+		encode = func(w io.Writer) error { return encodeJPEG(w, m, options) }
+	case ".gif":
+		encode = func(w io.Writer) error { return gif.Encode(w, m, nil) }
+	case ".bmp":
+		encode = func(w io.Writer) error { return bmp.Encode(w, m) }
+	case ".tif", ".tiff":
+		encode = func(w io.Writer) error { return tiff.Encode(w, m, &tiff.Options{Compression: tiff.Deflate}) }
+	default:
+		return fmt.Errorf("draw2dimg: unsupported image file extension %q", ext)
+	}
+	// the image is encoded in a temporary file renamed once complete, an
+	// existing file is not lost if the encoding fails
+	f, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*")
+	if err != nil {
+		return err
+	}
+	if err := writeImageFile(f, filePath, encode); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filePath)
+}
+
+// writeImageFile encodes the image with encode in the temporary file f
+// which replaces filePath, with the permissions of filePath if it exists,
+// and closes f
+func writeImageFile(f *os.File, filePath string, encode func(w io.Writer) error) error {
+	b := bufio.NewWriter(f)
+	err := encode(b)
+	if err == nil {
+		err = b.Flush()
+	}
+	if err == nil {
+		// the temporary files are only readable by their owner
+		mode := os.FileMode(0o644)
+		if info, statErr := os.Stat(filePath); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+		err = f.Chmod(mode)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// encodePNG encodes m in PNG with a pHYs chunk of the resolution after the
+// IHDR chunk
+func encodePNG(w io.Writer, m image.Image, options *ImageFileOptions) error {
+	encoder := png.Encoder{CompressionLevel: options.PNGCompression}
+	if options.DPI <= 0 {
+		return encoder.Encode(w, m)
+	}
+	buf := &bytes.Buffer{}
+	if err := encoder.Encode(buf, m); err != nil {
+		return err
+	}
+	// the signature and the IHDR chunk of 13 bytes
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+	data := buf.Bytes()
+	// the pixels per meter of both axes, the unit being the meter
+	ppm := uint32(float64(options.DPI)/0.0254 + 0.5)
+	phys := make([]byte, 9)
+	binary.BigEndian.PutUint32(phys, ppm)
+	binary.BigEndian.PutUint32(phys[4:], ppm)
+	phys[8] = 1
+	if _, err := w.Write(data[:ihdrEnd]); err != nil {
+		return err
+	}
+	if err := writePNGChunk(w, "pHYs", phys); err != nil {
+		return err
+	}
+	_, err := w.Write(data[ihdrEnd:])
+	return err
+}
+
+// writePNGChunk writes the PNG chunk of type typ
+func writePNGChunk(w io.Writer, typ string, data []byte) error {
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], typ)
+	chunk = append(chunk, data...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	_, err := w.Write(chunk)
+	return err
+}
+
+// encodeJPEG encodes m in JPEG with a JFIF APP0 segment of the resolution
+// after the SOI marker
+func encodeJPEG(w io.Writer, m image.Image, options *ImageFileOptions) error {
+	quality := options.JPEGQuality
+	if quality == 0 {
+		quality = jpeg.DefaultQuality
+	}
+	if options.DPI <= 0 {
+		return jpeg.Encode(w, m, &jpeg.Options{Quality: quality})
+	}
+	buf := &bytes.Buffer{}
+	if err := jpeg.Encode(buf, m, &jpeg.Options{Quality: quality}); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	dpi := uint16(min(options.DPI, 0xffff))
+	app0 := []byte{0xff, 0xe0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 1, 1, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(app0[12:], dpi)
+	binary.BigEndian.PutUint16(app0[14:], dpi)
+	if _, err := w.Write(data[:2]); err != nil {
+		return err
+	}
+	if _, err := w.Write(app0); err != nil {
+		return err
+	}
+	_, err := w.Write(data[2:])
+	return err
+}
+
+// jpegOrientation returns the orientation of the EXIF APP1 segment of the
+// JPEG data, 0 if there is none
+func jpegOrientation(data []byte) int {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return 0
+	}
+	for i := 2; i+4 <= len(data) && data[i] == 0xff; {
+		marker := data[i+1]
+		if marker == 0xda {
+			// the image data follows the start of scan
+			return 0
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return 0
+		}
+		segment := data[i+4 : end]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i = end
+	}
+	return 0
+}
+
+// tiffOrientation returns the orientation tag of the first IFD of the TIFF
+// data, the format of the EXIF data, 0 if there is none
+func tiffOrientation(data []byte) int {
+	if len(data) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(data[4:]))
+	if ifd < 8 || ifd+2 > len(data) {
+		return 0
+	}
+	entries := int(order.Uint16(data[ifd:]))
+	for i := range entries {
+		entry := ifd + 2 + 12*i
+		if entry+12 > len(data) {
+			return 0
+		}
+		// the orientation is a SHORT
+		if order.Uint16(data[entry:]) == 0x0112 && order.Uint16(data[entry+2:]) == 3 {
+			return int(order.Uint16(data[entry+8:]))
+		}
+	}
+	return 0
+}
+
+// orient returns img turned upright as specified by the EXIF orientation,
+// from 1, upright, to 8
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	// the orientations from 5 transpose the image
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		for x := range dw {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,169 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveImageFile_Formats(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.png", "a.JPG", "a.jpeg", "a.gif", "a.bmp", "a.tif", "a.tiff"} {
+		filePath := filepath.Join(dir, name)
+		if err := SaveImageFile(filePath, quadrants(), nil); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		img, err := LoadImageFile(filePath)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if img.Bounds() != image.Rect(0, 0, 20, 20) {
+			t.Errorf("%s: got bounds %v", name, img.Bounds())
+		}
+		// the red top left quadrant, approximately for the lossy formats
+		r, g, b, _ := img.At(5, 5).RGBA()
+		if r < 0xe000 || g > 0x2000 || b > 0x2000 {
+			t.Errorf("%s: got %v, want red", name, img.At(5, 5))
+		}
+	}
+	if err := SaveImageFile(filepath.Join(dir, "a.xyz"), quadrants(), nil); err == nil {
+		t.Error("no error for an unsupported extension")
+	}
+}
+
+func TestSaveImageFile_Options(t *testing.T) {
+	dir := t.TempDir()
+	options := &ImageFileOptions{DPI: 300}
+	pngPath := filepath.Join(dir, "a.png")
+	if err := SaveImageFile(pngPath, quadrants(), options); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(pngPath)
+	// the pHYs chunk follows the IHDR chunk, 11811 pixels per meter
+	phys := data[33:]
+	if string(phys[4:8]) != "pHYs" || binary.BigEndian.Uint32(phys[8:]) != 11811 || phys[16] != 1 {
+		t.Errorf("got %q, want a pHYs chunk of 300 dpi", phys[:21])
+	}
+	if _, err := LoadImageFile(pngPath); err != nil {
+		t.Errorf("the PNG image is invalid: %v", err)
+	}
+
+	jpegPath := filepath.Join(dir, "a.jpg")
+	if err := SaveImageFile(jpegPath, quadrants(), options); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(jpegPath)
+	if string(data[6:11]) != "JFIF\x00" || data[13] != 1 || binary.BigEndian.Uint16(data[14:]) != 300 {
+		t.Errorf("got %q, want a JFIF segment of 300 dpi", data[:20])
+	}
+
+	size := func(quality int) int {
+		if err := SaveImageFile(jpegPath, checkerboard(64), &ImageFileOptions{JPEGQuality: quality}); err != nil {
+			t.Fatal(err)
+		}
+		info, _ := os.Stat(jpegPath)
+		return int(info.Size())
+	}
+	if low, high := size(10), size(95); low >= high {
+		t.Errorf("got %d bytes at quality 10 and %d at 95", low, high)
+	}
+}
+
+func TestSaveImageFile_Error(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "a.png")
+	if err := SaveImageFile(filePath, quadrants(), nil); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := os.ReadFile(filePath)
+	// an empty image cannot be encoded in PNG
+	if err := SaveImageFile(filePath, image.NewRGBA(image.Rect(0, 0, 0, 0)), nil); err == nil {
+		t.Fatal("no error for an empty image")
+	}
+	if got, _ := os.ReadFile(filePath); !bytes.Equal(got, want) {
+		t.Error("the existing file should be kept")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("got %d files, want the temporary file removed", len(entries))
+	}
+}
+
+// exifJPEG returns img encoded in JPEG with an EXIF segment of orientation
+func exifJPEG(t *testing.T, img image.Image, orientation uint16) []byte {
+	buf := &bytes.Buffer{}
+	if err := jpeg.Encode(buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	exif := []byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00")
+	binary.BigEndian.PutUint16(exif[6+8+2+8:], orientation)
+	app1 := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(2+len(exif)))
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), append(app1, exif...)...), data[2:]...)
+}
+
+func TestLoadImageFile_Orientation(t *testing.T) {
+	// 40 x 20 pixels, red on the left and blue on the right
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			img.Set(x, y, color.RGBA{0xff, 0, 0, 0xff})
+			if x >= 20 {
+				img.Set(x, y, color.RGBA{0, 0, 0xff, 0xff})
+			}
+		}
+	}
+	filePath := filepath.Join(t.TempDir(), "a.jpg")
+	// turned a quarter turn clockwise, the left is on the top
+	if err := os.WriteFile(filePath, exifJPEG(t, img, 6), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadImageFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Bounds() != image.Rect(0, 0, 20, 40) {
+		t.Fatalf("got bounds %v, want 20 x 40", loaded.Bounds())
+	}
+	if r, _, b, _ := loaded.At(10, 5).RGBA(); r < 0xe000 || b > 0x2000 {
+		t.Errorf("got %v on the top, want red", loaded.At(10, 5))
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 2 x 1 pixels, the pixel 0 on the left
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.Pix[0], img.Pix[1] = 0, 0xff
+	for _, test := range []struct {
+		orientation int
+		bounds      image.Rectangle
+		// the position of the pixel 0
+		first image.Point
+	}{
+		{1, image.Rect(0, 0, 2, 1), image.Pt(0, 0)},
+		{2, image.Rect(0, 0, 2, 1), image.Pt(1, 0)},
+		{3, image.Rect(0, 0, 2, 1), image.Pt(1, 0)},
+		{4, image.Rect(0, 0, 2, 1), image.Pt(0, 0)},
+		{5, image.Rect(0, 0, 1, 2), image.Pt(0, 0)},
+		{6, image.Rect(0, 0, 1, 2), image.Pt(0, 0)},
+		{7, image.Rect(0, 0, 1, 2), image.Pt(0, 1)},
+		{8, image.Rect(0, 0, 1, 2), image.Pt(0, 1)},
+	} {
+		got := orient(img, test.orientation)
+		if got.Bounds() != test.bounds {
+			t.Errorf("orientation %d: got bounds %v, want %v", test.orientation, got.Bounds(), test.bounds)
+			continue
+		}
+		if r, _, _, _ := got.At(test.first.X, test.first.Y).RGBA(); r != 0 {
+			t.Errorf("orientation %d: the pixel 0 is not at %v", test.orientation, test.first)
+		}
+	}
+}
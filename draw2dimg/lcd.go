@@ -0,0 +1,226 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/golang/freetype/raster"
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
+)
+
+// SubpixelOrder is the order of the color subpixels of an LCD screen, used
+// to antialias text
+type SubpixelOrder int
+
+const (
+	// SubpixelNone antialiases text in greyscale
+	SubpixelNone SubpixelOrder = iota
+	// SubpixelRGB is for screens with red, green then blue subpixels from
+	// left to right
+	SubpixelRGB
+	// SubpixelBGR is for screens with blue, green then red subpixels from
+	// left to right
+	SubpixelBGR
+)
+
+// lcdFilter spreads the coverage of a subpixel on its neighbors to reduce
+// color fringes, it is the default LCD filter of FreeType
+var lcdFilter = [5]float64{0x08 / 256.0, 0x4D / 256.0, 0x56 / 256.0, 0x4D / 256.0, 0x08 / 256.0}
+
+// SetSubpixelAntialiasing sets the subpixel order used to antialias the text
+// drawn by FillString and StrokeString. Glyphs are rasterized at 3 times the
+// horizontal resolution, filtered and composited channel by channel. Each
+// channel can only be blended with an opaque background, on transparent
+// pixels the color fringes cannot be represented. The default is
+// SubpixelNone.
+func (gc *GraphicContext) SetSubpixelAntialiasing(order SubpixelOrder) {
+	gc.subpixelOrder = order
+}
+
+// drawStringLCD draws text with subpixel antialiasing in c, filled or
+// stroked with the current line width
+func (gc *GraphicContext) drawStringLCD(f *truetype.Font, text string, x, y float64, c color.Color, stroke bool) float64 {
+	// the glyphs are collected first, to rasterize only their bounds
+	type lcdGlyph struct {
+		path *draw2d.Path
+		tr   draw2d.Matrix
+	}
+	var glyphs []lcdGlyph
+	margin := 0.0
+	if stroke {
+		margin = gc.strokeMargin()
+	}
+	x0, y0, x1, y1 := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	addGlyph := func(g *draw2dbase.Glyph, _ draw2d.GraphicContext) {
+		// the transformation scaled 3 times horizontally
+		tr := gc.GetMatrixTransform()
+		tr[0], tr[2], tr[4] = 3*tr[0], 3*tr[2], 3*tr[4]
+		if gx0, gy0, gx1, gy1, ok := pathsBounds([]*draw2d.Path{g.Path}, tr, margin); ok {
+			x0, y0, x1, y1 = math.Min(x0, gx0), math.Min(y0, gy0), math.Max(x1, gx1), math.Max(y1, gy1)
+			glyphs = append(glyphs, lcdGlyph{g.Path, tr})
+		}
+	}
+	width := draw2dbase.DrawString(gc, gc.glyphCache, f, gc.Current.Scale, text, x, y, gc.glyphRendering, addGlyph)
+	if len(glyphs) == 0 {
+		return width
+	}
+	// the pixels of the glyphs, with a margin of 1 pixel in which the
+	// filter spreads their coverage
+	area := image.Rect(int(math.Floor(x0/3))-1, int(math.Floor(y0))-1, int(math.Ceil(x1/3))+1, int(math.Ceil(y1))+1)
+	area = area.Intersect(gc.img.Bounds())
+	if area.Empty() {
+		return width
+	}
+
+	if gc.lcd == nil {
+		gc.lcd = &lcdRasterizer{rasterizer: raster.NewRasterizer(0, 0)}
+		gc.lcd.rasterizer.UseNonZeroWinding = true
+	}
+	rasterizer, mask := gc.lcd.rasterizer, &gc.lcd.mask
+	rasterizer.SetBounds(3*area.Dx(), area.Dy())
+	// the glyphs are added relatively to the area, the spans are painted
+	// in the subpixels of the image
+	rasterizer.Dx, rasterizer.Dy = 3*area.Min.X, area.Min.Y
+	for _, g := range glyphs {
+		tr := g.tr
+		tr[4], tr[5] = tr[4]-float64(rasterizer.Dx), tr[5]-float64(rasterizer.Dy)
+		var flattener draw2dbase.Flattener = draw2dbase.Transformer{Tr: tr, Flattener: FtLineBuilder{Adder: rasterizer}}
+		if stroke {
+			stroker := draw2dbase.NewLineStroker(gc.Current.Cap, gc.Current.Join, flattener)
+			stroker.HalfLineWidth = gc.Current.LineWidth / 2
+			flattener = stroker
+		}
+		draw2dbase.Flatten(g.path, flattener, tr.GetScale())
+	}
+	mask.reset(image.Rect(3*area.Min.X, area.Min.Y, 3*area.Max.X, area.Max.Y))
+	rasterizer.Rasterize(mask)
+	gc.paintLCD(mask, c)
+	return width
+}
+
+// lcdRasterizer rasterizes the text drawn with subpixel antialiasing, it is
+// kept by the graphic context to be reused by the next drawings
+type lcdRasterizer struct {
+	rasterizer *raster.Rasterizer
+	mask       coverageMask
+}
+
+// coverageMask is a raster.Painter accumulating the coverage of the spans
+// of a rasterizer in the subpixels of rect, and the bounds of the painted
+// area
+type coverageMask struct {
+	rect   image.Rectangle
+	pix    []float64
+	bounds image.Rectangle
+}
+
+// reset clears the mask and sets its rectangle, reusing its coverage
+// buffer if it is large enough
+func (mask *coverageMask) reset(rect image.Rectangle) {
+	n := rect.Dx() * rect.Dy()
+	if cap(mask.pix) < n {
+		mask.pix = make([]float64, n)
+	} else {
+		mask.pix = mask.pix[:n]
+		clear(mask.pix)
+	}
+	mask.rect, mask.bounds = rect, image.Rectangle{}
+}
+
+func (mask *coverageMask) Paint(spans []raster.Span, done bool) {
+	width := mask.rect.Dx()
+	for _, s := range spans {
+		if s.Y < mask.rect.Min.Y || s.Y >= mask.rect.Max.Y {
+			continue
+		}
+		x0, x1 := max(s.X0, mask.rect.Min.X), min(s.X1, mask.rect.Max.X)
+		if x0 >= x1 {
+			continue
+		}
+		row := mask.pix[(s.Y-mask.rect.Min.Y)*width:]
+		for x := x0 - mask.rect.Min.X; x < x1-mask.rect.Min.X; x++ {
+			row[x] = math.Min(row[x]+float64(s.Alpha)/0xffff, 1)
+		}
+		mask.bounds = mask.bounds.Union(image.Rect(x0, s.Y, x1, s.Y+1))
+	}
+}
+
+// coverage returns the filtered coverage of the subpixel x of row y
+func (mask *coverageMask) coverage(x, y int) float64 {
+	if y < mask.rect.Min.Y || y >= mask.rect.Max.Y {
+		return 0
+	}
+	row := mask.pix[(y-mask.rect.Min.Y)*mask.rect.Dx():]
+	coverage := 0.0
+	for i, weight := range lcdFilter {
+		if sx := x + i - len(lcdFilter)/2; sx >= mask.rect.Min.X && sx < mask.rect.Max.X {
+			coverage += weight * row[sx-mask.rect.Min.X]
+		}
+	}
+	return coverage
+}
+
+// paintLCD composites c on the image, each channel weighted by the coverage
+// of its subpixel
+func (gc *GraphicContext) paintLCD(mask *coverageMask, c color.Color) {
+	cr, cg, cb, ca := c.RGBA()
+	if ca == 0 || mask.bounds.Empty() {
+		return
+	}
+	linear := gc.linearPainter != nil
+	if linear {
+		// the channels are blended in linear light
+		l := toLinear(color.RGBA64{uint16(cr), uint16(cg), uint16(cb), uint16(ca)})
+		cr, cg, cb = uint32(l.R), uint32(l.G), uint32(l.B)
+	}
+	// c without alpha premultiplication
+	src := [3]float64{float64(cr) / float64(ca), float64(cg) / float64(ca), float64(cb) / float64(ca)}
+	alpha := float64(ca) / 0xffff
+
+	// the filter spreads the coverage over 2 more subpixels on each side
+	spread := len(lcdFilter) / 2
+	area := image.Rect((mask.bounds.Min.X-spread)/3, mask.bounds.Min.Y, (mask.bounds.Max.X+spread+2)/3, mask.bounds.Max.Y)
+	area = area.Intersect(gc.img.Bounds())
+	// the current mask, if any
+	coverage := gc.currentMask()
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			var a [3]float64
+			for i := range a {
+				a[i] = mask.coverage(3*x+i, y) * alpha
+				if coverage != nil {
+					a[i] *= float64(coverage.AlphaAt(x, y).A) / 0xff
+				}
+			}
+			if a[0] == 0 && a[1] == 0 && a[2] == 0 {
+				continue
+			}
+			if gc.subpixelOrder == SubpixelBGR {
+				a[0], a[2] = a[2], a[0]
+			}
+			d := color.RGBA64Model.Convert(gc.img.At(x, y)).(color.RGBA64)
+			if linear {
+				d = toLinear(d)
+			}
+			dr, dg, db, da := d.RGBA()
+			dst := [3]float64{float64(dr), float64(dg), float64(db)}
+			var out [3]uint16
+			for i := range out {
+				out[i] = uint16(math.Round(src[i]*a[i]*0xffff + dst[i]*(1-a[i])))
+			}
+			mean := (a[0] + a[1] + a[2]) / 3
+			outA := uint16(math.Round(mean*0xffff + float64(da)*(1-mean)))
+			c := color.RGBA64{R: min(out[0], outA), G: min(out[1], outA), B: min(out[2], outA), A: outA}
+			if linear {
+				c = fromLinear(c)
+			}
+			gc.img.Set(x, y, c)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func drawLCDText(order SubpixelOrder, stroke bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 80, 30))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	gc := newTextContext(img)
+	gc.SetFillColor(color.Black)
+	gc.SetStrokeColor(color.Black)
+	gc.SetFontSize(12)
+	gc.SetSubpixelAntialiasing(order)
+	if stroke {
+		gc.StrokeStringAt("Wave", 5, 20)
+	} else {
+		gc.FillStringAt("Wave", 5, 20)
+	}
+	return img
+}
+
+func TestSetSubpixelAntialiasing(t *testing.T) {
+	grey := drawLCDText(SubpixelNone, false)
+	rgb := drawLCDText(SubpixelRGB, false)
+	bgr := drawLCDText(SubpixelBGR, false)
+
+	fringes, inked := 0, 0
+	for i := 0; i < len(rgb.Pix); i += 4 {
+		if grey.Pix[i] != grey.Pix[i+1] || grey.Pix[i+1] != grey.Pix[i+2] {
+			t.Fatal("greyscale antialiasing should not produce colors")
+		}
+		r, g, b, a := rgb.Pix[i], rgb.Pix[i+1], rgb.Pix[i+2], rgb.Pix[i+3]
+		if a != 0xff {
+			t.Fatalf("the background should stay opaque, got alpha %d", a)
+		}
+		if r != g || g != b {
+			fringes++
+		}
+		if r < 0x80 {
+			inked++
+		}
+		// the coverage of the red subpixel in RGB order is the one of blue in BGR order
+		if r != bgr.Pix[i+2] || g != bgr.Pix[i+1] || b != bgr.Pix[i] {
+			t.Fatalf("BGR pixel %v should mirror RGB pixel %v", bgr.Pix[i:i+3], rgb.Pix[i:i+3])
+		}
+	}
+	if fringes == 0 || inked == 0 {
+		t.Errorf("subpixel antialiasing should draw colored edges, got %d colored and %d dark pixels", fringes, inked)
+	}
+}
+
+func TestSetSubpixelAntialiasing_Stroke(t *testing.T) {
+	filled, stroked := drawLCDText(SubpixelRGB, false), drawLCDText(SubpixelRGB, true)
+	different := false
+	for i := range filled.Pix {
+		different = different || filled.Pix[i] != stroked.Pix[i]
+	}
+	if !different {
+		t.Error("stroked text should differ from filled text")
+	}
+}
+
+func TestSetSubpixelAntialiasing_Bounds(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1000, 1000))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	gc := newTextContext(img)
+	gc.SetFillColor(color.Black)
+	gc.SetFontSize(12)
+	gc.SetSubpixelAntialiasing(SubpixelRGB)
+	gc.FillStringAt("Wave", 500, 500)
+	// the coverage of the glyphs only, with a margin
+	rect := gc.lcd.mask.rect
+	if !rect.Overlaps(image.Rect(1500, 490, 1600, 500)) || rect.Dx() > 3*60 || rect.Dy() > 30 {
+		t.Fatalf("got coverage rectangle %v, want the one of the text", rect)
+	}
+	pix := &gc.lcd.mask.pix[:1][0]
+	gc.FillStringAt("Wave", 100, 100)
+	if &gc.lcd.mask.pix[:1][0] != pix {
+		t.Error("the coverage buffer is not reused")
+	}
+	inked := 0
+	for y := 85; y < 105; y++ {
+		for x := 100; x < 140; x++ {
+			if img.RGBAAt(x, y).R < 0x80 {
+				inked++
+			}
+		}
+	}
+	if inked == 0 {
+		t.Error("the second text is not drawn")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"golang.org/x/image/draw"
+)
+
+// SetLinearBlending sets whether the fills, strokes and text are composited
+// in linear light. Image colors are sRGB encoded, blending them directly
+// makes antialiased edges and translucent overlaps too dark, and thin light
+// text on a dark background too thin. In linear light the colors are
+// decoded, blended and encoded back, as browsers do. The painter of the
+// graphic context is replaced by the one returned by NewLinearPainter while
+// enabled. The default is false.
+func (gc *GraphicContext) SetLinearBlending(linear bool) {
+	gc.linearPainter = nil
+	if linear {
+		gc.linearPainter = NewLinearPainter(gc.img)
+		// the linear painter dithers as the painter does
+		if p, ok := gc.painter.(*PalettedPainter); ok {
+			if lp, ok := gc.linearPainter.(*PalettedPainter); ok {
+				lp.Dither = p.Dither
+			}
+		}
+	}
+}
+
+// NewLinearPainter returns a Painter drawing on img as NewPainter does, but
+// compositing the colors in linear light
+func NewLinearPainter(img draw.Image) Painter {
+	if img, ok := img.(*image.Paletted); ok {
+		p := NewPalettedPainter(img, false)
+		p.Linear = true
+		return p
+	}
+	p := newPixelPainter(img)
+	p.Linear = true
+	return p
+}
+
+var (
+	srgbOnce sync.Once
+	// srgbDecode maps 16-bit sRGB encoded values to linear light values
+	srgbDecode [1 << 16]uint16
+	// srgbEncode maps 16-bit linear light values to sRGB encoded values
+	srgbEncode [1 << 16]uint16
+)
+
+// srgbTables returns the sRGB decode and encode tables, built on first use
+func srgbTables() (decode, encode *[1 << 16]uint16) {
+	srgbOnce.Do(func() {
+		for i := range srgbDecode {
+			v := float64(i) / 0xffff
+			if v <= 0.04045 {
+				v /= 12.92
+			} else {
+				v = math.Pow((v+0.055)/1.055, 2.4)
+			}
+			srgbDecode[i] = uint16(math.Round(v * 0xffff))
+		}
+		for i := range srgbEncode {
+			v := float64(i) / 0xffff
+			if v <= 0.0031308 {
+				v *= 12.92
+			} else {
+				v = 1.055*math.Pow(v, 1/2.4) - 0.055
+			}
+			srgbEncode[i] = uint16(math.Round(v * 0xffff))
+		}
+	})
+	return &srgbDecode, &srgbEncode
+}
+
+// toLinear decodes the premultiplied sRGB color c to premultiplied linear
+// light
+func toLinear(c color.RGBA64) color.RGBA64 {
+	decode, _ := srgbTables()
+	return convertColor(c, decode)
+}
+
+// fromLinear encodes the premultiplied linear light color c to premultiplied
+// sRGB
+func fromLinear(c color.RGBA64) color.RGBA64 {
+	_, encode := srgbTables()
+	return convertColor(c, encode)
+}
+
+// convertColor maps the components of c, without alpha premultiplication,
+// with table
+func convertColor(c color.RGBA64, table *[1 << 16]uint16) color.RGBA64 {
+	if c.A == 0 {
+		return c
+	}
+	r, g, b, a := unpremultiply(c)
+	return premultiply(uint32(table[r]), uint32(table[g]), uint32(table[b]), a)
+}
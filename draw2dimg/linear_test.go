@@ -0,0 +1,79 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+func TestSRGBTables(t *testing.T) {
+	decode, encode := srgbTables()
+	for v := 0; v < 256; v++ {
+		if got := to8(encode[decode[v*0x101]]); int(got) != v {
+			t.Errorf("the 8-bit value %d is encoded back to %d", v, got)
+		}
+	}
+	if l := decode[0x8080]; l < 0x3600 || l > 0x3800 {
+		t.Errorf("the sRGB mid grey should be about 21%% in linear light, got %#x", l)
+	}
+}
+
+// drawHalfCovered draws on a black image a white rectangle of which the
+// left column is half covered, and a translucent white square
+func drawHalfCovered(linear bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	draw.Draw(img, img.Bounds(), image.Black, image.Point{}, draw.Src)
+	gc := NewGraphicContext(img)
+	gc.SetLinearBlending(linear)
+	gc.SetFillColor(color.White)
+	draw2dkit.Rectangle(gc, 1.5, 0, 5, 10)
+	gc.Fill()
+	gc.SetFillColor(color.NRGBA{0xff, 0xff, 0xff, 0x80})
+	draw2dkit.Rectangle(gc, 10, 0, 15, 10)
+	gc.Fill()
+	return img
+}
+
+func TestSetLinearBlending(t *testing.T) {
+	srgb, linear := drawHalfCovered(false), drawHalfCovered(true)
+	for _, x := range []int{1, 12} {
+		if s, l := srgb.RGBAAt(x, 5), linear.RGBAAt(x, 5); s.R < 0x7f || s.R > 0x80 || l.R < 0xba || l.R > 0xbd || l.A != 0xff {
+			t.Errorf("a half white pixel (%d, 5) should be mid grey in linear light, got %v and %v", x, s, l)
+		}
+	}
+	if c := linear.RGBAAt(3, 5); c != (color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("opaque fills should keep their color, got %v", c)
+	}
+	if c := linear.RGBAAt(7, 5); c != (color.RGBA{0, 0, 0, 0xff}) {
+		t.Errorf("the background should be left unchanged, got %v", c)
+	}
+}
+
+func TestSetLinearBlending_Text(t *testing.T) {
+	ink := func(linear bool, order SubpixelOrder) (sum int) {
+		img := image.NewRGBA(image.Rect(0, 0, 80, 30))
+		draw.Draw(img, img.Bounds(), image.Black, image.Point{}, draw.Src)
+		gc := newTextContext(img)
+		gc.SetLinearBlending(linear)
+		gc.SetSubpixelAntialiasing(order)
+		gc.SetFillColor(color.White)
+		gc.SetFontSize(9)
+		gc.FillStringAt("thin", 5, 20)
+		for i := 0; i < len(img.Pix); i += 4 {
+			sum += int(img.Pix[i]) + int(img.Pix[i+1]) + int(img.Pix[i+2])
+		}
+		return sum
+	}
+	// light text on a dark background is bolder in linear light
+	for _, order := range []SubpixelOrder{SubpixelNone, SubpixelRGB} {
+		if srgb, linear := ink(false, order), ink(true, order); linear <= srgb {
+			t.Errorf("text with subpixel order %v should be brighter in linear light, got %d and %d", order, srgb, linear)
+		}
+	}
+}
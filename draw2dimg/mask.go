@@ -0,0 +1,147 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/golang/freetype/raster"
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// currentMask returns the coverage of the current mask on the image, nil
+// if the drawings are not masked. The coverage of the last mask is cached.
+func (gc *GraphicContext) currentMask() *image.Alpha {
+	m := gc.Current.Mask
+	if m == nil {
+		return nil
+	}
+	if gc.mask != m || gc.maskCoverage.Rect != gc.img.Bounds() {
+		gc.mask, gc.maskCoverage = m, gc.rasterizeMask(m)
+	}
+	return gc.maskCoverage
+}
+
+// rasterizeMask returns the coverage of the mask m on the image
+func (gc *GraphicContext) rasterizeMask(m *draw2dbase.Mask) *image.Alpha {
+	bounds := gc.img.Bounds()
+	coverage := image.NewAlpha(bounds)
+	if m.Image != nil {
+		src := maskAlpha(m.Image, m.Luminance)
+		draw.BiLinear.Transform(coverage, f64.Aff3{m.Tr[0], m.Tr[2], m.Tr[4], m.Tr[1], m.Tr[3], m.Tr[5]}, src, src.Rect, draw.Src, nil)
+		return coverage
+	}
+	r := newRasterizer(gc.rasterization, bounds.Dx(), bounds.Dy(), 0)
+	setNonZeroWinding(r, m.FillRule == draw2d.FillRuleWinding)
+	flattener := draw2dbase.Transformer{Tr: m.Tr, Flattener: lineBuilder(r)}
+	for _, p := range m.Paths {
+		draw2dbase.Flatten(p, flattener, m.Tr.GetScale())
+	}
+	r.Rasterize(raster.NewAlphaSrcPainter(coverage))
+	return coverage
+}
+
+// maskAlpha returns the alpha of img, or its luminance times its alpha,
+// that is the luminance of its premultiplied colors, if luminance is true
+func maskAlpha(img image.Image, luminance bool) *image.Alpha {
+	bounds := img.Bounds()
+	alpha := image.NewAlpha(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			if luminance {
+				a = (2126*r + 7152*g + 722*b) / 10000
+			}
+			alpha.SetAlpha(x, y, color.Alpha{A: uint8(a >> 8)})
+		}
+	}
+	return alpha
+}
+
+// maskedPainter multiplies the coverage of the spans by the coverage of a
+// mask. It is safe for concurrent use if its Painter is.
+type maskedPainter struct {
+	Painter
+	mask *image.Alpha
+}
+
+// maskedSpans are the buffers of the spans painted by maskedPainter, which
+// paints different rows concurrently with TiledRasterizer
+var maskedSpans = sync.Pool{New: func() any { return new([]raster.Span) }}
+
+func (p maskedPainter) Paint(ss []raster.Span, done bool) {
+	buf := maskedSpans.Get().(*[]raster.Span)
+	masked := (*buf)[:0]
+	rect := p.mask.Rect
+	for _, s := range ss {
+		if s.Y < rect.Min.Y || s.Y >= rect.Max.Y {
+			continue
+		}
+		row := p.mask.Pix[p.mask.PixOffset(rect.Min.X, s.Y):]
+		x1 := min(s.X1, rect.Max.X) - rect.Min.X
+		// the span is split where the coverage of the mask changes, x0 and
+		// end being relative to the mask
+		for x0 := max(s.X0, rect.Min.X) - rect.Min.X; x0 < x1; {
+			a := row[x0]
+			end := x0 + 1
+			for end < x1 && row[end] == a {
+				end++
+			}
+			if alpha := s.Alpha * uint32(a) * 0x101 / 0xffff; alpha != 0 {
+				masked = append(masked, raster.Span{Y: s.Y, X0: rect.Min.X + x0, X1: rect.Min.X + end, Alpha: alpha})
+			}
+			x0 = end
+		}
+	}
+	p.Painter.Paint(masked, done)
+	*buf = masked
+	maskedSpans.Put(buf)
+}
+
+// drawMasked draws with draw on a layer, unmasked, and composites the
+// layer multiplied by the coverage of the current mask. The layer only
+// covers the pixels of rect which are not masked out, rect being the ones
+// in which draw draws.
+func (gc *GraphicContext) drawMasked(rect image.Rectangle, draw func()) {
+	mask := gc.Current.Mask
+	x0, y0, x1, y1, ok := mask.Bounds()
+	if !ok {
+		return
+	}
+	rect = rect.Intersect(gc.deviceRect(gc.img.Bounds(), x0, y0, x1, y1))
+	if rect.Empty() {
+		return
+	}
+	coverage := gc.currentMask()
+	gc.Current.Mask = nil
+	gc.pushLayer(rect)
+	draw()
+	layer := gc.img.(*image.RGBA)
+	gc.popGroup()
+	gc.Current.Mask = mask
+	applyMask(layer, coverage)
+	compositeLayer(gc.img, layer, 1, draw2d.BlendNormal, gc.linearPainter != nil)
+}
+
+// applyMask multiplies the premultiplied colors of img by the coverage of
+// mask, which is 0 outside of it
+func applyMask(img *image.RGBA, mask *image.Alpha) {
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(img.Rect.Min.X, y):]
+		for x := 0; x < img.Rect.Dx(); x++ {
+			a := uint32(mask.AlphaAt(img.Rect.Min.X+x, y).A)
+			if a == 0xff {
+				continue
+			}
+			for c := 4 * x; c < 4*x+4; c++ {
+				row[c] = uint8((uint32(row[c])*a + 127) / 255)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/golang/freetype/raster"
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+func TestGraphicContext_MaskWithPath(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	gc := NewGraphicContext(img)
+	gc.Save()
+	// the left half of the image
+	gc.Scale(2, 2)
+	draw2dkit.Rectangle(gc, 0, 0, 10, 20)
+	gc.MaskWithPath()
+	gc.SetMatrixTransform(draw2d.NewIdentityMatrix())
+	gc.SetFillColor(color.RGBA{0xff, 0, 0, 0xff})
+	draw2dkit.Rectangle(gc, 0, 0, 40, 40)
+	gc.Fill()
+	if got := img.RGBAAt(10, 20); got != (color.RGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("got %v within the mask, want red", got)
+	}
+	if got := img.RGBAAt(30, 20); got != (color.RGBA{}) {
+		t.Errorf("got %v outside of the mask, want transparent", got)
+	}
+	gc.Restore()
+	draw2dkit.Rectangle(gc, 0, 0, 40, 40)
+	gc.Fill()
+	if got := img.RGBAAt(30, 20); got != (color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("got %v after Restore, want the unmasked fill", got)
+	}
+}
+
+func TestGraphicContext_SetMask(t *testing.T) {
+	// a mask of 2 pixels, opaque black and half transparent white, scaled
+	// to the image
+	mask := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	mask.SetNRGBA(0, 0, color.NRGBA{0, 0, 0, 0xff})
+	mask.SetNRGBA(1, 0, color.NRGBA{0xff, 0xff, 0xff, 0x80})
+	for _, test := range []struct {
+		luminance   bool
+		left, right uint8
+	}{
+		{false, 0xff, 0x80},
+		{true, 0, 0x80},
+	} {
+		img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+		gc := NewGraphicContext(img)
+		if test.luminance {
+			gc.SetLuminanceMask(mask, draw2d.NewScaleMatrix(20, 40))
+		} else {
+			gc.SetMask(mask, draw2d.NewScaleMatrix(20, 40))
+		}
+		gc.SetFillColor(color.RGBA{0, 0, 0xff, 0xff})
+		draw2dkit.Rectangle(gc, 0, 0, 40, 40)
+		gc.Fill()
+		if got := img.RGBAAt(5, 20).A; got != test.left {
+			t.Errorf("luminance %v: got alpha %#x on the left, want %#x", test.luminance, got, test.left)
+		}
+		if got := img.RGBAAt(35, 20).A; got < test.right-1 || got > test.right+1 {
+			t.Errorf("luminance %v: got alpha %#x on the right, want %#x", test.luminance, got, test.right)
+		}
+
+		// images are masked too
+		src := image.NewRGBA(image.Rect(0, 0, 40, 40))
+		NewGraphicContext(src).Clear()
+		clear(img.Pix)
+		gc.DrawImage(src)
+		if got := img.RGBAAt(5, 20).A; got != test.left {
+			t.Errorf("luminance %v: got image alpha %#x on the left, want %#x", test.luminance, got, test.left)
+		}
+	}
+}
+
+// spanRecorder is a Painter recording the painted spans
+type spanRecorder struct {
+	spans []raster.Span
+}
+
+func (r *spanRecorder) Paint(ss []raster.Span, done bool) {
+	r.spans = append(r.spans, ss...)
+}
+
+func (r *spanRecorder) SetColor(c color.Color) {}
+
+func TestMaskedPainter_Paint(t *testing.T) {
+	mask := image.NewAlpha(image.Rect(10, 0, 20, 1))
+	copy(mask.Pix, []uint8{0, 0, 0xff, 0xff, 0xff, 0x80, 0x80, 0, 0xff, 0xff})
+	recorder := &spanRecorder{}
+	maskedPainter{recorder, mask}.Paint([]raster.Span{{Y: 0, X0: 0, X1: 30, Alpha: 0xffff}, {Y: 1, X0: 0, X1: 30, Alpha: 0xffff}}, true)
+	// the span is split where the coverage of the mask changes, the row
+	// outside of the mask is masked out
+	want := []raster.Span{
+		{Y: 0, X0: 12, X1: 15, Alpha: 0xffff},
+		{Y: 0, X0: 15, X1: 17, Alpha: 0x8080},
+		{Y: 0, X0: 18, X1: 20, Alpha: 0xffff},
+	}
+	if !reflect.DeepEqual(recorder.spans, want) {
+		t.Errorf("got spans %v, want %v", recorder.spans, want)
+	}
+}
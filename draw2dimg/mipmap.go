@@ -0,0 +1,176 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"container/list"
+	"image"
+	"math"
+	"reflect"
+	"sync"
+
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/draw"
+)
+
+// DefaultMipmapCacheSize is the number of images of which the mipmaps are
+// kept by the mipmap cache a graphic context creates for itself
+const DefaultMipmapCacheSize = 16
+
+// MipmapCache holds the mipmaps of the images reduced by DrawImage and
+// DrawImageRect: the images halved once, twice and so on, from which the
+// images are scaled down by less than 2. It holds the mipmaps of a bounded number of images,
+// the mipmaps of the least recently drawn images are evicted first. It is
+// keyed by source image, the images of types which are not comparable are
+// not cached. It is safe for concurrent use and can be shared by several
+// graphic contexts.
+type MipmapCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[image.Image]*list.Element
+	// order holds the entries, most recently used first
+	order *list.List
+}
+
+type mipmapEntry struct {
+	img image.Image
+	// levels are the mipmaps of img, levels[i] being halved i+1 times
+	levels []*image.RGBA
+}
+
+// NewMipmapCache creates a mipmap cache holding the mipmaps of at most
+// capacity images, DefaultMipmapCacheSize if capacity is not positive
+func NewMipmapCache(capacity int) *MipmapCache {
+	if capacity <= 0 {
+		capacity = DefaultMipmapCacheSize
+	}
+	return &MipmapCache{
+		capacity: capacity,
+		entries:  make(map[image.Image]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Level returns img halved level times, level being at least 1. The
+// mipmap has the bounds (0, 0, width, height), its size being the one of
+// img divided by 2 to the level, rounded up.
+func (cache *MipmapCache) Level(img image.Image, level int) *image.RGBA {
+	if !reflect.TypeOf(img).Comparable() {
+		return mipmapLevels(img, nil, level)[level-1]
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	element, ok := cache.entries[img]
+	if ok {
+		cache.order.MoveToFront(element)
+	} else {
+		element = cache.order.PushFront(&mipmapEntry{img: img})
+		cache.entries[img] = element
+		for cache.order.Len() > cache.capacity {
+			oldest := cache.order.Back()
+			cache.order.Remove(oldest)
+			delete(cache.entries, oldest.Value.(*mipmapEntry).img)
+		}
+	}
+	entry := element.Value.(*mipmapEntry)
+	entry.levels = mipmapLevels(img, entry.levels, level)
+	return entry.levels[level-1]
+}
+
+// Remove removes the mipmaps of img, which must be done when img is
+// modified after being drawn
+func (cache *MipmapCache) Remove(img image.Image) {
+	if !reflect.TypeOf(img).Comparable() {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if element, ok := cache.entries[img]; ok {
+		cache.order.Remove(element)
+		delete(cache.entries, img)
+	}
+}
+
+// Clear removes all the mipmaps from the cache
+func (cache *MipmapCache) Clear() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.entries = make(map[image.Image]*list.Element)
+	cache.order.Init()
+}
+
+// mipmapLevels returns levels completed up to level
+func mipmapLevels(img image.Image, levels []*image.RGBA, level int) []*image.RGBA {
+	if len(levels) == 0 {
+		bounds := img.Bounds()
+		src, ok := img.(*image.RGBA)
+		if !ok || bounds.Min != (image.Point{}) {
+			src = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+			draw.Draw(src, src.Rect, img, bounds.Min, draw.Src)
+		}
+		levels = append(levels, halve(src))
+	}
+	for len(levels) < level {
+		levels = append(levels, halve(levels[len(levels)-1]))
+	}
+	return levels
+}
+
+// halve returns src of which each pixel is the mean of a square of 2 x 2
+// pixels of src, or of the pixels of the square within src on its odd
+// right and bottom edges
+func halve(src *image.RGBA) *image.RGBA {
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, (width+1)/2, (height+1)/2))
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			var sum [4]uint32
+			n := uint32(0)
+			for sy := 2 * y; sy < min(2*y+2, height); sy++ {
+				for sx := 2 * x; sx < min(2*x+2, width); sx++ {
+					i := src.PixOffset(src.Rect.Min.X+sx, src.Rect.Min.Y+sy)
+					for c := range sum {
+						sum[c] += uint32(src.Pix[i+c])
+					}
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[i+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// mipmapLevel returns the number of times an image drawn with tr is halved
+// before being scaled, so that it is scaled down by less than 2
+func mipmapLevel(tr draw2d.Matrix) int {
+	// the largest scale of the axes
+	scale := math.Max(math.Hypot(tr[0], tr[1]), math.Hypot(tr[2], tr[3]))
+	if scale <= 0 || scale >= 0.5 {
+		return 0
+	}
+	return int(math.Floor(-math.Log2(scale)))
+}
+
+// reduce returns the mipmap of img of which the rectangle sr is drawn with
+// tr, the rectangle of the mipmap covering sr and the transformation with
+// which it is drawn instead
+func (cache *MipmapCache) reduce(img image.Image, sr image.Rectangle, tr draw2d.Matrix) (image.Image, image.Rectangle, draw2d.Matrix) {
+	level := mipmapLevel(tr)
+	bounds := img.Bounds()
+	if level == 0 || bounds.Empty() {
+		return img, sr, tr
+	}
+	mipmap := cache.Level(img, level)
+	// each pixel of the mipmap is the mean of n x n pixels of img
+	n := 1 << level
+	sr = sr.Sub(bounds.Min)
+	sr = image.Rect(sr.Min.X/n, sr.Min.Y/n, (sr.Max.X+n-1)/n, (sr.Max.Y+n-1)/n)
+	tr.Translate(float64(bounds.Min.X), float64(bounds.Min.Y))
+	tr.Scale(float64(n), float64(n))
+	return mipmap, sr, tr
+}
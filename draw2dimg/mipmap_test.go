@@ -0,0 +1,130 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+)
+
+// checkerboard returns an image of size x size black and white pixels
+func checkerboard(size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := uint8(0)
+			if (x+y)%2 == 0 {
+				c = 0xff
+			}
+			img.SetRGBA(x, y, color.RGBA{c, c, c, 0xff})
+		}
+	}
+	return img
+}
+
+// stripes returns an image of size x size black pixels with a vertical
+// white line every 8 pixels
+func stripes(size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := uint8(0)
+			if x%8 == 0 {
+				c = 0xff
+			}
+			img.SetRGBA(x, y, color.RGBA{c, c, c, 0xff})
+		}
+	}
+	return img
+}
+
+func TestGraphicContext_DrawImageScaledDown(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		filter  ImageFilter
+		mipmaps bool
+	}{
+		{"nearest neighbor with mipmaps", LinearFilter, true},
+		{"bilinear with mipmaps", BilinearFilter, true},
+		{"lanczos", LanczosFilter, false},
+		{"area", AreaFilter, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+			gc := NewGraphicContext(img)
+			gc.SetFilter(test.filter)
+			if !test.mipmaps {
+				gc.SetMipmapCache(nil)
+			}
+			gc.Scale(0.125, 0.125)
+			gc.DrawImage(stripes(128))
+			// the lines are averaged instead of missed or aliased
+			for _, p := range []image.Point{{4, 4}, {8, 8}, {11, 5}} {
+				if got := img.RGBAAt(p.X, p.Y); got.R < 0x18 || got.R > 0x28 || got.A != 0xff {
+					t.Errorf("got %v at %v, want dark gray", got, p)
+				}
+			}
+		})
+	}
+}
+
+func TestMipmapCache_Level(t *testing.T) {
+	cache := NewMipmapCache(2)
+	img := checkerboard(5)
+	level := cache.Level(img, 2)
+	if level.Rect != image.Rect(0, 0, 2, 2) {
+		t.Fatalf("got bounds %v, want 2 x 2", level.Rect)
+	}
+	if cache.Level(img, 2) != level {
+		t.Error("the mipmap is not reused")
+	}
+	if got := cache.Level(img, 1); got.Rect != image.Rect(0, 0, 3, 3) {
+		t.Errorf("got bounds %v, want 3 x 3", got.Rect)
+	}
+
+	cache.Remove(img)
+	if cache.Level(img, 2) == level {
+		t.Error("the removed mipmap is reused")
+	}
+	level = cache.Level(img, 2)
+	cache.Level(checkerboard(4), 1)
+	cache.Level(checkerboard(4), 1)
+	if cache.Level(img, 2) == level {
+		t.Error("the least recently used mipmap is not evicted")
+	}
+}
+
+func TestMipmapCache_Translated(t *testing.T) {
+	img := image.NewRGBA(image.Rect(10, 10, 14, 12))
+	for x := 10; x < 14; x++ {
+		img.SetRGBA(x, 10, color.RGBA{0xff, 0, 0, 0xff})
+	}
+	level := NewMipmapCache(0).Level(img, 1)
+	if got, want := level.RGBAAt(1, 0), (color.RGBA{0x80, 0, 0, 0x80}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGraphicContext_DrawImageRectMipmaps(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+	gc := NewGraphicContext(img)
+	cache := NewMipmapCache(1)
+	gc.SetMipmapCache(cache)
+	src := stripes(256)
+	gc.DrawImageScaled(src, 0, 0, draw2d.ImageScaling{Width: 16, Height: 16, ScalingPolicy: draw2d.ScalingFit})
+	level := cache.Level(src, 3)
+	// the right half of the image, scaled down by 8
+	gc.DrawImageRect(src, image.Rect(128, 0, 256, 128), [4]float64{16, 0, 32, 16})
+	if cache.Level(src, 3) != level || cache.order.Len() != 1 {
+		t.Error("the mipmaps of the image are not reused by DrawImageRect")
+	}
+	for _, p := range []image.Point{{4, 4}, {20, 8}, {28, 12}} {
+		if got := img.RGBAAt(p.X, p.Y); got.R < 0x18 || got.R > 0x28 || got.A != 0xff {
+			t.Errorf("got %v at %v, want dark gray", got, p)
+		}
+	}
+}
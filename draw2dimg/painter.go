@@ -0,0 +1,331 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/golang/freetype/raster"
+	"golang.org/x/image/draw"
+)
+
+// NewPainter returns a Painter drawing on img. *image.RGBA, *image.NRGBA,
+// *image.RGBA64, *image.NRGBA64, *image.Gray, *image.Gray16, *image.Alpha,
+// *image.Alpha16 and *image.Paletted images are painted directly on their
+// pixels, colors being mapped to the nearest color of the palette of
+// paletted images. Other images are painted through their At and Set
+// methods.
+func NewPainter(img draw.Image) Painter {
+	switch img := img.(type) {
+	case *image.RGBA:
+		return raster.NewRGBAPainter(img)
+	case *image.Paletted:
+		return NewPalettedPainter(img, false)
+	}
+	return newPixelPainter(img)
+}
+
+// newPixelPainter returns an ImagePainter drawing on the pixels of img
+func newPixelPainter(img draw.Image) *ImagePainter {
+	switch img := img.(type) {
+	case *image.RGBA:
+		return newImagePainter(img, func(i int) color.RGBA64 {
+			p := img.Pix[i : i+4 : i+4]
+			return color.RGBA64{uint16(p[0]) * 0x101, uint16(p[1]) * 0x101, uint16(p[2]) * 0x101, uint16(p[3]) * 0x101}
+		}, func(i int, c color.RGBA64) {
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = to8(c.R), to8(c.G), to8(c.B), to8(c.A)
+		}, 4, img.Stride)
+	case *image.NRGBA:
+		return newImagePainter(img, func(i int) color.RGBA64 {
+			p := img.Pix[i : i+4 : i+4]
+			return premultiply(uint32(p[0])*0x101, uint32(p[1])*0x101, uint32(p[2])*0x101, uint32(p[3])*0x101)
+		}, func(i int, c color.RGBA64) {
+			r, g, b, a := unpremultiply(c)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = to8(uint16(r)), to8(uint16(g)), to8(uint16(b)), to8(uint16(a))
+		}, 4, img.Stride)
+	case *image.RGBA64:
+		return newImagePainter(img, func(i int) color.RGBA64 {
+			p := img.Pix[i : i+8 : i+8]
+			return color.RGBA64{be16(p[0:]), be16(p[2:]), be16(p[4:]), be16(p[6:])}
+		}, func(i int, c color.RGBA64) {
+			p := img.Pix[i : i+8 : i+8]
+			putBe16(p[0:], c.R)
+			putBe16(p[2:], c.G)
+			putBe16(p[4:], c.B)
+			putBe16(p[6:], c.A)
+		}, 8, img.Stride)
+	case *image.NRGBA64:
+		return newImagePainter(img, func(i int) color.RGBA64 {
+			p := img.Pix[i : i+8 : i+8]
+			return premultiply(uint32(be16(p[0:])), uint32(be16(p[2:])), uint32(be16(p[4:])), uint32(be16(p[6:])))
+		}, func(i int, c color.RGBA64) {
+			p := img.Pix[i : i+8 : i+8]
+			r, g, b, a := unpremultiply(c)
+			putBe16(p[0:], uint16(r))
+			putBe16(p[2:], uint16(g))
+			putBe16(p[4:], uint16(b))
+			putBe16(p[6:], uint16(a))
+		}, 8, img.Stride)
+	case *image.Gray:
+		return newImagePainter(img, func(i int) color.RGBA64 {
+			y := uint16(img.Pix[i]) * 0x101
+			return color.RGBA64{y, y, y, 0xffff}
+		}, func(i int, c color.RGBA64) {
+			img.Pix[i] = to8(luminance(c))
+		}, 1, img.Stride)
+	case *image.Gray16:
+		return newImagePainter(img, func(i int) color.RGBA64 {
+			y := be16(img.Pix[i:])
+			return color.RGBA64{y, y, y, 0xffff}
+		}, func(i int, c color.RGBA64) {
+			putBe16(img.Pix[i:], luminance(c))
+		}, 2, img.Stride)
+	case *image.Alpha:
+		return newImagePainter(img, func(i int) color.RGBA64 {
+			a := uint16(img.Pix[i]) * 0x101
+			return color.RGBA64{a, a, a, a}
+		}, func(i int, c color.RGBA64) {
+			img.Pix[i] = to8(c.A)
+		}, 1, img.Stride)
+	case *image.Alpha16:
+		return newImagePainter(img, func(i int) color.RGBA64 {
+			a := be16(img.Pix[i:])
+			return color.RGBA64{a, a, a, a}
+		}, func(i int, c color.RGBA64) {
+			putBe16(img.Pix[i:], c.A)
+		}, 2, img.Stride)
+	}
+	// other images are read and written by the coordinates of their pixels
+	p := &ImagePainter{Op: draw.Over, image: img}
+	p.paint = func(x0, x1, y int, ma uint32) {
+		for x := x0; x < x1; x++ {
+			d := color.RGBA64Model.Convert(img.At(x, y)).(color.RGBA64)
+			img.Set(x, y, p.blend(d, ma))
+		}
+	}
+	return p
+}
+
+// ImagePainter is a Painter compositing the spans of the rasterizer on the
+// pixels of an image, with the premultiplied 16-bit color set by SetColor
+type ImagePainter struct {
+	// Op is draw.Over or draw.Src, the spans replace the pixels with Src
+	Op draw.Op
+	// Linear composites the colors in linear light instead of blending
+	// their sRGB encoded values
+	Linear bool
+	image  draw.Image
+	color  color.RGBA64
+	// linearColor is color in linear light
+	linearColor color.RGBA64
+	// paint composites the color on the pixels from x0 to x1 of the row y
+	// with the coverage ma
+	paint func(x0, x1, y int, ma uint32)
+}
+
+// newImagePainter returns a painter for an image of which the pixel at the
+// index i of the pixel data is read by get and written by set
+func newImagePainter(img draw.Image, get func(i int) color.RGBA64, set func(i int, c color.RGBA64), size, stride int) *ImagePainter {
+	p := &ImagePainter{Op: draw.Over, image: img}
+	min := img.Bounds().Min
+	p.paint = func(x0, x1, y int, ma uint32) {
+		i := (y-min.Y)*stride + (x0-min.X)*size
+		for x := x0; x < x1; x, i = x+1, i+size {
+			set(i, p.blend(get(i), ma))
+		}
+	}
+	return p
+}
+
+// SetColor sets the color to paint the spans
+func (p *ImagePainter) SetColor(c color.Color) {
+	p.color = color.RGBA64Model.Convert(c).(color.RGBA64)
+	p.linearColor = toLinear(p.color)
+}
+
+// blend composites the color of the painter with the coverage ma on d
+func (p *ImagePainter) blend(d color.RGBA64, ma uint32) color.RGBA64 {
+	if p.Linear {
+		// the painted and left pixels are not rounded through linear light
+		switch {
+		case ma == 0 && p.Op == draw.Over:
+			return d
+		case ma == 0xffff && (p.color.A == 0xffff || p.Op != draw.Over):
+			return p.color
+		}
+		return fromLinear(blend(toLinear(d), p.linearColor, ma, p.Op))
+	}
+	return blend(d, p.color, ma, p.Op)
+}
+
+// Paint satisfies the raster.Painter interface by painting ss on the image
+func (p *ImagePainter) Paint(ss []raster.Span, done bool) {
+	b := p.image.Bounds()
+	for _, s := range ss {
+		if s.Y < b.Min.Y {
+			continue
+		}
+		if s.Y >= b.Max.Y {
+			return
+		}
+		x0, x1 := max(s.X0, b.Min.X), min(s.X1, b.Max.X)
+		if x0 < x1 {
+			p.paint(x0, x1, s.Y, s.Alpha)
+		}
+	}
+}
+
+// aliasedPainter paints the spans covering at least half of their pixels
+// with a full coverage, and drops the others, for rendering without
+// antialiasing
+type aliasedPainter struct {
+	Painter
+}
+
+func (p aliasedPainter) Paint(ss []raster.Span, done bool) {
+	aliased := ss[:0]
+	for _, s := range ss {
+		if s.Alpha >= 0x8000 {
+			s.Alpha = 0xffff
+			aliased = append(aliased, s)
+		}
+	}
+	p.Painter.Paint(aliased, done)
+}
+
+// blend composites the color c with the coverage ma over d, or replaces d
+// with it for draw.Src, as the freetype RGBAPainter does
+func blend(d, c color.RGBA64, ma uint32, op draw.Op) color.RGBA64 {
+	const m = 1<<16 - 1
+	if op != draw.Over {
+		return color.RGBA64{
+			uint16(uint32(c.R) * ma / m), uint16(uint32(c.G) * ma / m),
+			uint16(uint32(c.B) * ma / m), uint16(uint32(c.A) * ma / m),
+		}
+	}
+	a := m - uint32(c.A)*ma/m
+	return color.RGBA64{
+		uint16((uint32(d.R)*a + uint32(c.R)*ma) / m), uint16((uint32(d.G)*a + uint32(c.G)*ma) / m),
+		uint16((uint32(d.B)*a + uint32(c.B)*ma) / m), uint16((uint32(d.A)*a + uint32(c.A)*ma) / m),
+	}
+}
+
+// premultiply returns the premultiplied color of the 16-bit non
+// premultiplied components
+func premultiply(r, g, b, a uint32) color.RGBA64 {
+	return color.RGBA64{uint16(r * a / 0xffff), uint16(g * a / 0xffff), uint16(b * a / 0xffff), uint16(a)}
+}
+
+// unpremultiply returns the 16-bit non premultiplied components of c
+func unpremultiply(c color.RGBA64) (r, g, b, a uint32) {
+	a = uint32(c.A)
+	if a == 0 {
+		return 0, 0, 0, 0
+	}
+	return uint32(c.R) * 0xffff / a, uint32(c.G) * 0xffff / a, uint32(c.B) * 0xffff / a, a
+}
+
+// luminance returns the 16-bit gray of c, as color.Gray16Model does
+func luminance(c color.RGBA64) uint16 {
+	return uint16((19595*uint32(c.R) + 38470*uint32(c.G) + 7471*uint32(c.B) + 1<<15) >> 16)
+}
+
+// to8 rounds the 16-bit component v to 8 bits
+func to8(v uint16) uint8 {
+	return uint8((uint32(v)*0xff + 0x7fff) / 0xffff)
+}
+
+func be16(p []uint8) uint16 {
+	return uint16(p[0])<<8 | uint16(p[1])
+}
+
+func putBe16(p []uint8, v uint16) {
+	p[0], p[1] = uint8(v>>8), uint8(v)
+}
+
+// PalettedPainter is a Painter drawing on a paletted image. Composited colors
+// are mapped to the nearest color of the palette, or dithered with an
+// ordered 4x4 Bayer matrix, which does not depend on the order in which the
+// spans are painted. The palette of the image must not change once painted.
+type PalettedPainter struct {
+	ImagePainter
+	// Dither spreads the error of the mapping of colors to the palette
+	Dither bool
+	image  *image.Paletted
+}
+
+// SetDithering sets whether the colors painted on a paletted image are
+// dithered with an ordered 4x4 Bayer matrix instead of being mapped to the
+// nearest color of the palette. It has no effect on other images, or on
+// the painters which are not PalettedPainters. The default is false.
+func (gc *GraphicContext) SetDithering(dither bool) {
+	for _, p := range []Painter{gc.painter, gc.linearPainter} {
+		if p, ok := p.(*PalettedPainter); ok {
+			p.Dither = dither
+		}
+	}
+}
+
+// bayer is the ordered dithering matrix, in sixteenths
+var bayer = [4][4]int32{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// NewPalettedPainter returns a painter for img, that dithers the colors if
+// dither is true
+func NewPalettedPainter(img *image.Paletted, dither bool) *PalettedPainter {
+	p := &PalettedPainter{
+		ImagePainter: ImagePainter{Op: draw.Over, image: img},
+		Dither:       dither,
+		image:        img,
+	}
+	p.paint = p.paintPaletted
+	return p
+}
+
+func (p *PalettedPainter) paintPaletted(x0, x1, y int, ma uint32) {
+	palette := p.image.Palette
+	if len(palette) == 0 {
+		return
+	}
+	// the distance between the colors of a regular palette of this size
+	spread := int32(0xffff)
+	if levels := math.Cbrt(float64(len(palette))); levels > 2 {
+		spread = int32(0xffff / (levels - 1))
+	}
+	// the coverage is the same along the span, the composited color and
+	// its nearest color only depend on the pixel, the indices of the
+	// nearest colors are cached by pixel, plus one, for the span
+	var indices [256]uint16
+	i := p.image.PixOffset(x0, y)
+	for x := x0; x < x1; x, i = x+1, i+1 {
+		pixel := p.image.Pix[i]
+		if !p.Dither && indices[pixel] != 0 {
+			p.image.Pix[i] = uint8(indices[pixel] - 1)
+			continue
+		}
+		var d color.RGBA64
+		if int(pixel) < len(palette) {
+			d = color.RGBA64Model.Convert(palette[pixel]).(color.RGBA64)
+		}
+		c := p.blend(d, ma)
+		if !p.Dither {
+			index := uint8(palette.Index(c))
+			indices[pixel] = uint16(index) + 1
+			p.image.Pix[i] = index
+			continue
+		}
+		// the components are premultiplied, they cannot exceed the alpha
+		offset := (bayer[y&3][x&3]*2 - 15) * spread / 32
+		dither := func(v uint16) uint16 {
+			return uint16(min(max(int32(v)+offset, 0), int32(c.A)))
+		}
+		p.image.Pix[i] = uint8(palette.Index(color.RGBA64{dither(c.R), dither(c.G), dither(c.B), c.A}))
+	}
+}
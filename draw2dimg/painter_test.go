@@ -0,0 +1,107 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"testing"
+
+	"github.com/llgcode/draw2d/draw2dkit"
+	"golang.org/x/image/draw"
+)
+
+// drawShapes clears img in white and draws an antialiased disc with a half
+// transparent red fill over a blue square
+func drawShapes(img draw.Image) {
+	gc := NewGraphicContext(img)
+	gc.SetFillColor(color.White)
+	gc.Clear()
+	gc.SetFillColor(color.NRGBA{0, 0, 0xff, 0xff})
+	draw2dkit.Rectangle(gc, 5, 5, 25, 25)
+	gc.Fill()
+	gc.SetFillColor(color.NRGBA{0xff, 0, 0, 0x80})
+	draw2dkit.Circle(gc, 20, 20, 12.5)
+	gc.Fill()
+}
+
+func TestNewPainter_ImageTypes(t *testing.T) {
+	bounds := image.Rect(0, 0, 40, 40)
+	want := image.NewRGBA(bounds)
+	drawShapes(want)
+	for _, img := range []draw.Image{
+		image.NewNRGBA(bounds),
+		image.NewRGBA64(bounds),
+		image.NewNRGBA64(bounds),
+		image.NewGray(bounds),
+		image.NewGray16(bounds),
+		image.NewAlpha(bounds),
+		image.NewAlpha16(bounds),
+		image.NewCMYK(bounds),
+	} {
+		drawShapes(img)
+		model := img.ColorModel()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				r0, g0, b0, a0 := model.Convert(want.At(x, y)).RGBA()
+				r1, g1, b1, a1 := img.At(x, y).RGBA()
+				for _, d := range []int{int(r0) - int(r1), int(g0) - int(g1), int(b0) - int(b1), int(a0) - int(a1)} {
+					if d < -0x300 || d > 0x300 {
+						t.Fatalf("%T: pixel %d, %d is %v, want %v", img, x, y, img.At(x, y), model.Convert(want.At(x, y)))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNewPalettedPainter(t *testing.T) {
+	bounds := image.Rect(0, 0, 40, 40)
+	gray := color.Gray{0x80}
+	for _, dither := range []bool{false, true} {
+		img := image.NewPaletted(bounds, color.Palette{color.Black, color.White})
+		gc := NewGraphicContext(img)
+		gc.SetDithering(dither)
+		gc.SetFillColor(gray)
+		draw2dkit.Rectangle(gc, 0, 0, 40, 40)
+		gc.Fill()
+		white := 0
+		for _, index := range img.Pix {
+			white += int(index)
+		}
+		// the nearest color of the gray is white, dithering mixes black and
+		// white pixels
+		if !dither && white != len(img.Pix) {
+			t.Errorf("expected only white pixels, got %d of %d", white, len(img.Pix))
+		}
+		if dither && (white < len(img.Pix)*3/8 || white > len(img.Pix)*5/8) {
+			t.Errorf("expected about half white pixels, got %d of %d", white, len(img.Pix))
+		}
+	}
+
+	// the dithered components of translucent colors do not exceed their
+	// alpha, the half transparent white is not dithered with white
+	img := image.NewPaletted(bounds, color.Palette{color.Transparent, color.RGBA{0x80, 0x80, 0x80, 0x80}, color.White})
+	gc := NewGraphicContext(img)
+	gc.SetDithering(true)
+	gc.SetFillColor(color.NRGBA{0xff, 0xff, 0xff, 0x80})
+	draw2dkit.Rectangle(gc, 0, 0, 40, 40)
+	gc.Fill()
+	for _, index := range img.Pix {
+		if index == 2 {
+			t.Fatal("expected no white pixels")
+		}
+	}
+
+	// colors of a larger palette are mapped to their nearest color
+	img = image.NewPaletted(bounds, palette.WebSafe)
+	gc = NewGraphicContext(img)
+	gc.SetFillColor(color.RGBA{0xff, 0x30, 0, 0xff})
+	draw2dkit.Rectangle(gc, 0, 0, 40, 40)
+	gc.Fill()
+	if c := img.At(20, 20); c != (color.RGBA{0xff, 0x33, 0, 0xff}) {
+		t.Errorf("unexpected color %v", c)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"github.com/golang/freetype/raster"
+	"github.com/llgcode/draw2d/draw2dbase"
+	"github.com/llgcode/draw2d/internal/floatraster"
+)
+
+// Rasterization selects the rasterizer converting the fills and strokes to
+// the coverage of pixels
+type Rasterization int
+
+const (
+	// FixedRasterization rasterizes with the rasterizer of freetype, of which
+	// the coordinates are 26.6 fixed point numbers. They are rounded to a
+	// 64th of pixel and overflow beyond about 16 million pixels.
+	FixedRasterization Rasterization = iota
+	// FloatRasterization rasterizes by accumulating the signed area of the
+	// lines in float64, as golang.org/x/image/vector does. Coordinates are
+	// not rounded and do not overflow, and sub paths are closed implicitly.
+	FloatRasterization
+)
+
+// SetRasterization sets the rasterizer of the fills and strokes. The
+// default is FixedRasterization.
+func (gc *GraphicContext) SetRasterization(r Rasterization) {
+	gc.rasterization = r
+	gc.resetRasterizers()
+}
+
+// resetRasterizers replaces the fill and stroke rasterizers by the ones of
+// the rasterization and the number of workers of gc
+func (gc *GraphicContext) resetRasterizers() {
+	gc.fillRasterizer = newRasterizer(gc.rasterization, gc.img.Bounds().Dx(), gc.img.Bounds().Dy(), gc.workers)
+	gc.strokeRasterizer = newRasterizer(gc.rasterization, gc.img.Bounds().Dx(), gc.img.Bounds().Dy(), gc.workers)
+}
+
+// rasterizer accumulates the lines of paths and paints their spans, it is
+// implemented by raster.Rasterizer, TiledRasterizer and
+// floatraster.Rasterizer
+type rasterizer interface {
+	Rasterize(p raster.Painter)
+	Clear()
+}
+
+// newRasterizer returns a rasterizer of kind r of an image of width x height
+// pixels, rasterizing on workers goroutines if more than one
+func newRasterizer(r Rasterization, width, height, workers int) rasterizer {
+	switch {
+	case r == FloatRasterization:
+		rasterizer := floatraster.NewRasterizer(width, height)
+		rasterizer.Workers = workers
+		return rasterizer
+	case workers > 1:
+		return NewTiledRasterizer(width, height, workers)
+	}
+	return raster.NewRasterizer(width, height)
+}
+
+// lineBuilder returns the flattener adding lines to r
+func lineBuilder(r rasterizer) draw2dbase.Flattener {
+	if flattener, ok := r.(draw2dbase.Flattener); ok {
+		return flattener
+	}
+	return FtLineBuilder{Adder: r.(raster.Adder)}
+}
+
+// setNonZeroWinding sets the fill rule of r
+func setNonZeroWinding(r rasterizer, nonZero bool) {
+	switch r := r.(type) {
+	case *raster.Rasterizer:
+		r.UseNonZeroWinding = nonZero
+	case *TiledRasterizer:
+		r.UseNonZeroWinding = nonZero
+	case *floatraster.Rasterizer:
+		r.UseNonZeroWinding = nonZero
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+func TestSetRasterization(t *testing.T) {
+	render := func(r Rasterization, workers int) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 160, 130))
+		gc := NewGraphicContext(img)
+		gc.SetParallelRendering(workers)
+		gc.SetRasterization(r)
+		gc.SetFillColor(color.NRGBA{0x20, 0x40, 0x80, 0xc0})
+		gc.SetStrokeColor(color.NRGBA{0xc0, 0x10, 0x10, 0xa0})
+		gc.SetLineWidth(3.3)
+		draw2dkit.Circle(gc, 50.3, 47.7, 41.2)
+		draw2dkit.Ellipse(gc, 90, 100, 70, 20.5)
+		gc.FillStroke()
+		return img
+	}
+	fixed, float := render(FixedRasterization, 1), render(FloatRasterization, 1)
+	for i := range fixed.Pix {
+		// the rasterizers only differ by the rounding of the coverage
+		if d := int(fixed.Pix[i]) - int(float.Pix[i]); d < -6 || d > 6 {
+			t.Fatalf("pixel (%d, %d): float rasterization %v too far from fixed rasterization %v",
+				i/4%160, i/4/160, float.Pix[i&^3:i&^3+4], fixed.Pix[i&^3:i&^3+4])
+		}
+	}
+	if !bytes.Equal(float.Pix, render(FloatRasterization, 3).Pix) {
+		t.Error("the parallel float rasterization should render the same image as the serial one")
+	}
+}
+
+func TestSetRasterization_Float(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	gc := NewGraphicContext(img)
+	gc.SetRasterization(FloatRasterization)
+	gc.SetFillColor(color.Black)
+	// coordinates overflowing the 26.6 fixed point numbers of freetype
+	draw2dkit.Rectangle(gc, -1e9, -1e9, 1e9, 1e9)
+	gc.Fill()
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0xff {
+			t.Fatalf("pixel (%d, %d) should be filled by a huge rectangle", i/4%20, i/4/20)
+		}
+	}
+
+	// sub paths are closed implicitly
+	img = image.NewRGBA(img.Rect)
+	gc = NewGraphicContext(img)
+	gc.SetRasterization(FloatRasterization)
+	gc.SetFillRule(draw2d.FillRuleWinding)
+	gc.SetFillColor(color.Black)
+	gc.MoveTo(2, 2)
+	gc.LineTo(18, 2)
+	gc.LineTo(18, 18)
+	gc.LineTo(2, 18)
+	gc.Fill()
+	for y := range 20 {
+		for x := range 20 {
+			want := x >= 2 && x < 18 && y >= 2 && y < 18
+			if got := img.RGBAAt(x, y).A == 0xff; got != want {
+				t.Fatalf("pixel (%d, %d): filled %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
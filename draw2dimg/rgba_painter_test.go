@@ -10,13 +10,11 @@ import (
 
 func TestNewGraphicContext_NRGBA(t *testing.T) {
 	// Create an NRGBA image (not RGBA)
-	// Note: NewGraphicContext currently only supports RGBA, so this test
-	// verifies that RGBA works correctly
-	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 100))
 	gc := NewGraphicContext(img)
 
 	if gc == nil {
-		t.Error("NewGraphicContext returned nil for RGBA image")
+		t.Error("NewGraphicContext returned nil for NRGBA image")
 	}
 
 	if gc.img == nil {
@@ -0,0 +1,144 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"slices"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
+)
+
+// drawShadowed draws with draw on a layer, as a group without shadow nor
+// mask, and composites the shadow of the layer and the layer, both masked by
+// the current mask. The layer only covers rect, the pixels in which draw
+// draws, which may be out of the image when the shadow is not.
+func (gc *GraphicContext) drawShadowed(rect image.Rectangle, draw func()) {
+	coverage := gc.currentMask()
+	shadowColor, currentMask, tr := gc.Current.ShadowColor, gc.Current.Mask, gc.Current.Tr
+	fillRasterizer, strokeRasterizer := gc.fillRasterizer, gc.strokeRasterizer
+	gc.Current.ShadowColor, gc.Current.Mask = color.Transparent, nil
+	// the layer is rasterized at the origin, then moved to rect
+	gc.pushLayer(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	gc.resetRasterizers()
+	gc.Current.Tr = draw2d.NewTranslationMatrix(float64(-rect.Min.X), float64(-rect.Min.Y))
+	gc.Current.Tr.Compose(tr)
+	draw()
+	layer := gc.img.(*image.RGBA)
+	layer.Rect = layer.Rect.Add(rect.Min)
+	gc.popGroup()
+	gc.fillRasterizer, gc.strokeRasterizer = fillRasterizer, strokeRasterizer
+	gc.Current.ShadowColor, gc.Current.Mask, gc.Current.Tr = shadowColor, currentMask, tr
+
+	bounds := alphaBounds(layer)
+	if bounds.Empty() {
+		return
+	}
+	blur := gc.Current.ShadowBlur
+	mask := image.NewAlpha16(bounds.Inset(-draw2dbase.ShadowMargin(blur)))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			mask.SetAlpha16(x, y, color.Alpha16{A: uint16(layer.Pix[layer.PixOffset(x, y)+3]) * 0x101})
+		}
+	}
+	shadow := draw2dbase.Shadow(mask, blur, shadowColor)
+	shadow.Rect = shadow.Rect.Add(gc.shadowOffset())
+	if coverage != nil {
+		applyMask(shadow, coverage)
+		applyMask(layer, coverage)
+	}
+	linear := gc.linearPainter != nil
+	compositeLayer(gc.img, shadow, 1, draw2d.BlendNormal, linear)
+	compositeLayer(gc.img, layer, 1, draw2d.BlendNormal, linear)
+}
+
+// shadowOffset returns the offset of the shadows, rounded to whole pixels
+func (gc *GraphicContext) shadowOffset() image.Point {
+	return image.Pt(int(math.Round(gc.Current.ShadowOffsetX)), int(math.Round(gc.Current.ShadowOffsetY)))
+}
+
+// shadowClip returns the pixels in which the shapes cast shadows on the
+// image: the image and the image moved against the shadow offset, with the
+// margin of the blur
+func (gc *GraphicContext) shadowClip() image.Rectangle {
+	b := gc.img.Bounds()
+	return b.Union(b.Sub(gc.shadowOffset())).Inset(-draw2dbase.ShadowMargin(gc.Current.ShadowBlur))
+}
+
+// alphaBounds returns the bounds of the pixels of img which are not
+// transparent
+func alphaBounds(img *image.RGBA) image.Rectangle {
+	x0, y0, x1, y1 := img.Rect.Max.X, img.Rect.Max.Y, img.Rect.Min.X, img.Rect.Min.Y
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(img.Rect.Min.X, y):]
+		for x := 0; x < img.Rect.Dx(); x++ {
+			if row[4*x+3] != 0 {
+				x0, x1 = min(x0, img.Rect.Min.X+x), max(x1, img.Rect.Min.X+x+1)
+				y0, y1 = min(y0, y), max(y1, y+1)
+			}
+		}
+	}
+	if x0 >= x1 {
+		// all the pixels are transparent
+		return image.Rectangle{}
+	}
+	return image.Rect(x0, y0, x1, y1)
+}
+
+// pathsRect returns the pixels casting shadows on the image in which paths
+// and the current path are drawn, widened by margin for their stroke
+func (gc *GraphicContext) pathsRect(paths []*draw2d.Path, margin float64) image.Rectangle {
+	x0, y0, x1, y1, ok := pathsBounds(slices.Concat(paths, []*draw2d.Path{gc.Current.Path}), gc.Current.Tr, margin)
+	if !ok {
+		return image.Rectangle{}
+	}
+	return gc.deviceRect(gc.shadowClip(), x0, y0, x1, y1)
+}
+
+// stringRect returns the pixels casting shadows on the image in which text
+// is drawn at x, y, widened by margin for its stroke
+func (gc *GraphicContext) stringRect(text string, x, y, margin float64) image.Rectangle {
+	left, top, right, bottom := gc.GetStringBounds(text)
+	x0, y0, x1, y1 := gc.Current.Tr.TransformRectangle(x+left-margin, y+top-margin, x+right+margin, y+bottom+margin)
+	return gc.deviceRect(gc.shadowClip(), x0, y0, x1, y1)
+}
+
+// stringOnPathRect returns the pixels casting shadows on the image in which
+// text is drawn along path, widened by margin for its stroke. The glyphs
+// are placed on the path, each one within the size of the text of its
+// position.
+func (gc *GraphicContext) stringOnPathRect(text string, path *draw2d.Path, margin float64) image.Rectangle {
+	x0, y0, x1, y1, ok := draw2dbase.PathsBounds([]*draw2d.Path{path})
+	if !ok {
+		return image.Rectangle{}
+	}
+	left, top, right, bottom := gc.GetStringBounds(text)
+	m := right - left + bottom - top + margin
+	x0, y0, x1, y1 = gc.Current.Tr.TransformRectangle(x0-m, y0-m, x1+m, y1+m)
+	return gc.deviceRect(gc.shadowClip(), x0, y0, x1, y1)
+}
+
+// strokeMargin returns the distance by which the strokes exceed the bounds
+// of the points of their paths
+func (gc *GraphicContext) strokeMargin() float64 {
+	return draw2dbase.StrokeMargin(gc.Current.LineWidth, gc.Current.Cap, gc.Current.Join)
+}
+
+// deviceRect returns the pixels of clip covering the rectangle x0, y0, x1,
+// y1 in device space, with a margin for the antialiasing and the alignment
+// of the glyphs
+func (gc *GraphicContext) deviceRect(clip image.Rectangle, x0, y0, x1, y1 float64) image.Rectangle {
+	const margin = 2
+	// clamped before being converted to integers
+	x0, y0 = math.Max(x0, float64(clip.Min.X-margin)), math.Max(y0, float64(clip.Min.Y-margin))
+	x1, y1 = math.Min(x1, float64(clip.Max.X+margin)), math.Min(y1, float64(clip.Max.Y+margin))
+	if !(x0 < x1 && y0 < y1) {
+		return image.Rectangle{}
+	}
+	r := image.Rect(int(math.Floor(x0))-margin, int(math.Floor(y0))-margin, int(math.Ceil(x1))+margin, int(math.Ceil(y1))+margin)
+	return r.Intersect(clip)
+}
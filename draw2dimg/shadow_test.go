@@ -0,0 +1,106 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+func TestGraphicContext_SetShadow(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	gc := NewGraphicContext(img)
+	gc.SetFillColor(color.White)
+	gc.Clear()
+	gc.SetShadow(5, 5, 0, color.Black)
+	// the offset is not transformed
+	gc.Scale(2, 2)
+	gc.SetFillColor(color.RGBA{0xff, 0, 0, 0xff})
+	draw2dkit.Rectangle(gc, 5, 5, 10, 10)
+	gc.Fill()
+
+	for _, test := range []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{12, 12, color.RGBA{0xff, 0, 0, 0xff}},
+		{22, 22, color.RGBA{0, 0, 0, 0xff}},
+		{17, 22, color.RGBA{0, 0, 0, 0xff}},
+		{22, 12, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{26, 26, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+	} {
+		if got := img.RGBAAt(test.x, test.y); got != test.want {
+			t.Errorf("pixel (%d, %d): got %v, want %v", test.x, test.y, got, test.want)
+		}
+	}
+
+	// blurred shadows fade out
+	img = image.NewRGBA(image.Rect(0, 0, 40, 40))
+	gc = NewGraphicContext(img)
+	gc.SetShadow(0, 0, 6, color.Black)
+	gc.SetFillColor(color.RGBA{0xff, 0, 0, 0xff})
+	draw2dkit.Rectangle(gc, 10, 10, 30, 30)
+	gc.Fill()
+	if got := img.RGBAAt(20, 20); got != (color.RGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("the shape should be drawn over its shadow, got %v", got)
+	}
+	previous := uint8(0xff)
+	for x := 9; x > 3; x-- {
+		a := img.RGBAAt(x, 20).A
+		if a == 0 || a >= previous {
+			t.Errorf("pixel (%d, 20): alpha %#x should be lower than the one on its right %#x", x, a, previous)
+		}
+		previous = a
+	}
+}
+
+func TestGraphicContext_SetShadowOutOfImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	gc := NewGraphicContext(img)
+	// the shape is out of the image, its shadow is not
+	gc.SetShadow(80, 0, 0, color.Black)
+	gc.SetFillColor(color.RGBA{0xff, 0, 0, 0xff})
+	draw2dkit.Rectangle(gc, -60, 10, -40, 30)
+	gc.Fill()
+	if got, want := img.RGBAAt(30, 20), (color.RGBA{0, 0, 0, 0xff}); got != want {
+		t.Errorf("pixel (30, 20): got %v, want %v", got, want)
+	}
+	if got, want := img.RGBAAt(10, 20), (color.RGBA{}); got != want {
+		t.Errorf("pixel (10, 20): got %v, want %v", got, want)
+	}
+}
+
+func TestAlphaBounds(t *testing.T) {
+	img := image.NewRGBA(image.Rect(10, 10, 30, 30))
+	if got := alphaBounds(img); !got.Empty() {
+		t.Errorf("got %v for a transparent image, want an empty rectangle", got)
+	}
+	img.SetRGBA(12, 20, color.RGBA{A: 1})
+	img.SetRGBA(25, 14, color.RGBA{A: 1})
+	if got, want := alphaBounds(img), image.Rect(12, 14, 26, 21); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGraphicContext_PathsRect(t *testing.T) {
+	gc := NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 100, 100)))
+	gc.Translate(10, 0)
+	draw2dkit.Rectangle(gc, 20, 20, 30, 40)
+	// the rectangle translated, with the margin of the antialiasing
+	if got, want := gc.pathsRect(nil, 0), image.Rect(28, 18, 42, 42); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// widened by the margin of the stroke
+	if got, want := gc.pathsRect(nil, 1), image.Rect(27, 17, 43, 43); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	gc.SetMatrixTransform(draw2d.NewTranslationMatrix(-200, 0))
+	if got := gc.pathsRect(nil, 0); !got.Empty() {
+		t.Errorf("got %v out of the image, want an empty rectangle", got)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
+	"golang.org/x/image/font"
+)
+
+func TestMeasureString_MatchesStringBounds(t *testing.T) {
+	gc := newTextContext(image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	gc.SetFontSize(18)
+	text := "Hello, Wg"
+	m := gc.MeasureString(text)
+	left, top, right, bottom := gc.GetStringBounds(text)
+	if m.Left != left || m.Top != top || m.Right != right || m.Bottom != bottom {
+		t.Errorf("MeasureString bounds %v %v %v %v, GetStringBounds %v %v %v %v",
+			m.Left, m.Top, m.Right, m.Bottom, left, top, right, bottom)
+	}
+	gc.BeginPath()
+	if width := gc.CreateStringPath(text, 0, 0); math.Abs(width-m.Width) > 1e-9 {
+		t.Errorf("MeasureString width %v, CreateStringPath width %v", m.Width, width)
+	}
+	if len(m.Glyphs) != len(text) || m.CapHeight <= m.XHeight || m.XHeight <= 0 {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+}
+
+func TestSetGlyphCache_Shared(t *testing.T) {
+	drawText := func(cache draw2dbase.GlyphCache) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 200, 40))
+		gc := newTextContext(img)
+		if cache != nil {
+			gc.SetGlyphCache(cache)
+		}
+		gc.SetFillColor(color.Black)
+		gc.SetFontSize(14)
+		gc.FillStringAt("Hello, shared cache", 5, 25)
+		return img
+	}
+	want := drawText(nil)
+
+	cache := draw2dbase.NewLRUGlyphCache(64)
+	images := make([]*image.RGBA, 4)
+	var wg sync.WaitGroup
+	for i := range images {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			images[i] = drawText(cache)
+		}(i)
+	}
+	wg.Wait()
+	for i, img := range images {
+		if !bytes.Equal(img.Pix, want.Pix) {
+			t.Errorf("image %d drawn with the shared cache differs", i)
+		}
+	}
+	if stats := cache.Stats(); stats.Hits == 0 || stats.Len == 0 {
+		t.Errorf("the shared cache should be used, got %+v", stats)
+	}
+}
+
+func TestFillStringAt_Underline(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 50))
+	gc := newTextContext(img)
+	gc.SetFillColor(color.Black)
+	gc.SetFontSize(20)
+	_, _, _, plainBottom := gc.GetStringBounds("ace")
+	gc.SetTextDecoration(draw2d.TextDecorationUnderline)
+	left, _, right, bottom := gc.GetStringBounds("ace")
+	width := gc.FillStringAt("ace", 10, 30)
+	if bottom <= plainBottom || left != 0 || right != width {
+		t.Errorf("underline should extend the string bounds, got %v %v %v", left, right, bottom)
+	}
+	// the underline is drawn across the whole advance below the baseline
+	m := draw2dbase.GetFontMetrics(gc.Current.Font, gc.Current.Scale)
+	y := 30 + int(m.UnderlinePosition+m.UnderlineThickness/2)
+	for x := 11; x < 10+int(width)-1; x++ {
+		if _, _, _, a := img.At(x, y).RGBA(); a == 0 {
+			t.Fatalf("pixel %d, %d of the underline is not drawn", x, y)
+		}
+	}
+}
+
+func TestFillStringAt_Vertical(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 50, 200))
+	gc := newTextContext(img)
+	gc.SetFillColor(color.Black)
+	gc.SetFontSize(20)
+	width := gc.MeasureString("Hello").Width
+	gc.SetWritingMode(draw2d.WritingModeVertical)
+	left, top, right, bottom := gc.GetStringBounds("Hello")
+	if bottom-top <= right-left || top < 0 || left >= 0 || right <= 0 {
+		t.Errorf("vertical bounds %v %v %v %v should be a column around x = 0", left, top, right, bottom)
+	}
+	if height := gc.FillStringAt("Hello", 25, 10); math.Abs(height-width) > 1e-9 {
+		t.Errorf("FillStringAt returned %v, want the width of the text %v", height, width)
+	}
+	// the column is drawn below the origin and nothing to its right
+	inked := func(x0, y0, x1, y1 int) bool {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				if _, _, _, a := img.At(x, y).RGBA(); a != 0 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if !inked(0, 10, 50, 10+int(width)) {
+		t.Error("the column is not drawn")
+	}
+	if inked(0, 0, 50, 9) || inked(26+int(right), 0, 50, 200) {
+		t.Error("the column is drawn outside of its bounds")
+	}
+}
+
+func TestSetHinting(t *testing.T) {
+	draw := func(x float64, hinting font.Hinting, subpixels int) *image.RGBA {
+		// each glyph is aligned, a single one is easier to compare
+		img := image.NewRGBA(image.Rect(0, 0, 100, 30))
+		gc := newTextContext(img)
+		gc.SetFillColor(color.Black)
+		gc.SetFontSize(9)
+		gc.SetHinting(hinting)
+		gc.SetSubpixelPositioning(subpixels)
+		gc.FillStringAt("e", x, 20.3)
+		return img
+	}
+	if hinted := draw(10, font.HintingFull, 0); bytes.Equal(hinted.Pix, draw(10, font.HintingNone, 0).Pix) {
+		t.Error("hinting should change the rendering of the text")
+	}
+	// hinted glyphs are drawn at whole pixel positions
+	if !bytes.Equal(draw(10.3, font.HintingFull, 0).Pix, draw(10, font.HintingFull, 0).Pix) {
+		t.Error("hinted text drawn at 10.3 and 10 should be the same")
+	}
+	// with 4 subpixel positions, 10.55 is rounded to 10.5
+	if !bytes.Equal(draw(10.55, font.HintingVertical, 4).Pix, draw(10.5, font.HintingVertical, 4).Pix) {
+		t.Error("text drawn at 10.55 and 10.5 should be the same")
+	}
+	if bytes.Equal(draw(10.5, font.HintingVertical, 4).Pix, draw(10, font.HintingVertical, 4).Pix) {
+		t.Error("text drawn at 10.5 and 10 should differ")
+	}
+}
+
+// testBitmapFont is an 'A' of 3x2 pixels in a font of 8 pixels per em
+const testBitmapFont = `STARTFONT 2.1
+FONTBOUNDINGBOX 4 6 0 -2
+PIXEL_SIZE 8
+FONT_ASCENT 6
+FONT_DESCENT 2
+STARTCHAR A
+ENCODING 65
+DWIDTH 5 0
+BBX 3 2 1 0
+BITMAP
+E0
+A0
+ENDCHAR
+ENDFONT
+`
+
+func TestFillStringAt_BitmapFont(t *testing.T) {
+	font, err := draw2d.ParseBDF([]byte(testBitmapFont))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	gc := NewGraphicContext(img)
+	cache := draw2d.NewFolderFontCache(t.TempDir())
+	fontData := draw2d.FontData{Name: "fixed"}
+	cache.StorePathFont(fontData, font)
+	gc.FontCache = cache
+	gc.SetFontData(fontData)
+	gc.SetDPI(72)
+	// two pixels per font pixel
+	gc.SetFontSize(16)
+	gc.SetFillColor(color.Black)
+
+	// the origin is moved to the pixel at 10, 21
+	if width := gc.FillStringAt("A", 10.3, 20.6); width != 10 {
+		t.Errorf("FillStringAt returned %v, want 10", width)
+	}
+	set := func(x, y int) bool {
+		if x < 12 || x >= 18 || y < 17 || y >= 21 {
+			return false
+		}
+		return y < 19 || x < 14 || x >= 16
+	}
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			want := color.RGBA{}
+			if set(x, y) {
+				want = color.RGBA{A: 0xff}
+			}
+			if got := img.RGBAAt(x, y); got != want {
+				t.Fatalf("pixel %d, %d is %v, want %v", x, y, got, want)
+			}
+		}
+	}
+	if left, top, right, bottom := gc.GetStringBounds("A"); left != 2 || top != -4 || right != 8 || bottom != 0 {
+		t.Errorf("unexpected bounds %v %v %v %v", left, top, right, bottom)
+	}
+}
+
+func TestFillStringAt_StrokeFont(t *testing.T) {
+	font, err := draw2d.ParseHershey([]byte("12345  1JZ\n12345  6MWRFRT RRYRZ\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 40, 50))
+	gc := NewGraphicContext(img)
+	cache := draw2d.NewFolderFontCache(t.TempDir())
+	fontData := draw2d.FontData{Name: "hershey"}
+	cache.StorePathFont(fontData, font)
+	gc.FontCache = cache
+	gc.SetFontData(fontData)
+	gc.SetDPI(72)
+	gc.SetFontSize(21)
+	gc.SetStrokeColor(color.Black)
+	gc.SetLineWidth(2)
+
+	// the open glyph paths are stroked even when filling
+	if width := gc.FillStringAt("!", 10, 40); width != 10 {
+		t.Errorf("FillStringAt returned %v, want 10", width)
+	}
+	if _, _, _, a := img.At(15, 25).RGBA(); a == 0 {
+		t.Error("the stem of the glyph should be drawn")
+	}
+	if _, _, _, a := img.At(20, 25).RGBA(); a != 0 {
+		t.Error("nothing should be drawn beside the glyph")
+	}
+	if m := gc.MeasureString("! !"); m.Width != 36 || m.Top != -21 || len(m.Glyphs) != 3 {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+)
+
+// inkBounds returns the bounds of the non transparent pixels of img
+func inkBounds(img *image.RGBA) image.Rectangle {
+	var r image.Rectangle
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.RGBAAt(x, y).A != 0 {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
+
+// newTextContext creates a GraphicContext loading fonts from the resource
+// folder, independently of the global font cache changed by other tests
+func newTextContext(img *image.RGBA) *GraphicContext {
+	gc := NewGraphicContext(img)
+	gc.FontCache = draw2d.NewFolderFontCache("../resource/font")
+	return gc
+}
+
+func TestFillStringOnPath_Vertical(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 200))
+	gc := newTextContext(img)
+	gc.SetFillColor(color.Black)
+	gc.SetFontSize(12)
+
+	// a vertical path going down: glyphs are rotated by 90°
+	path := &draw2d.Path{}
+	path.MoveTo(50, 10)
+	path.LineTo(50, 190)
+	width := gc.FillStringOnPath("Hello", path, 0)
+	if width <= 0 {
+		t.Fatalf("FillStringOnPath returned width %v", width)
+	}
+	ink := inkBounds(img)
+	if ink.Empty() {
+		t.Fatal("FillStringOnPath did not draw anything")
+	}
+	if ink.Dy() <= ink.Dx() {
+		t.Errorf("text along a vertical path should be taller than wide, got %v", ink)
+	}
+	if ink.Min.Y < 9 || float64(ink.Max.Y) > 10+width+2 {
+		t.Errorf("text drawn outside of the expected path section: %v", ink)
+	}
+}
+
+func TestFillStringOnPath_AnchorEnd(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 50))
+	gc := newTextContext(img)
+	gc.SetFillColor(color.Black)
+	gc.SetTextPathAnchor(draw2d.TextAnchorEnd)
+
+	path := &draw2d.Path{}
+	path.MoveTo(0, 30)
+	path.LineTo(200, 30)
+	gc.FillStringOnPath("Hello", path, 150)
+	ink := inkBounds(img)
+	if ink.Max.X > 152 || ink.Max.X < 140 {
+		t.Errorf("text anchored at its end should end near x=150, got %v", ink)
+	}
+}
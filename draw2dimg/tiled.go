@@ -0,0 +1,330 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/golang/freetype/raster"
+	"golang.org/x/image/math/fixed"
+)
+
+// SetParallelRendering sets the number of goroutines rasterizing and
+// compositing the fills and strokes, in horizontal tiles of the image. The
+// result is the same as the one of the serial rendering. Paths are rendered
+// serially if workers is 0 or 1, the default. The painter must support the
+// painting of different rows concurrently, as the painters returned by
+// NewPainter and NewLinearPainter do.
+func (gc *GraphicContext) SetParallelRendering(workers int) {
+	gc.workers = workers
+	gc.resetRasterizers()
+}
+
+// DefaultTileHeight is the height in pixels of the tiles of a
+// TiledRasterizer
+const DefaultTileHeight = 128
+
+// TiledRasterizer is a raster.Adder rasterizing the paths added to it in
+// horizontal tiles, on several goroutines. The curves are split in lines as
+// raster.Rasterizer splits them, and the lines are binned into the tiles
+// they cross. Each tile is rasterized by a raster.Rasterizer of its rows,
+// which is given the pieces of the lines in the rows, split at the same
+// points as raster.Rasterizer splits them, so that the spans are the same
+// as the ones of a raster.Rasterizer of the whole image.
+type TiledRasterizer struct {
+	// UseNonZeroWinding selects the non-zero winding fill rule instead of
+	// the even-odd one
+	UseNonZeroWinding bool
+	// TileHeight is the height of the tiles, DefaultTileHeight if zero
+	TileHeight int
+	// Workers is the number of goroutines rasterizing the tiles
+	Workers int
+
+	width, height int
+	// splitScale2 and splitScale3 set the number of lines in which the
+	// quadratic and cubic curves are split, as raster.Rasterizer does
+	splitScale2, splitScale3 int
+	// pen is the current point
+	pen   fixed.Point26_6
+	lines []tiledLine
+	// rasterizers are the rasterizers of the workers
+	rasterizers []*raster.Rasterizer
+}
+
+// tiledLine is a line from a to b
+type tiledLine struct {
+	a, b fixed.Point26_6
+}
+
+// rows returns the first and last rows of l, as raster.Rasterizer rounds
+// them, toward 0
+func (l tiledLine) rows() (top, bottom int) {
+	y0i, y1i := int(l.a.Y)/64, int(l.b.Y)/64
+	return min(y0i, y1i), max(y0i, y1i)
+}
+
+// NewTiledRasterizer returns a rasterizer of an image of width x height
+// pixels rasterizing its tiles on workers goroutines
+func NewTiledRasterizer(width, height, workers int) *TiledRasterizer {
+	// the heuristic of raster.Rasterizer.SetBounds
+	ss2, ss3 := 32, 16
+	if width > 24 || height > 24 {
+		ss2, ss3 = 2*ss2, 2*ss3
+		if width > 120 || height > 120 {
+			ss2, ss3 = 2*ss2, 2*ss3
+		}
+	}
+	return &TiledRasterizer{Workers: workers, width: width, height: height, splitScale2: ss2, splitScale3: ss3}
+}
+
+// Start starts a new curve at a
+func (r *TiledRasterizer) Start(a fixed.Point26_6) {
+	r.pen = a
+}
+
+// Add1 adds a linear segment to the current curve
+func (r *TiledRasterizer) Add1(b fixed.Point26_6) {
+	r.lines = append(r.lines, tiledLine{r.pen, b})
+	r.pen = b
+}
+
+// Add2 adds a quadratic segment to the current curve, split in lines as
+// raster.Rasterizer.Add2 splits it
+func (r *TiledRasterizer) Add2(b, c fixed.Point26_6) {
+	dev := maxAbs(r.pen.X-2*b.X+c.X, r.pen.Y-2*b.Y+c.Y) / fixed.Int26_6(r.splitScale2)
+	nsplit := 0
+	for dev > 0 {
+		dev /= 4
+		nsplit++
+	}
+	const maxNsplit = 16
+	if nsplit > maxNsplit {
+		panic("draw2dimg: Add2 nsplit too large: " + strconv.Itoa(nsplit))
+	}
+	var (
+		pStack [2*maxNsplit + 3]fixed.Point26_6
+		sStack [maxNsplit + 1]int
+		i      int
+	)
+	sStack[0] = nsplit
+	pStack[0], pStack[1], pStack[2] = c, b, r.pen
+	for i >= 0 {
+		s := sStack[i]
+		p := pStack[2*i:]
+		if s > 0 {
+			// split p[:3] in p[:3] and p[2:5]
+			mx := p[1].X
+			p[4].X = p[2].X
+			p[3].X = (p[4].X + mx) / 2
+			p[1].X = (p[0].X + mx) / 2
+			p[2].X = (p[1].X + p[3].X) / 2
+			my := p[1].Y
+			p[4].Y = p[2].Y
+			p[3].Y = (p[4].Y + my) / 2
+			p[1].Y = (p[0].Y + my) / 2
+			p[2].Y = (p[1].Y + p[3].Y) / 2
+			sStack[i] = s - 1
+			sStack[i+1] = s - 1
+			i++
+		} else {
+			midx := (p[0].X + 2*p[1].X + p[2].X) / 4
+			midy := (p[0].Y + 2*p[1].Y + p[2].Y) / 4
+			r.Add1(fixed.Point26_6{X: midx, Y: midy})
+			r.Add1(p[0])
+			i--
+		}
+	}
+}
+
+// Add3 adds a cubic segment to the current curve, split in lines as
+// raster.Rasterizer.Add3 splits it
+func (r *TiledRasterizer) Add3(b, c, d fixed.Point26_6) {
+	dev2 := maxAbs(r.pen.X-3*(b.X+c.X)+d.X, r.pen.Y-3*(b.Y+c.Y)+d.Y) / fixed.Int26_6(r.splitScale2)
+	dev3 := maxAbs(r.pen.X-2*b.X+d.X, r.pen.Y-2*b.Y+d.Y) / fixed.Int26_6(r.splitScale3)
+	nsplit := 0
+	for dev2 > 0 || dev3 > 0 {
+		dev2 /= 8
+		dev3 /= 4
+		nsplit++
+	}
+	const maxNsplit = 16
+	if nsplit > maxNsplit {
+		panic("draw2dimg: Add3 nsplit too large: " + strconv.Itoa(nsplit))
+	}
+	var (
+		pStack [3*maxNsplit + 4]fixed.Point26_6
+		sStack [maxNsplit + 1]int
+		i      int
+	)
+	sStack[0] = nsplit
+	pStack[0], pStack[1], pStack[2], pStack[3] = d, c, b, r.pen
+	for i >= 0 {
+		s := sStack[i]
+		p := pStack[3*i:]
+		if s > 0 {
+			// split p[:4] in p[:4] and p[3:7]
+			m01x := (p[0].X + p[1].X) / 2
+			m12x := (p[1].X + p[2].X) / 2
+			m23x := (p[2].X + p[3].X) / 2
+			p[6].X = p[3].X
+			p[5].X = m23x
+			p[1].X = m01x
+			p[2].X = (m01x + m12x) / 2
+			p[4].X = (m12x + m23x) / 2
+			p[3].X = (p[2].X + p[4].X) / 2
+			m01y := (p[0].Y + p[1].Y) / 2
+			m12y := (p[1].Y + p[2].Y) / 2
+			m23y := (p[2].Y + p[3].Y) / 2
+			p[6].Y = p[3].Y
+			p[5].Y = m23y
+			p[1].Y = m01y
+			p[2].Y = (m01y + m12y) / 2
+			p[4].Y = (m12y + m23y) / 2
+			p[3].Y = (p[2].Y + p[4].Y) / 2
+			sStack[i] = s - 1
+			sStack[i+1] = s - 1
+			i++
+		} else {
+			midx := (p[0].X + 3*(p[1].X+p[2].X) + p[3].X) / 8
+			midy := (p[0].Y + 3*(p[1].Y+p[2].Y) + p[3].Y) / 8
+			r.Add1(fixed.Point26_6{X: midx, Y: midy})
+			r.Add1(p[0])
+			i--
+		}
+	}
+}
+
+// maxAbs returns the maximum of abs(a) and abs(b)
+func maxAbs(a, b fixed.Int26_6) fixed.Int26_6 {
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+	return max(a, b)
+}
+
+// Clear cancels any previous calls to Start or AddXxx
+func (r *TiledRasterizer) Clear() {
+	r.pen = fixed.Point26_6{}
+	r.lines = r.lines[:0]
+}
+
+// Rasterize converts the accumulated curves into spans painted by p. The
+// tiles are painted concurrently, the spans of a tile being sorted by Y and
+// then X.
+func (r *TiledRasterizer) Rasterize(p raster.Painter) {
+	tileHeight := r.TileHeight
+	if tileHeight <= 0 {
+		tileHeight = DefaultTileHeight
+	}
+	// bin the lines in the tiles they cross
+	tiles := make([][]int, (r.height+tileHeight-1)/tileHeight)
+	for i, l := range r.lines {
+		top, bottom := l.rows()
+		if bottom < 0 {
+			continue
+		}
+		t0 := max(top, 0) / tileHeight
+		t1 := min(bottom/tileHeight, len(tiles)-1)
+		for t := t0; t <= t1; t++ {
+			tiles[t] = append(tiles[t], i)
+		}
+	}
+
+	workers := min(max(r.Workers, 1), len(tiles))
+	for len(r.rasterizers) < workers {
+		r.rasterizers = append(r.rasterizers, raster.NewRasterizer(r.width, tileHeight))
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func(rasterizer *raster.Rasterizer) {
+			defer wg.Done()
+			for t := range next {
+				r.rasterizeTile(rasterizer, p, t*tileHeight, min(tileHeight, r.height-t*tileHeight), tiles[t])
+			}
+		}(r.rasterizers[w])
+	}
+	for t, lines := range tiles {
+		if len(lines) > 0 {
+			next <- t
+		}
+	}
+	close(next)
+	wg.Wait()
+}
+
+// rasterizeTile paints with p the spans of the lines of the tile of height
+// rows starting at the row y
+func (r *TiledRasterizer) rasterizeTile(rasterizer *raster.Rasterizer, p raster.Painter, y, height int, lines []int) {
+	rasterizer.SetBounds(r.width, height)
+	rasterizer.UseNonZeroWinding = r.UseNonZeroWinding
+	rasterizer.Dy = y
+	for _, index := range lines {
+		addLinePieces(rasterizer, r.lines[index], y, y+height)
+	}
+	rasterizer.Rasterize(p)
+}
+
+// addLinePieces adds to rasterizer the pieces of the line l in the rows from
+// top to bottom, excluded, moved up by top. raster.Rasterizer.Add1 scans a
+// line row by row, the line crossing the edge between the rows j and j+1
+// from its first row at the x computed here in closed form. Each piece is
+// added as a line within its row, or ending on its edge, which the rasterizer
+// scans the same way.
+func addLinePieces(rasterizer *raster.Rasterizer, l tiledLine, top, bottom int) {
+	x0, y0, x1, y1 := l.a.X, l.a.Y, l.b.X, l.b.Y
+	dx, dy := x1-x0, y1-y0
+	y0i := int(y0) / 64
+	y0f := y0 - fixed.Int26_6(64*y0i)
+	y1i := int(y1) / 64
+	y1f := y1 - fixed.Int26_6(64*y1i)
+	piece := func(yi int, xa, ya, xb, yb fixed.Int26_6) {
+		offset := fixed.Int26_6(64 * (yi - top))
+		rasterizer.Start(fixed.Point26_6{X: xa, Y: offset + ya})
+		rasterizer.Add1(fixed.Point26_6{X: xb, Y: offset + yb})
+	}
+	if y0i == y1i {
+		if y0i >= top && y0i < bottom {
+			piece(y0i, x0, y0f, x1, y1f)
+		}
+		return
+	}
+	p, q := int64(64-y0f)*int64(dx), int64(dy)
+	var edge0, edge1 fixed.Int26_6 = 0, 64
+	yiDelta := 1
+	if dy < 0 {
+		p, q = int64(y0f)*int64(dx), -int64(dy)
+		edge0, edge1, yiDelta = 64, 0, -1
+	}
+	// crossing returns the x where the line leaves its row j
+	crossing := func(j int) fixed.Int26_6 {
+		n := p + int64(j)*64*int64(dx)
+		d := n / q
+		if n%q < 0 {
+			d--
+		}
+		return x0 + fixed.Int26_6(d)
+	}
+	last := (y1i - y0i) * yiDelta
+	j0, j1 := (top-y0i)*yiDelta, (bottom-1-y0i)*yiDelta
+	if j0 > j1 {
+		j0, j1 = j1, j0
+	}
+	for j := max(j0, 0); j <= min(j1, last); j++ {
+		xa, ya := x0, y0f
+		if j > 0 {
+			xa, ya = crossing(j-1), edge0
+		}
+		xb, yb := x1, y1f
+		if j < last {
+			xb, yb = crossing(j), edge1
+		}
+		piece(y0i+j*yiDelta, xa, ya, xb, yb)
+	}
+}
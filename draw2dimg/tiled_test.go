@@ -0,0 +1,68 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dimg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"math"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+// drawScene draws shapes crossing the tiles and the borders of img, with
+// tiles of tileHeight rows on workers goroutines
+func drawScene(img draw.Image, workers, tileHeight int) {
+	gc := NewGraphicContext(img)
+	gc.SetParallelRendering(workers)
+	if tiled, ok := gc.fillRasterizer.(*TiledRasterizer); ok {
+		tiled.TileHeight = tileHeight
+		gc.strokeRasterizer.(*TiledRasterizer).TileHeight = tileHeight
+	}
+	gc.SetFillColor(color.NRGBA{0x20, 0x40, 0x80, 0xc0})
+	gc.SetStrokeColor(color.NRGBA{0xc0, 0x10, 0x10, 0xa0})
+	gc.SetLineWidth(3.3)
+	draw2dkit.Circle(gc, 50.3, 47.7, 41.2)
+	draw2dkit.Ellipse(gc, 90, 100, 70, 20.5)
+	gc.FillStroke()
+
+	gc.SetFillRule(draw2d.FillRuleWinding)
+	gc.MoveTo(-20, 10)
+	for i := 1; i < 5; i++ {
+		angle := float64(i) * 4 * math.Pi / 5
+		gc.LineTo(60+80*math.Sin(angle), 70-80*math.Cos(angle))
+	}
+	gc.Close()
+	gc.Fill()
+
+	gc.SetLineDash([]float64{7, 3.5}, 1.2)
+	gc.Rotate(0.3)
+	draw2dkit.RoundedRectangle(gc, 20, -30, 170, 190, 15, 15)
+	gc.Stroke()
+}
+
+func TestTiledRasterizer(t *testing.T) {
+	for _, tileHeight := range []int{1, 7, 64, 500} {
+		serial := image.NewRGBA(image.Rect(0, 0, 160, 130))
+		drawScene(serial, 1, tileHeight)
+		tiled := image.NewRGBA(serial.Rect)
+		drawScene(tiled, 4, tileHeight)
+		if !bytes.Equal(serial.Pix, tiled.Pix) {
+			t.Errorf("tiles of %d rows should render the same image as the serial rasterizer", tileHeight)
+		}
+	}
+
+	serial := image.NewPaletted(image.Rect(0, 0, 160, 130), palette.Plan9)
+	drawScene(serial, 1, 0)
+	tiled := image.NewPaletted(serial.Rect, palette.Plan9)
+	drawScene(tiled, 3, 5)
+	if !bytes.Equal(serial.Pix, tiled.Pix) {
+		t.Error("paletted images should be painted concurrently the same way")
+	}
+}
@@ -1,7 +1,9 @@
 // Copyright 2010 The draw2d Authors. All rights reserved.
 // created: 13/12/2010 by Laurent Le Goff
 
-// Package draw2dkit provides helpers to draw common figures using a Path
+// Package draw2dkit provides helpers to draw common figures using a Path,
+// text: paragraphs mixing several styles, truncation and fitting in a box,
+// and images stretched as frames
 package draw2dkit
 
 import (
@@ -0,0 +1,51 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dkit
+
+import (
+	"image"
+
+	"github.com/llgcode/draw2d"
+)
+
+// NineSlice draws img stretched to the rectangle between (x1,y1) and
+// (x2,y2) as a frame. The image is sliced along the edges of its rectangle
+// center: the corners keep their size, the edges are stretched along the
+// sides of the rectangle and the center in both directions. The corners are
+// scaled down if the rectangle is smaller than them.
+func NineSlice(gc draw2d.GraphicContext, img image.Image, center image.Rectangle, x1, y1, x2, y2 float64) {
+	bounds := img.Bounds()
+	clamp := func(v, lo, hi int) int {
+		return min(max(v, lo), hi)
+	}
+	// the edges of the slices in img and in the rectangle
+	sx := [4]int{bounds.Min.X, clamp(center.Min.X, bounds.Min.X, bounds.Max.X), 0, bounds.Max.X}
+	sx[2] = clamp(center.Max.X, sx[1], bounds.Max.X)
+	sy := [4]int{bounds.Min.Y, clamp(center.Min.Y, bounds.Min.Y, bounds.Max.Y), 0, bounds.Max.Y}
+	sy[2] = clamp(center.Max.Y, sy[1], bounds.Max.Y)
+	dx, dy := sliceEdges(sx, x1, x2), sliceEdges(sy, y1, y2)
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			src := image.Rect(sx[i], sy[j], sx[i+1], sy[j+1])
+			if src.Empty() || dx[i] == dx[i+1] || dy[j] == dy[j+1] {
+				continue
+			}
+			gc.DrawImageRect(img, src, [4]float64{dx[i], dy[j], dx[i+1], dy[j+1]})
+		}
+	}
+}
+
+// sliceEdges returns the edges from v1 to v2 of the slices of which s are
+// the edges in the image
+func sliceEdges(s [4]int, v1, v2 float64) [4]float64 {
+	first, last := float64(s[1]-s[0]), float64(s[3]-s[2])
+	if size := v2 - v1; first+last > size {
+		scale := 0.0
+		if size > 0 {
+			scale = size / (first + last)
+		}
+		first, last = first*scale, last*scale
+	}
+	return [4]float64{v1, v1 + first, v2 - last, v2}
+}
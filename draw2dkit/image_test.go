@@ -0,0 +1,65 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dkit
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+func TestNineSlice(t *testing.T) {
+	// a frame of 12 x 12 pixels, with red corners of 4 x 4 pixels, green
+	// edges and a blue center
+	frame := image.NewRGBA(image.Rect(0, 0, 12, 12))
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 12; x++ {
+			corner, edge := (x < 4 || x >= 8) && (y < 4 || y >= 8), x < 4 || x >= 8 || y < 4 || y >= 8
+			switch {
+			case corner:
+				frame.SetRGBA(x, y, color.RGBA{0xff, 0, 0, 0xff})
+			case edge:
+				frame.SetRGBA(x, y, color.RGBA{0, 0xff, 0, 0xff})
+			default:
+				frame.SetRGBA(x, y, color.RGBA{0, 0, 0xff, 0xff})
+			}
+		}
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 100, 60))
+	gc := draw2dimg.NewGraphicContext(img)
+	gc.SetFilter(draw2dimg.LinearFilter)
+	NineSlice(gc, frame, image.Rect(4, 4, 8, 8), 0, 0, 100, 60)
+
+	for _, test := range []struct {
+		x, y int
+		want color.RGBA
+	}{
+		// the corners keep their size
+		{2, 2, color.RGBA{0xff, 0, 0, 0xff}},
+		{97, 57, color.RGBA{0xff, 0, 0, 0xff}},
+		{5, 2, color.RGBA{0, 0xff, 0, 0xff}},
+		// the edges are stretched along the sides
+		{50, 2, color.RGBA{0, 0xff, 0, 0xff}},
+		{2, 30, color.RGBA{0, 0xff, 0, 0xff}},
+		{50, 30, color.RGBA{0, 0, 0xff, 0xff}},
+	} {
+		if got := img.RGBAAt(test.x, test.y); got != test.want {
+			t.Errorf("pixel (%d, %d): got %v, want %v", test.x, test.y, got, test.want)
+		}
+	}
+
+	// the corners are scaled down in a rectangle smaller than them
+	img = image.NewRGBA(image.Rect(0, 0, 100, 60))
+	gc = draw2dimg.NewGraphicContext(img)
+	gc.SetFilter(draw2dimg.LinearFilter)
+	NineSlice(gc, frame, image.Rect(4, 4, 8, 8), 0, 0, 4, 40)
+	if got := img.RGBAAt(3, 1); got != (color.RGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("got %v, want the scaled down corner", got)
+	}
+	if got := img.RGBAAt(5, 1); got != (color.RGBA{}) {
+		t.Errorf("got %v outside of the rectangle, want transparent", got)
+	}
+}
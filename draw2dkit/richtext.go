@@ -0,0 +1,289 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dkit
+
+import (
+	"image/color"
+	"math"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/llgcode/draw2d"
+)
+
+// Span is a piece of text of a Paragraph drawn with its own style. The zero
+// values of the style keep the font, size and fill color of the graphic
+// context.
+type Span struct {
+	Text string
+	// FontData is the font of the span. If its Name is empty, the name of
+	// the current font is used with the family, style and weight of the span.
+	FontData draw2d.FontData
+	// FontSize is the size of the font in points
+	FontSize float64
+	// Color is the fill color of the text
+	Color color.Color
+	// Decoration is the lines drawn with the text
+	Decoration draw2d.TextDecoration
+}
+
+// apply sets the style of the span on gc
+func (span Span) apply(gc draw2d.GraphicContext) {
+	if span.FontData != (draw2d.FontData{}) {
+		fontData := span.FontData
+		if fontData.Name == "" {
+			fontData.Name = gc.GetFontData().Name
+		}
+		gc.SetFontData(fontData)
+	}
+	if span.FontSize > 0 {
+		gc.SetFontSize(span.FontSize)
+	}
+	if span.Color != nil {
+		gc.SetFillColor(span.Color)
+	}
+	gc.SetTextDecoration(span.Decoration)
+	gc.SetWritingMode(draw2d.WritingModeHorizontal)
+}
+
+// measure returns the metrics of text drawn with the style of the span
+func (span Span) measure(gc draw2d.GraphicContext, text string) draw2d.TextMetrics {
+	gc.Save()
+	defer gc.Restore()
+	span.apply(gc)
+	return gc.MeasureString(text)
+}
+
+// Paragraph is a text made of spans of different styles, laid out on lines
+// sharing their baseline. Lines are broken at the newlines of the text and
+// wrapped between words to fit Width.
+type Paragraph struct {
+	Spans []Span
+	// Width is the width lines are wrapped at, they are not wrapped if zero
+	Width float64
+	// Halign aligns the lines within Width, or within the widest line if
+	// Width is zero
+	Halign draw2d.Halign
+	// LineSpacing multiplies the height of the lines, 1 if zero
+	LineSpacing float64
+}
+
+// TextLayout is the position of the spans of a Paragraph
+type TextLayout struct {
+	Lines []TextLine
+	// Width is the width of the widest line
+	Width float64
+	// Height is the sum of the heights of the lines
+	Height float64
+}
+
+// TextLine is a line of a TextLayout
+type TextLine struct {
+	// Spans are the pieces of the spans of the paragraph on the line. Their
+	// Text is the part of the span drawn on the line.
+	Spans []PlacedSpan
+	// Baseline is the vertical position of the baseline from the top of
+	// the paragraph
+	Baseline float64
+	// Width is the advance of the line, without its trailing spaces
+	Width float64
+	// Ascent, Descent and LineGap are the largest of the fonts of the line
+	Ascent, Descent, LineGap float64
+}
+
+// PlacedSpan is a span at a horizontal position from the left of the
+// paragraph
+type PlacedSpan struct {
+	Span
+	X float64
+}
+
+// word is a word, a run of spaces or a newline of a span
+type word struct {
+	span  int
+	text  string
+	width float64
+}
+
+func (w word) space() bool {
+	r, _ := utf8.DecodeRuneInString(w.text)
+	return r != '\n' && unicode.IsSpace(r)
+}
+
+func (w word) newline() bool {
+	return w.text == "\n"
+}
+
+// splitWords splits text in words, runs of spaces and newlines
+func splitWords(text string) []string {
+	var words []string
+	start := 0
+	kind := func(r rune) int {
+		switch {
+		case r == '\n':
+			return 0
+		case unicode.IsSpace(r):
+			return 1
+		}
+		return 2
+	}
+	for i, r := range text {
+		if i == start {
+			continue
+		}
+		previous, _ := utf8.DecodeLastRuneInString(text[:i])
+		if r == '\n' || previous == '\n' || kind(r) != kind(previous) {
+			words = append(words, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+// Layout breaks the paragraph in lines and computes the position of the
+// spans with the fonts of gc
+func (p Paragraph) Layout(gc draw2d.GraphicContext) TextLayout {
+	var words []word
+	for i, span := range p.Spans {
+		for _, text := range splitWords(span.Text) {
+			w := word{span: i, text: text}
+			if !w.newline() {
+				w.width = span.measure(gc, text).Width
+			}
+			words = append(words, w)
+		}
+	}
+
+	var (
+		layout TextLayout
+		line   []word
+		spaces []word
+		width  float64
+	)
+	for i := 0; i < len(words); {
+		w := words[i]
+		switch {
+		case w.newline():
+			layout.addLine(gc, p, line, w.span)
+			line, spaces, width = nil, nil, 0
+			i++
+			continue
+		case w.space():
+			spaces = append(spaces, w)
+			i++
+			continue
+		}
+		// the words of consecutive spans are not separated
+		end := i
+		wordWidth := 0.0
+		for end < len(words) && !words[end].space() && !words[end].newline() {
+			wordWidth += words[end].width
+			end++
+		}
+		spacesWidth := 0.0
+		for _, s := range spaces {
+			spacesWidth += s.width
+		}
+		if p.Width > 0 && len(line) > 0 && width+spacesWidth+wordWidth > p.Width {
+			layout.addLine(gc, p, line, line[len(line)-1].span)
+			line, width = nil, 0
+		} else {
+			line = append(line, spaces...)
+			width += spacesWidth
+		}
+		line = append(line, words[i:end]...)
+		width += wordWidth
+		spaces = nil
+		i = end
+	}
+	// the spaces ending a line are dropped
+	span := len(p.Spans) - 1
+	if len(line) > 0 {
+		span = line[len(line)-1].span
+	}
+	layout.addLine(gc, p, line, span)
+
+	for i := range layout.Lines {
+		line := &layout.Lines[i]
+		boxWidth := p.Width
+		if boxWidth <= 0 {
+			boxWidth = layout.Width
+		}
+		offset := 0.0
+		switch p.Halign {
+		case draw2d.HalignCenter:
+			offset = (boxWidth - line.Width) / 2
+		case draw2d.HalignRight:
+			offset = boxWidth - line.Width
+		}
+		for j := range line.Spans {
+			line.Spans[j].X += offset
+		}
+	}
+	return layout
+}
+
+// addLine appends a line of words, merged in spans, below the lines of the
+// layout. The metrics of an empty line are those of the span at index span.
+func (layout *TextLayout) addLine(gc draw2d.GraphicContext, p Paragraph, words []word, span int) {
+	var line TextLine
+	last := -1
+	for _, w := range words {
+		if w.span == last {
+			line.Spans[len(line.Spans)-1].Text += w.text
+			continue
+		}
+		placed := PlacedSpan{Span: p.Spans[w.span]}
+		placed.Text = w.text
+		line.Spans = append(line.Spans, placed)
+		last = w.span
+	}
+	metrics := func(s Span) {
+		m := s.measure(gc, "")
+		line.Ascent = math.Max(line.Ascent, m.Ascent)
+		line.Descent = math.Max(line.Descent, m.Descent)
+		line.LineGap = math.Max(line.LineGap, m.LineGap)
+	}
+	if len(line.Spans) == 0 && span >= 0 {
+		metrics(p.Spans[span])
+	}
+	for i := range line.Spans {
+		placed := &line.Spans[i]
+		metrics(placed.Span)
+		placed.X = line.Width
+		line.Width += placed.measure(gc, placed.Text).Width
+	}
+	spacing := p.LineSpacing
+	if spacing <= 0 {
+		spacing = 1
+	}
+	line.Baseline = layout.Height + line.Ascent
+	layout.Height += (line.Ascent + line.Descent + line.LineGap) * spacing
+	layout.Width = math.Max(layout.Width, line.Width)
+	layout.Lines = append(layout.Lines, line)
+}
+
+// Fill draws the lines of the layout with the top left corner of the
+// paragraph at x, y
+func (layout TextLayout) Fill(gc draw2d.GraphicContext, x, y float64) {
+	for _, line := range layout.Lines {
+		for _, placed := range line.Spans {
+			gc.Save()
+			placed.apply(gc)
+			gc.FillStringAt(placed.Text, x+placed.X, y+line.Baseline)
+			gc.Restore()
+		}
+	}
+}
+
+// Fill lays out the paragraph and draws it with its top left corner at x,
+// y. It returns the height of the paragraph.
+func (p Paragraph) Fill(gc draw2d.GraphicContext, x, y float64) (height float64) {
+	layout := p.Layout(gc)
+	layout.Fill(gc, x, y)
+	return layout.Height
+}
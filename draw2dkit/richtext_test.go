@@ -0,0 +1,116 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dkit
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+func newTextGC(img *image.RGBA) *draw2dimg.GraphicContext {
+	gc := draw2dimg.NewGraphicContext(img)
+	gc.FontCache = draw2d.NewFolderFontCache("../resource/font")
+	gc.SetFontData(draw2d.FontData{Name: "luxi", Family: draw2d.FontFamilySans})
+	gc.SetFontSize(12)
+	return gc
+}
+
+var bold = draw2d.FontData{Family: draw2d.FontFamilySans, Style: draw2d.FontStyleBold}
+
+func TestParagraph_Layout(t *testing.T) {
+	gc := newTextGC(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	p := Paragraph{Spans: []Span{
+		{Text: "Hello "},
+		{Text: "bold", FontData: bold},
+		{Text: "er world"},
+	}}
+	layout := p.Layout(gc)
+	if len(layout.Lines) != 1 || len(layout.Lines[0].Spans) != 3 {
+		t.Fatalf("expected a line of 3 spans, got %+v", layout.Lines)
+	}
+	line := layout.Lines[0]
+	if x := gc.MeasureString("Hello ").Width; math.Abs(line.Spans[1].X-x) > 1e-9 {
+		t.Errorf("the bold span should start at %v, got %v", x, line.Spans[1].X)
+	}
+	if line.Baseline != line.Ascent || layout.Height <= line.Ascent || layout.Width != line.Width {
+		t.Errorf("unexpected layout %+v", layout)
+	}
+
+	// "bolder" is a single word, the line is wrapped before "world"
+	p.Width = line.Spans[2].X + gc.MeasureString("er").Width + 1
+	layout = p.Layout(gc)
+	if len(layout.Lines) != 2 {
+		t.Fatalf("expected 2 lines, got %+v", layout.Lines)
+	}
+	first, second := layout.Lines[0], layout.Lines[1]
+	if first.Spans[2].Text != "er" || first.Width > p.Width {
+		t.Errorf("unexpected first line %+v", first)
+	}
+	if len(second.Spans) != 1 || second.Spans[0].Text != "world" || second.Spans[0].X != 0 {
+		t.Errorf("unexpected second line %+v", second)
+	}
+	if second.Baseline <= first.Baseline+first.Descent {
+		t.Errorf("the second line should be below the first one: %v, %v", first.Baseline, second.Baseline)
+	}
+
+	p.Halign = draw2d.HalignRight
+	layout = p.Layout(gc)
+	if second := layout.Lines[1]; math.Abs(second.Spans[0].X+second.Width-p.Width) > 1e-9 {
+		t.Errorf("the second line should end at %v, got %+v", p.Width, second)
+	}
+}
+
+func TestParagraph_SharedBaseline(t *testing.T) {
+	gc := newTextGC(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	small := Paragraph{Spans: []Span{{Text: "small"}}}.Layout(gc)
+	mixed := Paragraph{Spans: []Span{{Text: "small "}, {Text: "BIG", FontSize: 24}}}.Layout(gc)
+	if len(mixed.Lines) != 1 || mixed.Lines[0].Ascent <= small.Lines[0].Ascent {
+		t.Errorf("the line should have the ascent of its largest font: %+v", mixed.Lines)
+	}
+	if mixed.Height <= small.Height {
+		t.Errorf("the line should be higher with a larger font: %v, %v", mixed.Height, small.Height)
+	}
+
+	newlines := Paragraph{Spans: []Span{{Text: "a\n\nb"}}}.Layout(gc)
+	if len(newlines.Lines) != 3 || len(newlines.Lines[1].Spans) != 0 || math.Abs(newlines.Height-3*small.Height) > 1e-9 {
+		t.Errorf("expected 3 lines with an empty one, got %+v", newlines)
+	}
+}
+
+func TestParagraph_Fill(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 60))
+	gc := newTextGC(img)
+	gc.SetFillColor(color.Black)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	height := Paragraph{Spans: []Span{
+		{Text: "plain "},
+		{Text: "keyword", FontData: bold, Color: red, Decoration: draw2d.TextDecorationUnderline},
+		{Text: " text"},
+	}, Width: 190}.Fill(gc, 5, 5)
+	if height <= 0 {
+		t.Errorf("Fill returned %v", height)
+	}
+	var black, colored int
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 200; x++ {
+			switch img.RGBAAt(x, y) {
+			case color.RGBA{A: 0xff}:
+				black++
+			case red:
+				colored++
+			}
+		}
+	}
+	if black == 0 || colored == 0 {
+		t.Errorf("expected black and red text, got %d and %d pixels", black, colored)
+	}
+	if c := gc.Current.FillColor; c != color.Color(color.Black) {
+		t.Errorf("the fill color of the context should be restored, got %v", c)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dkit
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/llgcode/draw2d"
+)
+
+// Ellipsis is the part of a text replaced by an ellipsis when it is truncated
+type Ellipsis int
+
+const (
+	// EllipsisEnd keeps the start of the text
+	EllipsisEnd Ellipsis = iota
+	// EllipsisStart keeps the end of the text
+	EllipsisStart
+	// EllipsisMiddle keeps the start and the end of the text
+	EllipsisMiddle
+)
+
+// EllipsisText is the text drawn in place of the runes removed by Truncate
+var EllipsisText = "…"
+
+// StringWidth returns the width of the ink of text drawn with the current
+// font of gc, from the origin of the text or from the left of the ink if it
+// starts before the origin
+func StringWidth(gc draw2d.GraphicContext, text string) float64 {
+	left, _, right, _ := gc.GetStringBounds(text)
+	return math.Max(right, 0) - math.Min(left, 0)
+}
+
+// Truncate returns text shortened to fit width with the current font of gc,
+// replacing the runes removed at the position of ellipsis by EllipsisText.
+// Text that fits is returned unchanged, and an empty string if not even the
+// ellipsis fits. Combining marks are kept with their base character.
+func Truncate(gc draw2d.GraphicContext, text string, width float64, ellipsis Ellipsis) string {
+	if StringWidth(gc, text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	// shorten returns the text keeping n runes
+	shorten := func(n int) string {
+		var head, tail []rune
+		switch ellipsis {
+		case EllipsisStart:
+			tail = runes[len(runes)-n:]
+		case EllipsisMiddle:
+			head, tail = runes[:(n+1)/2], runes[len(runes)-n/2:]
+		default:
+			head = runes[:n]
+		}
+		for len(head) > 0 && len(head) < len(runes) && unicode.Is(unicode.Mn, runes[len(head)]) {
+			head = head[:len(head)-1]
+		}
+		for len(tail) > 0 && unicode.Is(unicode.Mn, tail[0]) {
+			tail = tail[1:]
+		}
+		return strings.TrimRightFunc(string(head), unicode.IsSpace) + EllipsisText +
+			strings.TrimLeftFunc(string(tail), unicode.IsSpace)
+	}
+	// the number of runes kept is the largest that fits
+	n := sort.Search(len(runes), func(n int) bool {
+		return StringWidth(gc, shorten(n+1)) > width
+	})
+	if n == 0 && StringWidth(gc, EllipsisText) > width {
+		return ""
+	}
+	return shorten(n)
+}
+
+// FitFontSize returns the largest font size at which the ink of text drawn
+// with the current font of gc fits a box of width x height. The height is
+// not constrained if it is zero. The font size of gc is left unchanged. It
+// returns 0 for a text without ink.
+func FitFontSize(gc draw2d.GraphicContext, text string, width, height float64) float64 {
+	size := gc.GetFontSize()
+	defer gc.SetFontSize(size)
+	fits := func(size float64) (scale float64) {
+		gc.SetFontSize(size)
+		left, top, right, bottom := gc.GetStringBounds(text)
+		w := math.Max(right, 0) - math.Min(left, 0)
+		scale = math.Inf(1)
+		if w > 0 {
+			scale = width / w
+		}
+		if h := bottom - top; height > 0 && h > 0 {
+			scale = math.Min(scale, height/h)
+		}
+		return scale
+	}
+	scale := fits(size)
+	if math.IsInf(scale, 1) {
+		return 0
+	}
+	// the bounds are proportional to the size, but for the rounding of
+	// hinted glyphs
+	fitted := size * scale
+	for i := 0; i < 20 && fits(fitted) < 1; i++ {
+		fitted *= 0.99
+	}
+	return fitted
+}
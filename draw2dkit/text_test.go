@@ -0,0 +1,72 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dkit
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	gc := newTextGC(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	text := "The quick brown fox jumps over the lazy dog"
+	if got := Truncate(gc, text, 1000, EllipsisEnd); got != text {
+		t.Errorf("a text that fits should be unchanged, got %q", got)
+	}
+	width := StringWidth(gc, text) / 2
+	for _, test := range []struct {
+		ellipsis Ellipsis
+		check    func(s string) bool
+	}{
+		{EllipsisEnd, func(s string) bool { return strings.HasPrefix(s, "The quick") && strings.HasSuffix(s, EllipsisText) }},
+		{EllipsisStart, func(s string) bool { return strings.HasPrefix(s, EllipsisText) && strings.HasSuffix(s, "lazy dog") }},
+		{EllipsisMiddle, func(s string) bool {
+			return strings.HasPrefix(s, "The") && strings.HasSuffix(s, "dog") && strings.Contains(s, EllipsisText)
+		}},
+	} {
+		got := Truncate(gc, text, width, test.ellipsis)
+		if !test.check(got) || StringWidth(gc, got) > width {
+			t.Errorf("unexpected truncation %q for ellipsis %d", got, test.ellipsis)
+		}
+		// one more rune would not fit
+		if longer := Truncate(gc, text, width+gc.MeasureString("m").Width, test.ellipsis); len([]rune(longer)) <= len([]rune(got)) {
+			t.Errorf("%q should be longer than %q", longer, got)
+		}
+	}
+	if got := Truncate(gc, text, 1, EllipsisEnd); got != "" {
+		t.Errorf("expected an empty string, got %q", got)
+	}
+	// the combining acute accent stays with its e
+	accented := "au lait cafe\u0301"
+	for width := 1.0; width < StringWidth(gc, accented); width++ {
+		if got := Truncate(gc, accented, width, EllipsisStart); strings.HasPrefix(got, EllipsisText+"\u0301") {
+			t.Errorf("the accent was separated from its base: %q", got)
+		}
+	}
+}
+
+func TestFitFontSize(t *testing.T) {
+	gc := newTextGC(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	text := "Axis label"
+	size := FitFontSize(gc, text, 200, 0)
+	if gc.GetFontSize() != 12 {
+		t.Errorf("the font size should be restored, got %v", gc.GetFontSize())
+	}
+	gc.SetFontSize(size)
+	if w := StringWidth(gc, text); w > 200 || w < 190 {
+		t.Errorf("the text should nearly fill 200 at size %v, got %v", size, w)
+	}
+	if constrained := FitFontSize(gc, text, 200, 10); constrained >= size {
+		t.Errorf("the height should limit the size: %v, %v", constrained, size)
+	} else {
+		gc.SetFontSize(constrained)
+		if _, top, _, bottom := gc.GetStringBounds(text); bottom-top > 10 {
+			t.Errorf("the text is %v high at size %v", bottom-top, constrained)
+		}
+	}
+	if size := FitFontSize(gc, "", 100, 100); size != 0 {
+		t.Errorf("expected 0 for an empty text, got %v", size)
+	}
+}
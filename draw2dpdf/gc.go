@@ -83,11 +83,15 @@ type GraphicContext struct {
 	*draw2dbase.StackGraphicContext
 	pdf *gofpdf.Fpdf
 	DPI int
+	// group is the current group, nil if there is no group
+	group *group
+	// maskImages are the names of the images of the image masks
+	maskImages map[*draw2dbase.Mask]string
 }
 
 // NewGraphicContext creates a new pdf GraphicContext
 func NewGraphicContext(pdf *gofpdf.Fpdf) *GraphicContext {
-	gc := &GraphicContext{draw2dbase.NewStackGraphicContext(), pdf, DPI}
+	gc := &GraphicContext{draw2dbase.NewStackGraphicContext(), pdf, DPI, nil, nil}
 	gc.SetDPI(DPI)
 	return gc
 }
@@ -95,16 +99,43 @@ func NewGraphicContext(pdf *gofpdf.Fpdf) *GraphicContext {
 // DrawImage draws an image as PNG
 // TODO: add type (tp) as parameter to argument list?
 func (gc *GraphicContext) DrawImage(image image.Image) {
+	bounds := image.Bounds()
+	x0, y0 := float64(bounds.Min.X), float64(bounds.Min.Y)
+	x1, y1 := float64(bounds.Max.X), float64(bounds.Max.Y)
+	gc.DrawImageRect(image, bounds, [4]float64{x0, y0, x1, y1})
+}
+
+// DrawImageScaled draws the raster image at x, y, scaled and aligned as
+// specified by scaling
+func (gc *GraphicContext) DrawImageScaled(img image.Image, x, y float64, scaling draw2d.ImageScaling) {
+	draw2dbase.DrawImageScaled(gc, img, x, y, scaling)
+}
+
+// DrawImageRect draws the rectangle srcRect of the raster image as PNG,
+// scaled to the rectangle dstRect of the user space. The image is not
+// flipped if dstRect is.
+func (gc *GraphicContext) DrawImageRect(img image.Image, srcRect image.Rectangle, dstRect [4]float64) {
+	sub := draw2dbase.SubImage(img, srcRect)
+	bounds := sub.Bounds()
+	if srcRect.Empty() || bounds.Empty() {
+		return
+	}
+	// the parts of srcRect outside of img are not drawn
+	tr := draw2d.NewMatrixFromRects([4]float64{float64(srcRect.Min.X), float64(srcRect.Min.Y), float64(srcRect.Max.X), float64(srcRect.Max.Y)}, dstRect)
+	x0, y0, x1, y1 := tr.TransformRectangle(float64(bounds.Min.X), float64(bounds.Min.Y), float64(bounds.Max.X), float64(bounds.Max.Y))
+	if x1 <= x0 || y1 <= y0 {
+		// gofpdf computes the size of the images of width or height 0
+		return
+	}
 	name := strconv.Itoa(int(imageCount))
 	imageCount++
 	tp := "PNG" // "JPG", "JPEG", "PNG" and "GIF"
 	b := &bytes.Buffer{}
-	png.Encode(b, image)
+	png.Encode(b, sub)
 	gc.pdf.RegisterImageReader(name, tp, b)
-	bounds := image.Bounds()
-	x0, y0 := float64(bounds.Min.X), float64(bounds.Min.Y)
-	w, h := float64(bounds.Dx()), float64(bounds.Dy())
-	gc.pdf.Image(name, x0, y0, w, h, false, tp, 0, "")
+	gc.paint(1, func(pdf *gofpdf.Fpdf) {
+		pdf.Image(name, x0, y0, x1-x0, y1-y0, false, tp, 0, "")
+	})
 }
 
 // Clear draws a white rectangle over the whole page
@@ -144,6 +175,27 @@ func (gc *GraphicContext) GetDPI() int {
 // and the baseline intersect at 0, 0 in the returned coordinates.
 // Therefore the top and left coordinates may well be negative.
 func (gc *GraphicContext) GetStringBounds(s string) (left, top, right, bottom float64) {
+	if font, ok := gc.loadPathFont(); ok {
+		m := draw2dbase.MeasurePathFont(font, gc.pathFontScale(font), gc.Current.TextDecoration, s)
+		return m.Left, m.Top, m.Right, m.Bottom
+	}
+	left, top, right, bottom = gc.cellBounds(s)
+	advance := right
+	if gc.Current.WritingMode == draw2d.WritingModeVertical {
+		m := gc.fontMetrics()
+		advance = gc.layoutVertical(s, nil)
+		left, top, right, bottom = -(m.Ascent+m.Descent)/2, 0, (m.Ascent+m.Descent)/2, advance
+	}
+	if gc.Current.TextDecoration != draw2d.TextDecorationNone {
+		if l, t, r, b, ok := draw2dbase.DecorationBounds(gc.fontMetrics(), gc.Current.TextDecoration, gc.Current.WritingMode, advance); ok {
+			top, left, bottom, right = math.Min(top, t), math.Min(left, l), math.Max(bottom, b), math.Max(right, r)
+		}
+	}
+	return left, top, right, bottom
+}
+
+// cellBounds returns the bounds of the cell of the string s
+func (gc *GraphicContext) cellBounds(s string) (left, top, right, bottom float64) {
 	_, h := gc.pdf.GetFontSize()
 	d := gc.pdf.GetFontDesc("", "")
 	if d.Ascent == 0 {
@@ -155,16 +207,151 @@ func (gc *GraphicContext) GetStringBounds(s string) (left, top, right, bottom fl
 	return 0, top, gc.pdf.GetStringWidth(s), top + h
 }
 
+// layoutVertical splits text in runs of upright and sideways characters and
+// returns the height of the column they fill from top to bottom. Each
+// upright character advances by the height of the font, a sideways run by
+// its width. layout, if not nil, is called with each run and its offset
+// from the top of the column.
+func (gc *GraphicContext) layoutVertical(text string, layout func(run string, upright bool, offset float64)) float64 {
+	m := gc.fontMetrics()
+	runes := []rune(text)
+	height := 0.0
+	for start := 0; start < len(runes); {
+		upright := draw2dbase.IsUpright(runes[start])
+		end := start + 1
+		for !upright && end < len(runes) && !draw2dbase.IsUpright(runes[end]) {
+			end++
+		}
+		run := string(runes[start:end])
+		if layout != nil {
+			layout(run, upright, height)
+		}
+		if upright {
+			height += m.Ascent + m.Descent
+		} else {
+			height += gc.pdf.GetStringWidth(run)
+		}
+		start = end
+	}
+	return height
+}
+
+// loadPathFont returns the bitmap or stroke font selected by the current
+// font data in the global font cache. Its glyphs are drawn as paths instead
+// of pdf text.
+func (gc *GraphicContext) loadPathFont() (draw2d.PathFont, bool) {
+	return draw2dbase.LoadPathFont(draw2d.GetGlobalFontCache(), gc.Current.FontData)
+}
+
+// pathFontScale returns the size of the font units of font drawn with the
+// current font size. Bitmap fonts are not scaled by whole numbers, pdf
+// viewers do not draw on the pixels of the device.
+func (gc *GraphicContext) pathFontScale(font draw2d.PathFont) float64 {
+	_, h := gc.pdf.GetFontSize()
+	if _, _, em := font.Metrics(); em > 0 {
+		return h / em
+	}
+	return 1
+}
+
+// fontMetrics returns the metrics of the current font, read from its TrueType
+// font when the global font cache can load it and approximated from the pdf
+// font descriptor otherwise
+func (gc *GraphicContext) fontMetrics() draw2dbase.FontMetrics {
+	_, h := gc.pdf.GetFontSize()
+	if f, err := draw2d.GetGlobalFontCache().Load(gc.Current.FontData); err == nil && f != nil {
+		return draw2dbase.GetFontMetrics(f, h*64)
+	}
+	_, top, _, bottom := gc.cellBounds("")
+	return draw2dbase.ApproximateFontMetrics(h, -top, bottom)
+}
+
+// MeasureString returns the advance, ink bounds, font metrics and glyph
+// positions of the text. The metrics are read from the TrueType font of the
+// current FontData when the global font cache can load it, otherwise from the
+// pdf font descriptor, which does not provide the x height. Glyph positions
+// always follow the pdf font widths.
+func (gc *GraphicContext) MeasureString(text string) draw2d.TextMetrics {
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.MeasurePathFont(font, gc.pathFontScale(font), gc.Current.TextDecoration, text)
+	}
+	_, h := gc.pdf.GetFontSize()
+	var metrics draw2d.TextMetrics
+	if f, err := draw2d.GetGlobalFontCache().Load(gc.Current.FontData); err == nil && f != nil {
+		metrics = draw2dbase.MeasureString(f, h*64, gc.Current.WritingMode, gc.Current.TextDecoration, text)
+	} else {
+		d := gc.pdf.GetFontDesc("", "")
+		metrics.Left, metrics.Top, metrics.Right, metrics.Bottom = gc.GetStringBounds(text)
+		metrics.Ascent = -metrics.Top
+		metrics.Descent = metrics.Bottom
+		metrics.CapHeight = float64(d.CapHeight) * h / 1000
+	}
+	metrics.Glyphs = metrics.Glyphs[:0]
+	if gc.Current.WritingMode == draw2d.WritingModeVertical {
+		m := gc.fontMetrics()
+		metrics.Width = gc.layoutVertical(text, func(run string, upright bool, offset float64) {
+			if upright {
+				w := gc.pdf.GetStringWidth(run)
+				metrics.Glyphs = append(metrics.Glyphs, draw2d.GlyphMetrics{Rune: []rune(run)[0], X: -w / 2, Y: offset + m.Ascent, Advance: m.Ascent + m.Descent})
+				return
+			}
+			for _, r := range run {
+				w := gc.pdf.GetStringWidth(string(r))
+				metrics.Glyphs = append(metrics.Glyphs, draw2d.GlyphMetrics{Rune: r, X: draw2dbase.SidewaysBaseline(m), Y: offset, Advance: w})
+				offset += w
+			}
+		})
+		return metrics
+	}
+	x := 0.0
+	for _, r := range text {
+		w := gc.pdf.GetStringWidth(string(r))
+		metrics.Glyphs = append(metrics.Glyphs, draw2d.GlyphMetrics{Rune: r, X: x, Advance: w})
+		x += w
+	}
+	metrics.Width = gc.pdf.GetStringWidth(text)
+	return metrics
+}
+
 // CreateStringPath creates a path from the string s at x, y, and returns the string width.
+// In vertical writing mode x, y is the top of the column on its center line
+// and the height of the column is returned. The glyphs of bitmap and stroke
+// fonts are added to the current path, other fonts are drawn as pdf text.
 func (gc *GraphicContext) CreateStringPath(text string, x, y float64) (cursor float64) {
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.AddPathFontString(gc, font, gc.pathFontScale(font), text, x, y)
+	}
+	if gc.Current.WritingMode == draw2d.WritingModeVertical {
+		m := gc.fontMetrics()
+		return gc.layoutVertical(text, func(run string, upright bool, offset float64) {
+			if upright {
+				gc.createStringCell(run, x-gc.pdf.GetStringWidth(run)/2, y+offset+m.Ascent)
+				return
+			}
+			gc.Save()
+			gc.Translate(x+draw2dbase.SidewaysBaseline(m), y+offset)
+			gc.Rotate(math.Pi / 2)
+			gc.createStringCell(run, 0, 0)
+			gc.Restore()
+		})
+	}
+	return gc.createStringCell(text, x, y)
+}
+
+// createStringCell draws text in a cell with its baseline at x, y, with
+// the alpha of the fill color
+func (gc *GraphicContext) createStringCell(text string, x, y float64) float64 {
 	//fpdf uses the top left corner
-	left, top, right, bottom := gc.GetStringBounds(text)
+	left, top, right, bottom := gc.cellBounds(text)
 	w := right - left
 	h := bottom - top
-	// gc.pdf.SetXY(x, y-h) do not use this as y-h might be negative
-	margin := gc.pdf.GetCellMargin()
-	gc.pdf.MoveTo(x-left-margin, y+top)
-	gc.pdf.CellFormat(w, h, text, "", 0, "BL", false, 0, "")
+	_, _, _, alpha := gc.Current.FillColor.RGBA()
+	gc.paint(float64(alpha)/alphaMax, func(pdf *gofpdf.Fpdf) {
+		// gc.pdf.SetXY(x, y-h) do not use this as y-h might be negative
+		margin := pdf.GetCellMargin()
+		pdf.MoveTo(x-left-margin, y+top)
+		pdf.CellFormat(w, h, text, "", 0, "BL", false, 0, "")
+	})
 	return w
 }
 
@@ -175,7 +362,19 @@ func (gc *GraphicContext) FillString(text string) (cursor float64) {
 
 // FillStringAt draws a string at x, y
 func (gc *GraphicContext) FillStringAt(text string, x, y float64) (cursor float64) {
-	return gc.CreateStringPath(text, x, y)
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.DrawPathFontString(gc, font, gc.pathFontScale(font), text, x, y, draw2dbase.FillGlyph)
+	}
+	if gc.Current.HasShadow() {
+		gc.drawTextShadow(func(dx, dy float64) {
+			gc.CreateStringPath(text, x+dx, y+dy)
+		})
+	}
+	cursor = gc.CreateStringPath(text, x, y)
+	if gc.Current.TextDecoration != draw2d.TextDecorationNone {
+		draw2dbase.DrawDecorations(gc, gc.fontMetrics(), x, y, cursor, draw2dbase.FillGlyph)
+	}
+	return cursor
 }
 
 // StrokeString draws a string at 0, 0 (stroking is unsupported,
@@ -184,10 +383,44 @@ func (gc *GraphicContext) StrokeString(text string) (cursor float64) {
 	return gc.StrokeStringAt(text, 0, 0)
 }
 
-// StrokeStringAt draws a string at x, y (stroking is unsupported,
-// string will be filled)
+// StrokeStringAt draws a string at x, y (stroking is only supported by
+// bitmap and stroke fonts, other strings will be filled)
 func (gc *GraphicContext) StrokeStringAt(text string, x, y float64) (cursor float64) {
-	return gc.CreateStringPath(text, x, y)
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.DrawPathFontString(gc, font, gc.pathFontScale(font), text, x, y, draw2dbase.StrokeGlyph)
+	}
+	return gc.FillStringAt(text, x, y)
+}
+
+// FillStringOnPath draws the text along path, starting at offset from the
+// beginning of the path, each character rotated to follow the path tangent
+func (gc *GraphicContext) FillStringOnPath(text string, path *draw2d.Path, offset float64) (cursor float64) {
+	width := gc.pdf.GetStringWidth(text)
+	walker := draw2dbase.NewPathWalker(path)
+	start := draw2dbase.AnchorOffset(gc.Current.TextPathAnchor, offset, width)
+	// the widths of pdf strings are the sums of the widths of their runes
+	advance := start
+	for _, r := range text {
+		w := gc.pdf.GetStringWidth(string(r))
+		middle := advance + w/2
+		advance += w
+		x, y, angle, ok := walker.PointAt(middle)
+		if !ok {
+			continue
+		}
+		gc.Save()
+		gc.Translate(x, y)
+		gc.Rotate(angle)
+		gc.createStringCell(string(r), -w/2, 0)
+		gc.Restore()
+	}
+	return width
+}
+
+// StrokeStringOnPath draws the text along path (stroking is unsupported,
+// string will be filled)
+func (gc *GraphicContext) StrokeStringOnPath(text string, path *draw2d.Path, offset float64) (cursor float64) {
+	return gc.FillStringOnPath(text, path, offset)
 }
 
 // Stroke strokes the paths with the color specified by SetStrokeColor
@@ -232,15 +465,15 @@ const alphaMax = float64(0xFFFF)
 // draw fills and/or strokes paths
 func (gc *GraphicContext) draw(style string, alpha uint32, paths ...*draw2d.Path) {
 	paths = append(paths, gc.Current.Path)
-	for _, p := range paths {
-		ConvertPath(p, gc.pdf)
-	}
-	a := float64(alpha) / alphaMax
-	current, blendMode := gc.pdf.GetAlpha()
-	if a != current {
-		gc.pdf.SetAlpha(a, blendMode)
+	if gc.Current.HasShadow() {
+		gc.drawShadow(style, paths)
 	}
-	gc.pdf.DrawPath(style)
+	gc.paint(float64(alpha)/alphaMax, func(pdf *gofpdf.Fpdf) {
+		for _, p := range paths {
+			ConvertPath(p, pdf)
+		}
+		pdf.DrawPath(style)
+	})
 }
 
 // overwrite StackGraphicContext methods
@@ -270,17 +503,22 @@ func (gc *GraphicContext) SetFont(font *truetype.Font) {
 // text or the resulting document will not be valid.
 // It is necessary to generate a font definition file first with the
 // makefont utility. It is not necessary to call this function for the
-// core PDF fonts (courier, helvetica, times, zapfdingbats).
+// core PDF fonts (courier, helvetica, times, zapfdingbats), nor for the
+// bitmap and stroke fonts of the global font cache.
 // go get github.com/jung-kurt/gofpdf/makefont
 // http://godoc.org/github.com/jung-kurt/gofpdf#Fpdf.AddFont
 func (gc *GraphicContext) SetFontData(fontData draw2d.FontData) {
 	// TODO: call Makefont embed if json file does not exist yet
 	gc.StackGraphicContext.SetFontData(fontData)
+	if _, ok := gc.loadPathFont(); ok {
+		// bitmap and stroke fonts are drawn as paths
+		return
+	}
 	var style string
-	if fontData.Style&draw2d.FontStyleBold != 0 {
+	if fontData.ResolvedWeight() >= draw2d.FontWeightSemiBold {
 		style += "B"
 	}
-	if fontData.Style&draw2d.FontStyleItalic != 0 {
+	if fontData.Style&(draw2d.FontStyleItalic|draw2d.FontStyleOblique) != 0 {
 		style += "I"
 	}
 	fn := draw2d.FontFileName(fontData)
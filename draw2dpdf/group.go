@@ -0,0 +1,282 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dpdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"fmt"
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/llgcode/draw2d"
+)
+
+// blendModes are the names of the blend modes of pdf
+var blendModes = map[draw2d.BlendMode]string{
+	draw2d.BlendNormal:     "Normal",
+	draw2d.BlendMultiply:   "Multiply",
+	draw2d.BlendScreen:     "Screen",
+	draw2d.BlendOverlay:    "Overlay",
+	draw2d.BlendDarken:     "Darken",
+	draw2d.BlendLighten:    "Lighten",
+	draw2d.BlendColorDodge: "ColorDodge",
+	draw2d.BlendColorBurn:  "ColorBurn",
+	draw2d.BlendHardLight:  "HardLight",
+	draw2d.BlendSoftLight:  "SoftLight",
+	draw2d.BlendDifference: "Difference",
+	draw2d.BlendExclusion:  "Exclusion",
+}
+
+// group is a group started by PushGroup, drawn as a pdf transparency group
+// XObject. gofpdf writes neither transparency groups nor the alpha of the
+// templates, the objects of the group are imported in the document as the
+// ones of gofpdi are.
+type group struct {
+	// parent is the group in which the group is started, nil for a group
+	// drawn on the page
+	parent *group
+	// content is the content stream of the group, which draws the XObjects
+	// with the ExtGStates of its resources
+	content              bytes.Buffer
+	xObjects, extGStates []string
+	// segment is the content of the last drawings, which have the alpha
+	// and the blend mode of pdf alpha and blend
+	segment bytes.Buffer
+	alpha   float64
+	blend   string
+}
+
+// PushGroup starts a group: the following drawings are drawn in a pdf
+// transparency group, composited on the page by PopGroup
+func (gc *GraphicContext) PushGroup() {
+	gc.group = &group{parent: gc.group}
+}
+
+// PopGroup ends the last group started by PushGroup and composites it with
+// opacity and blend. It does nothing if there is no group.
+func (gc *GraphicContext) PopGroup(opacity float64, blend draw2d.BlendMode) {
+	g := gc.group
+	if g == nil {
+		return
+	}
+	gc.group = g.parent
+	hash, ok := g.form(gc.pdf)
+	if !ok {
+		return
+	}
+	gc.drawForm(hash, math.Max(0, math.Min(opacity, 1)), blendName(blendModes[blend]))
+}
+
+// drawForm draws the imported form XObject hash on the page, or in the
+// current group, with alpha and blend
+func (gc *GraphicContext) drawForm(hash string, alpha float64, blend string) {
+	if gc.group != nil {
+		gc.group.flush(gc.pdf)
+		gc.group.drawXObject(gc.pdf, hash, alpha, blend)
+		return
+	}
+	name := "/G" + hash
+	gc.pdf.ImportTemplates(map[string]string{name: hash})
+	current, blendMode := gc.pdf.GetAlpha()
+	gc.pdf.TransformBegin()
+	gc.pdf.Transform(gc.pageTransform())
+	gc.pdf.SetAlpha(alpha, blend)
+	gc.pdf.RawWriteStr(name + " Do")
+	gc.pdf.TransformEnd()
+	// the graphics state restored by TransformEnd is not known by gofpdf
+	gc.pdf.SetAlpha(current, blendMode)
+}
+
+// paint draws with draw on the page, or in the current group, with alpha
+// and the blend mode of pdf. The drawings masked by an image are drawn as
+// form XObjects with a soft mask.
+func (gc *GraphicContext) paint(alpha float64, draw func(pdf *gofpdf.Fpdf)) {
+	current, blendMode := gc.pdf.GetAlpha()
+	if m := gc.Current.Mask; m != nil && m.Image != nil {
+		if !m.Image.Bounds().Empty() {
+			gc.drawForm(gc.maskedForm(m, gc.capture(draw)), alpha, blendName(blendMode))
+		}
+		return
+	}
+	if gc.group != nil {
+		gc.group.add(gc.pdf, alpha, blendName(blendMode), gc.capture(draw))
+		return
+	}
+	if alpha != current {
+		gc.pdf.SetAlpha(alpha, blendMode)
+	}
+	draw(gc.pdf)
+}
+
+// capture returns the content drawn by draw on a gofpdf template of the
+// page, in the current state
+func (gc *GraphicContext) capture(draw func(pdf *gofpdf.Fpdf)) []byte {
+	cs := gc.Current
+	t := gc.pdf.CreateTemplate(func(tpl *gofpdf.Tpl) {
+		pdf := &tpl.Fpdf
+		pdf.SetAutoPageBreak(false, 0)
+		pdf.TransformBegin()
+		if m := cs.Mask; m != nil && m.Image == nil {
+			pdf.Transform(gc.pdfTransform(m.Tr))
+			clipPaths(pdf, m.Paths, m.FillRule)
+			tr := m.Tr.Copy()
+			tr.Inverse()
+			tr.Compose(cs.Tr)
+			pdf.Transform(gc.pdfTransform(tr))
+		} else {
+			pdf.Transform(gc.pdfTransform(cs.Tr))
+		}
+		if len(cs.Dash) > 0 {
+			pdf.SetDashPattern(cs.Dash, cs.DashOffset)
+		}
+		draw(pdf)
+		pdf.TransformEnd()
+	})
+	return t.Bytes()
+}
+
+// add adds to g the content of drawings with alpha and blend
+func (g *group) add(pdf *gofpdf.Fpdf, alpha float64, blend string, content []byte) {
+	if alpha != g.alpha || blend != g.blend {
+		g.flush(pdf)
+		g.alpha, g.blend = alpha, blend
+	}
+	g.segment.Write(content)
+}
+
+// flush draws the segment of g as a form XObject, with the resources of
+// the page
+func (g *group) flush(pdf *gofpdf.Fpdf) {
+	if g.segment.Len() == 0 {
+		return
+	}
+	o := newFormObject(pdf, false)
+	o.WriteString("/Resources 2 0 R")
+	hash := o.importStream(pdf, g.segment.Bytes())
+	g.segment.Reset()
+	g.drawXObject(pdf, hash, g.alpha, g.blend)
+}
+
+// drawXObject draws in g the imported XObject hash with alpha and blend
+func (g *group) drawXObject(pdf *gofpdf.Fpdf, hash string, alpha float64, blend string) {
+	g.xObjects = append(g.xObjects, hash)
+	name := fmt.Sprintf("/X%d", len(g.xObjects)-1)
+	if alpha == 1 && blend == "Normal" {
+		// the initial state of the transparency groups
+		fmt.Fprintf(&g.content, "%s Do\n", name)
+		return
+	}
+	o := &pdfObject{}
+	fmt.Fprintf(o, "<< /Type /ExtGState /ca %.3f /CA %.3f /BM /%s >>\nendobj", alpha, alpha, blend)
+	g.extGStates = append(g.extGStates, o.importObject(pdf))
+	fmt.Fprintf(&g.content, "q /E%d gs %s Do Q\n", len(g.extGStates)-1, name)
+}
+
+// form imports g as a transparency group XObject and returns its hash, ok
+// is false if nothing is drawn in g
+func (g *group) form(pdf *gofpdf.Fpdf) (hash string, ok bool) {
+	g.flush(pdf)
+	if g.content.Len() == 0 {
+		return "", false
+	}
+	o := newFormObject(pdf, true)
+	o.WriteString("/Resources << /ExtGState <<")
+	for i, h := range g.extGStates {
+		fmt.Fprintf(o, " /E%d ", i)
+		o.ref(h)
+	}
+	o.WriteString(" >> /XObject <<")
+	for i, h := range g.xObjects {
+		fmt.Fprintf(o, " /X%d ", i)
+		o.ref(h)
+	}
+	o.WriteString(" >> >>")
+	return o.importStream(pdf, g.content.Bytes()), true
+}
+
+// blendName returns the name of the blend mode of gofpdf, which is Normal
+// if it is not set
+func blendName(blendMode string) string {
+	if blendMode == "" {
+		return "Normal"
+	}
+	return blendMode
+}
+
+// pdfObject is a pdf object imported in the document with the gofpdf
+// support of gofpdi. The objects are named by hashes of 40 characters,
+// which gofpdf replaces by their numbers in the references to them.
+type pdfObject struct {
+	bytes.Buffer
+	refs map[int]string
+}
+
+// newFormObject starts the dictionary of a form XObject of the size of the
+// page, a transparency group if group is true. Its resources are written
+// next.
+func newFormObject(pdf *gofpdf.Fpdf, group bool) *pdfObject {
+	o := &pdfObject{}
+	width, height := pdf.GetPageSize()
+	k := pdf.GetConversionRatio()
+	fmt.Fprintf(o, "<< /Type /XObject /Subtype /Form /BBox [0 0 %.2f %.2f] ", width*k, height*k)
+	if group {
+		o.WriteString("/Group << /S /Transparency >> ")
+	}
+	return o
+}
+
+// ref writes a reference to the imported object hash
+func (o *pdfObject) ref(hash string) {
+	if o.refs == nil {
+		o.refs = make(map[int]string)
+	}
+	o.refs[o.Len()] = hash
+	o.WriteString(hash + " 0 R")
+}
+
+// importObject imports o in pdf and returns its hash
+func (o *pdfObject) importObject(pdf *gofpdf.Fpdf) string {
+	hash := fmt.Sprintf("%x", sha1.Sum(o.Bytes()))
+	pdf.ImportObjects(map[string][]byte{hash: o.Bytes()})
+	pdf.ImportObjPos(map[string]map[int]string{hash: o.refs})
+	return hash
+}
+
+// importStream ends the dictionary of o with the compressed stream of
+// content, imports o in pdf and returns its hash
+func (o *pdfObject) importStream(pdf *gofpdf.Fpdf, content []byte) string {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(content)
+	w.Close()
+	fmt.Fprintf(o, " /Filter /FlateDecode /Length %d >>\nstream\n", b.Len())
+	o.Write(b.Bytes())
+	o.WriteString("\nendstream\nendobj")
+	return o.importObject(pdf)
+}
+
+// pageTransform returns the pdf transformation which, concatenated to the
+// current one, makes the page the user space
+func (gc *GraphicContext) pageTransform() gofpdf.TransformMatrix {
+	pageToUser := gc.Current.Tr.Copy()
+	pageToUser.Inverse()
+	return gc.pdfTransform(pageToUser)
+}
+
+// pdfTransform returns the pdf transformation of the transformation tr of
+// the user space
+func (gc *GraphicContext) pdfTransform(tr draw2d.Matrix) gofpdf.TransformMatrix {
+	k := gc.pdf.GetConversionRatio()
+	_, height := gc.pdf.GetPageSize()
+	// the coordinates of pdf are in points from the bottom of the page
+	toPdf := draw2d.Matrix{k, 0, 0, -k, 0, k * height}
+	fromPdf := toPdf.Copy()
+	fromPdf.Inverse()
+	m := toPdf.Copy()
+	m.Compose(tr)
+	m.Compose(fromPdf)
+	return gofpdf.TransformMatrix{A: m[0], B: m[1], C: m[2], D: m[3], E: m[4], F: m[5]}
+}
@@ -0,0 +1,116 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dpdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dkit"
+	"github.com/llgcode/draw2d/draw2dpdf"
+)
+
+func TestGraphicContext_PushGroup(t *testing.T) {
+	// a pdf font which the global font cache cannot load
+	dir := t.TempDir()
+	for src, dst := range map[string]string{"luximbi.json": "pdfonlymbi.json", "luximbi.z": "luximbi.z"} {
+		b, err := os.ReadFile(filepath.Join("../resource/font", src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, dst), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	folder := draw2d.GetFontFolder()
+	draw2d.SetFontFolder(dir)
+	t.Cleanup(func() { draw2d.SetFontFolder(folder) })
+
+	pdf := draw2dpdf.NewPdf("L", "mm", "A4")
+	pdf.SetCompression(false)
+	gc := draw2dpdf.NewGraphicContext(pdf)
+	gc.SetFontData(draw2d.FontData{Name: "pdfonly", Family: draw2d.FontFamilyMono, Style: draw2d.FontStyleBold | draw2d.FontStyleItalic})
+	gc.Save()
+	gc.Translate(10, 10)
+	gc.PushGroup()
+	gc.SetFillColor(color.RGBA{0xff, 0, 0, 0xff})
+	draw2dkit.Rectangle(gc, 0, 0, 30, 20)
+	gc.Fill()
+	gc.FillStringAt("Hello", 0, 30)
+	// a nested group
+	gc.PushGroup()
+	gc.SetFillColor(color.NRGBA{0, 0, 0xff, 0x80})
+	draw2dkit.Rectangle(gc, 10, 0, 40, 20)
+	gc.Fill()
+	gc.PopGroup(0.8, draw2d.BlendNormal)
+	gc.PopGroup(0.5, draw2d.BlendMultiply)
+	gc.Restore()
+	gc.SetFillColor(color.RGBA{0, 0xff, 0, 0xff})
+	draw2dkit.Rectangle(gc, 10, 80, 50, 90)
+	gc.Fill()
+
+	var b bytes.Buffer
+	if err := pdf.Output(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := inflateStreams(t, b.String())
+	if n := strings.Count(out, "/Group << /S /Transparency >>"); n != 2 {
+		t.Errorf("got %d transparency groups, want 2", n)
+	}
+	// the group is drawn on the page, not translated, with its opacity and
+	// blend mode
+	if !regexp.MustCompile(`-28.34646 28.34646 cm\n/GS\d+ gs\n/G[0-9a-f]{40} Do`).MatchString(out) {
+		t.Error("the group should be drawn on the page")
+	}
+	if !strings.Contains(out, "/ca 0.500 /CA 0.500 /BM /Multiply") {
+		t.Error("the group should be composited with its opacity and blend mode")
+	}
+	// the nested group is drawn in the group, with its opacity, and its
+	// rectangle with the alpha of its color
+	if !regexp.MustCompile(`stream\n/X0 Do\nq /E0 gs /X1 Do Q\n`).MatchString(out) {
+		t.Error("the nested group should be drawn after the drawings of the group")
+	}
+	for _, gs := range []string{"/ca 0.800 /CA 0.800 /BM /Normal", "/ca 0.502 /CA 0.502 /BM /Normal"} {
+		if !strings.Contains(out, gs) {
+			t.Errorf("no ExtGState %s", gs)
+		}
+	}
+	// the drawings of the groups are not drawn on the page
+	if n := strings.Count(out, "\nh\nf"); n != 3 {
+		t.Errorf("got %d fills, want the ones of the groups and the one drawn after them", n)
+	}
+	if n := strings.Count(out, "(Hello)Tj"); n != 1 {
+		t.Errorf("got %d texts, want the one of the group", n)
+	}
+	if i, j := strings.Index(out, "(Hello)Tj"), strings.Index(out, "/Resources 2 0 R"); i < j || j < 0 {
+		t.Error("the text should be drawn in a form of the group")
+	}
+}
+
+// inflateStreams returns out with the content of its FlateDecode streams
+// inflated
+func inflateStreams(t *testing.T, out string) string {
+	t.Helper()
+	re := regexp.MustCompile(`(?s)/Filter /FlateDecode /Length \d+ >>\nstream\n(.*?)\nendstream`)
+	return re.ReplaceAllStringFunc(out, func(s string) string {
+		stream := re.FindStringSubmatch(s)[1]
+		r, err := zlib.NewReader(strings.NewReader(stream))
+		if err != nil {
+			return s
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ">>\nstream\n" + string(b) + "\nendstream"
+	})
+}
@@ -0,0 +1,34 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dpdf_test
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dpdf"
+)
+
+func TestGraphicContext_DrawImageRect(t *testing.T) {
+	pdf := draw2dpdf.NewPdf("P", "pt", "A4")
+	pdf.SetCompression(false)
+	gc := draw2dpdf.NewGraphicContext(pdf)
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	gc.DrawImageRect(img, image.Rect(10, 0, 20, 10), [4]float64{0, 0, 30, 20})
+	gc.DrawImageScaled(img, 0, 0, draw2d.ImageScaling{Width: 100, Height: 50, ScalingPolicy: draw2d.ScalingFit})
+
+	var b bytes.Buffer
+	if err := pdf.Output(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{"q 30.00000 0 0 20.00000 ", "q 50.00000 0 0 50.00000 "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("the image drawn with %q not found", want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dpdf
+
+import (
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
+)
+
+// MaskWithPath sets the mask of the following drawings to the fill of the
+// paths and of the current path. The masks of paths are clipping paths of
+// pdf, which intersect the clipping paths set since the last Save and are
+// only removed by Restore.
+func (gc *GraphicContext) MaskWithPath(paths ...*draw2d.Path) {
+	gc.StackGraphicContext.MaskWithPath(paths...)
+	clipPaths(gc.pdf, gc.Current.Mask.Paths, gc.Current.FillRule)
+}
+
+// clipPaths intersects the clipping path of pdf with the fill of paths
+// with fillRule
+func clipPaths(pdf *gofpdf.Fpdf, paths []*draw2d.Path, fillRule draw2d.FillRule) {
+	empty := true
+	for _, p := range paths {
+		empty = empty && p.IsEmpty()
+		ConvertPath(p, pdf)
+	}
+	if empty {
+		// nothing is drawn within an empty clipping path
+		pdf.RawWriteStr("0 0 0 0 re")
+	}
+	if fillRule == draw2d.FillRuleWinding {
+		pdf.RawWriteStr("W n")
+	} else {
+		pdf.RawWriteStr("W* n")
+	}
+}
+
+// maskedForm imports the drawing content as a form XObject masked by the
+// image mask m and returns its hash. gofpdf writes no soft masks, the form
+// draws the content with an ExtGState of which the soft mask is the group
+// of the mask image.
+func (gc *GraphicContext) maskedForm(m *draw2dbase.Mask, content []byte) string {
+	o := newFormObject(gc.pdf, false)
+	o.WriteString("/Resources 2 0 R")
+	drawing := o.importStream(gc.pdf, content)
+
+	o = newFormObject(gc.pdf, true)
+	o.WriteString("/Resources 2 0 R")
+	group := o.importStream(gc.pdf, gc.maskImage(m))
+
+	subtype := "Alpha"
+	if m.Luminance {
+		// the image is drawn on the black backdrop of the group, its
+		// luminance is multiplied by its alpha
+		subtype = "Luminosity"
+	}
+	o = &pdfObject{}
+	fmt.Fprintf(o, "<< /Type /ExtGState /SMask << /Type /Mask /S /%s /G ", subtype)
+	o.ref(group)
+	o.WriteString(" >> >>\nendobj")
+	gs := o.importObject(gc.pdf)
+
+	o = newFormObject(gc.pdf, false)
+	o.WriteString("/Resources << /ExtGState << /E0 ")
+	o.ref(gs)
+	o.WriteString(" >> /XObject << /X0 ")
+	o.ref(drawing)
+	o.WriteString(" >> >>")
+	return o.importStream(gc.pdf, []byte("/E0 gs /X0 Do\n"))
+}
+
+// maskImage returns the content drawing the image of the mask m on the page
+func (gc *GraphicContext) maskImage(m *draw2dbase.Mask) []byte {
+	name, ok := gc.maskImages[m]
+	if !ok {
+		if gc.maskImages == nil {
+			gc.maskImages = make(map[*draw2dbase.Mask]string)
+		}
+		// the masks are not modified once set, their images are
+		// registered once
+		name = gc.registerRaster("mask", m.Image)
+		gc.maskImages[m] = name
+	}
+	b := m.Image.Bounds()
+	t := gc.pdf.CreateTemplate(func(tpl *gofpdf.Tpl) {
+		pdf := &tpl.Fpdf
+		pdf.SetAutoPageBreak(false, 0)
+		pdf.TransformBegin()
+		pdf.Transform(gc.pdfTransform(m.Tr))
+		pdf.Image(name, float64(b.Min.X), float64(b.Min.Y), float64(b.Dx()), float64(b.Dy()), false, "PNG", 0, "")
+		pdf.TransformEnd()
+	})
+	return t.Bytes()
+}
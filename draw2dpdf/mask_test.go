@@ -0,0 +1,69 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dpdf_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dkit"
+	"github.com/llgcode/draw2d/draw2dpdf"
+)
+
+func TestGraphicContext_Mask(t *testing.T) {
+	folder := draw2d.GetFontFolder()
+	draw2d.SetFontFolder("../resource/font")
+	t.Cleanup(func() { draw2d.SetFontFolder(folder) })
+	pdf := draw2dpdf.NewPdf("L", "mm", "A4")
+	pdf.SetCompression(false)
+	gc := draw2dpdf.NewGraphicContext(pdf)
+	gc.SetFontData(draw2d.FontData{Name: "luxi", Family: draw2d.FontFamilyMono, Style: draw2d.FontStyleBold | draw2d.FontStyleItalic})
+	gc.SetFillColor(color.RGBA{0xff, 0, 0, 0xff})
+	gc.Save()
+	draw2dkit.Circle(gc, 30, 30, 20)
+	gc.MaskWithPath()
+	draw2dkit.Rectangle(gc, 10, 10, 50, 30)
+	gc.Fill()
+	gc.Restore()
+	mask := image.NewAlpha(image.Rect(0, 0, 10, 10))
+	mask.SetAlpha(5, 5, color.Alpha{A: 0xff})
+	gc.SetMask(mask, draw2d.NewScaleMatrix(10, 10))
+	draw2dkit.Rectangle(gc, 10, 80, 50, 90)
+	gc.Fill()
+	gc.FillStringAt("Hello", 50, 55)
+	gc.DrawImage(image.NewGray(image.Rect(0, 0, 4, 4)))
+	gc.SetLuminanceMask(mask, draw2d.NewScaleMatrix(10, 10))
+	draw2dkit.Rectangle(gc, 10, 80, 50, 90)
+	gc.Fill()
+
+	var b bytes.Buffer
+	if err := pdf.Output(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := inflateStreams(t, b.String())
+	if !strings.Contains(out, "W* n") {
+		t.Error("the mask of paths should be a clipping path")
+	}
+	// the fill, the text and the image are drawn with the soft mask of the
+	// image, which is registered once
+	if n := strings.Count(out, "/Type /ExtGState /SMask << /Type /Mask /S /Alpha /G"); n != 1 {
+		t.Errorf("got %d alpha soft masks, want 1", n)
+	}
+	if n := strings.Count(out, "/Type /ExtGState /SMask << /Type /Mask /S /Luminosity /G"); n != 1 {
+		t.Errorf("got %d luminosity soft masks, want 1", n)
+	}
+	if n := strings.Count(out, "/E0 gs /X0 Do"); n != 4 {
+		t.Errorf("got %d masked drawings, want 4", n)
+	}
+	if n := strings.Count(out, "/Subtype /Image"); n != 3 {
+		t.Errorf("got %d images, want the mask, its soft mask and the drawn image", n)
+	}
+	if i, j := strings.Index(out, "(Hello)Tj"), strings.Index(out, "/Resources 2 0 R"); i < j || j < 0 {
+		t.Error("the text should be drawn in a masked form")
+	}
+}
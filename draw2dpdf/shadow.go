@@ -0,0 +1,155 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dpdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+const (
+	// rasterResolution is the number of pixels per unit of the page of the
+	// rasterized shadows
+	rasterResolution = 4
+	// maxRasterPixels is the size above which the resolution of rasterized
+	// drawings is lowered
+	maxRasterPixels = 1 << 24
+)
+
+// drawShadow draws the shadow of the paths drawn with style. Pdf cannot
+// blur, the shadow is rasterized by draw2dimg and drawn as an image with a
+// soft mask.
+func (gc *GraphicContext) drawShadow(style string, paths []*draw2d.Path) {
+	cs := gc.Current
+	img, x, y, width, height, blur, ok := gc.rasterize(style, paths, cs.ShadowBlur)
+	if !ok {
+		return
+	}
+	mask := image.NewAlpha16(img.Rect)
+	for i := range len(img.Pix) / 4 {
+		mask.Pix[2*i], mask.Pix[2*i+1] = img.Pix[4*i+3], img.Pix[4*i+3]
+	}
+	dx, dy := gc.shadowOffset()
+	gc.drawRaster("shadow", draw2dbase.Shadow(mask, blur, cs.ShadowColor), x+dx, y+dy, width, height)
+}
+
+// rasterize draws with draw2dimg the paths with style on an image with
+// rasterResolution pixels per unit of the page, or less if the image would
+// have more than maxRasterPixels, and a margin for a blur of blur units of
+// the page. It returns the image, its position and size in user space and
+// the blur in pixels, ok is false if there is nothing to draw.
+func (gc *GraphicContext) rasterize(style string, paths []*draw2d.Path, blur float64) (img *image.RGBA, x, y, width, height, blurPixels float64, ok bool) {
+	cs := gc.Current
+	scale := cs.Tr.GetScale()
+	x0, y0, x1, y1, ok := draw2dbase.PathsBounds(paths)
+	if !ok || scale == 0 {
+		return nil, 0, 0, 0, 0, 0, false
+	}
+	if isStroked(style) {
+		w := draw2dbase.StrokeMargin(cs.LineWidth, cs.Cap, cs.Join)
+		x0, y0, x1, y1 = x0-w, y0-w, x1+w, y1+w
+	}
+	pixels := float64(rasterResolution)
+	var w, h int
+	for {
+		blurPixels = blur * pixels
+		resolution := pixels * scale
+		m := float64(draw2dbase.ShadowMargin(blurPixels)) / resolution
+		w, h = int(math.Ceil((x1-x0+2*m)*resolution)), int(math.Ceil((y1-y0+2*m)*resolution))
+		if w <= 0 || h <= 0 {
+			return nil, 0, 0, 0, 0, 0, false
+		}
+		if w*h <= maxRasterPixels {
+			x0, y0 = x0-m, y0-m
+			break
+		}
+		// the margin is rounded up, a few iterations may be needed
+		pixels *= math.Min(math.Sqrt(maxRasterPixels/float64(w*h)), 0.99)
+	}
+	resolution := pixels * scale
+
+	img = image.NewRGBA(image.Rect(0, 0, w, h))
+	igc := draw2dimg.NewGraphicContext(img)
+	igc.Scale(resolution, resolution)
+	igc.Translate(-x0, -y0)
+	igc.SetFillColor(cs.FillColor)
+	igc.SetFillRule(cs.FillRule)
+	igc.SetStrokeColor(cs.StrokeColor)
+	igc.SetLineWidth(cs.LineWidth)
+	igc.SetLineCap(cs.Cap)
+	igc.SetLineJoin(cs.Join)
+	igc.SetLineDash(cs.Dash, cs.DashOffset)
+	drawStyle(igc, style, paths)
+	return img, x0, y0, float64(w) / resolution, float64(h) / resolution, blurPixels, true
+}
+
+// isStroked returns whether the paths drawn with the pdf style are stroked
+func isStroked(style string) bool {
+	return strings.ContainsAny(style, "DS")
+}
+
+// drawStyle draws with igc the paths with the pdf style
+func drawStyle(igc *draw2dimg.GraphicContext, style string, paths []*draw2d.Path) {
+	switch {
+	case isStroked(style) && strings.Contains(style, "F"):
+		igc.FillStroke(paths...)
+	case isStroked(style):
+		igc.Stroke(paths...)
+	default:
+		igc.Fill(paths...)
+	}
+}
+
+// registerRaster registers img as a PNG image with a soft mask of its alpha
+// and returns its name
+func (gc *GraphicContext) registerRaster(prefix string, img image.Image) string {
+	b := &bytes.Buffer{}
+	png.Encode(b, img)
+	name := prefix + strconv.Itoa(int(imageCount))
+	imageCount++
+	gc.pdf.RegisterImageReader(name, "PNG", b)
+	return name
+}
+
+// drawRaster draws img at x, y in user space with width x height, as an
+// opaque PNG image with a soft mask of its alpha
+func (gc *GraphicContext) drawRaster(prefix string, img image.Image, x, y, width, height float64) {
+	name := gc.registerRaster(prefix, img)
+	gc.paint(1, func(pdf *gofpdf.Fpdf) {
+		pdf.Image(name, x, y, width, height, false, "PNG", 0, "")
+	})
+}
+
+// drawTextShadow draws with draw the text offset by dx, dy in the shadow
+// color. The text of pdf fonts is not rasterized, its shadow is not blurred.
+func (gc *GraphicContext) drawTextShadow(draw func(dx, dy float64)) {
+	cs := gc.Current
+	fillColor, shadowColor := cs.FillColor, cs.ShadowColor
+	c := color.NRGBAModel.Convert(shadowColor).(color.NRGBA)
+	gc.SetFillColor(color.NRGBA{c.R, c.G, c.B, 0xff})
+	// the text is drawn with the alpha of the fill color
+	cs.FillColor = shadowColor
+	cs.ShadowColor = color.Transparent
+	draw(gc.shadowOffset())
+	cs.ShadowColor = shadowColor
+	gc.SetFillColor(fillColor)
+}
+
+// shadowOffset returns the offset of the shadow in user space
+func (gc *GraphicContext) shadowOffset() (dx, dy float64) {
+	tr := gc.Current.Tr
+	x, y := tr.InverseTransformPoint(gc.Current.ShadowOffsetX, gc.Current.ShadowOffsetY)
+	x0, y0 := tr.InverseTransformPoint(0, 0)
+	return x - x0, y - y0
+}
@@ -0,0 +1,66 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dpdf_test
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dkit"
+	"github.com/llgcode/draw2d/draw2dpdf"
+)
+
+func TestGraphicContext_SetShadow(t *testing.T) {
+	folder := draw2d.GetFontFolder()
+	draw2d.SetFontFolder("../resource/font")
+	t.Cleanup(func() { draw2d.SetFontFolder(folder) })
+	pdf := draw2dpdf.NewPdf("L", "mm", "A4")
+	pdf.SetCompression(false)
+	gc := draw2dpdf.NewGraphicContext(pdf)
+	gc.SetFontData(draw2d.FontData{Name: "luxi", Family: draw2d.FontFamilyMono, Style: draw2d.FontStyleBold | draw2d.FontStyleItalic})
+	gc.SetFillColor(color.RGBA{0xff, 0, 0, 0xff})
+	gc.SetShadow(1, 1, 2, color.NRGBA{0, 0, 0, 0x80})
+	draw2dkit.Rectangle(gc, 10, 10, 50, 30)
+	gc.Fill()
+	gc.FillStringAt("Hello", 10, 60)
+	gc.SetShadow(0, 0, 0, color.Black)
+	draw2dkit.Rectangle(gc, 10, 80, 50, 90)
+	gc.Fill()
+
+	var b bytes.Buffer
+	if err := pdf.Output(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	// the shadow of the first rectangle, with a soft mask
+	if n := strings.Count(out, "/Subtype /Image"); n != 2 {
+		t.Errorf("got %d images, want the shadow and its soft mask", n)
+	}
+	if !strings.Contains(out, "/SMask") {
+		t.Error("the shadow should have a soft mask")
+	}
+	if n := strings.Count(out, "(Hello)Tj"); n != 2 {
+		t.Errorf("got %d texts, want the text and its shadow", n)
+	}
+}
+
+func TestGraphicContext_SetShadowLarge(t *testing.T) {
+	pdf := draw2dpdf.NewPdf("L", "mm", "A4")
+	gc := draw2dpdf.NewGraphicContext(pdf)
+	gc.SetShadow(1, 1, 2, color.NRGBA{0, 0, 0, 0x80})
+	// too large to be rasterized at the default resolution
+	draw2dkit.Rectangle(gc, -1000, -1000, 1000, 1000)
+	gc.Fill()
+
+	var b bytes.Buffer
+	if err := pdf.Output(&b); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(b.String(), "/Subtype /Image"); n != 2 {
+		t.Errorf("got %d images, want the shadow at a lower resolution and its soft mask", n)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2dpdf_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dpdf"
+)
+
+// newTextContext returns a pdf GraphicContext using the luxi mono bold italic
+// font, the only one of the resource folder with a pdf font definition
+func newTextContext(t *testing.T) *draw2dpdf.GraphicContext {
+	folder := draw2d.GetFontFolder()
+	draw2d.SetFontFolder("../resource/font")
+	t.Cleanup(func() { draw2d.SetFontFolder(folder) })
+	pdf := draw2dpdf.NewPdf("L", "mm", "A4")
+	gc := draw2dpdf.NewGraphicContext(pdf)
+	gc.SetFontData(draw2d.FontData{Name: "luxi", Family: draw2d.FontFamilyMono, Style: draw2d.FontStyleBold | draw2d.FontStyleItalic})
+	gc.SetFontSize(12)
+	return gc
+}
+
+func TestMeasureString(t *testing.T) {
+	gc := newTextContext(t)
+	text := "Hello"
+	m := gc.MeasureString(text)
+	_, _, right, _ := gc.GetStringBounds(text)
+	if math.Abs(m.Width-right) > 1e-9 {
+		t.Errorf("MeasureString width %v, GetStringBounds right %v", m.Width, right)
+	}
+	if len(m.Glyphs) != len(text) {
+		t.Fatalf("expected %d glyphs, got %d", len(text), len(m.Glyphs))
+	}
+	last := m.Glyphs[len(m.Glyphs)-1]
+	if math.Abs(last.X+last.Advance-m.Width) > 1e-6 {
+		t.Errorf("last glyph should end at the text width: %+v, width %v", last, m.Width)
+	}
+	if m.Ascent <= 0 || m.Descent <= 0 || m.XHeight <= 0 || m.CapHeight <= m.XHeight {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+}
+
+func TestGetStringBounds_Decorations(t *testing.T) {
+	gc := newTextContext(t)
+	left, top, right, bottom := gc.GetStringBounds("Hello")
+	gc.SetTextDecoration(draw2d.TextDecorationUnderline | draw2d.TextDecorationOverline)
+	dLeft, dTop, dRight, dBottom := gc.GetStringBounds("Hello")
+	if dLeft > left || dTop > top || dRight < right || dBottom < bottom {
+		t.Errorf("decorated bounds %v %v %v %v should contain %v %v %v %v",
+			dLeft, dTop, dRight, dBottom, left, top, right, bottom)
+	}
+	if width := gc.FillStringAt("Hello", 10, 10); math.Abs(width-right) > 1e-9 {
+		t.Errorf("FillStringAt returned %v, want %v", width, right)
+	}
+}
+
+func TestFillStringAt_Vertical(t *testing.T) {
+	gc := newTextContext(t)
+	width := gc.FillStringAt("Hello", 10, 10)
+	gc.SetWritingMode(draw2d.WritingModeVertical)
+	left, top, right, bottom := gc.GetStringBounds("Hello")
+	if top != 0 || math.Abs(bottom-width) > 1e-9 || left >= 0 || math.Abs(left+right) > 1e-9 {
+		t.Errorf("unexpected vertical bounds %v %v %v %v", left, top, right, bottom)
+	}
+	if height := gc.FillStringAt("Hello", 10, 10); math.Abs(height-width) > 1e-9 {
+		t.Errorf("FillStringAt returned %v, want %v", height, width)
+	}
+	m := gc.MeasureString("A漢")
+	if len(m.Glyphs) != 2 || m.Glyphs[1].Y <= m.Glyphs[0].Y || m.Width <= m.Glyphs[1].Y {
+		t.Errorf("glyphs should progress downwards, got %+v", m)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dfilter"
 	"image"
 	"image/color"
 	"image/png"
@@ -47,6 +48,80 @@ func toSvgFillRule(rule draw2d.FillRule) string {
 	}[rule]
 }
 
+// toSvgShapeRendering returns the shape-rendering attribute of shapes drawn
+// with or without antialiasing and crisp edges, empty for the default. Both
+// map to crispEdges, which turns off antialiasing in the renderers while
+// optimizeSpeed may keep it.
+func toSvgShapeRendering(antialias, crisp bool) string {
+	if crisp || !antialias {
+		return "crispEdges"
+	}
+	return ""
+}
+
+// toSvgOpacity returns the opacity attribute of a group, empty if opaque
+func toSvgOpacity(opacity float64) string {
+	if opacity >= 1 {
+		return ""
+	}
+	return optiSprintf("%f", math.Max(opacity, 0))
+}
+
+// toSvgFilterPrimitives returns the svg filter primitives of filters,
+// ignoring the filters not defined by draw2dfilter
+func toSvgFilterPrimitives(filters []draw2dfilter.Filter) []interface{} {
+	numbers := func(nums ...float64) string {
+		strs := make([]string, len(nums))
+		for i, n := range nums {
+			strs[i] = strconv.FormatFloat(n, 'g', 6, 64)
+		}
+		return strings.Join(strs, " ")
+	}
+	var primitives []interface{}
+	for _, f := range filters {
+		switch f := f.(type) {
+		case draw2dfilter.GaussianBlur:
+			primitives = append(primitives, &FeGaussianBlur{StdDeviation: numbers(f.StdDeviationX, f.StdDeviationY)})
+		case draw2dfilter.BoxBlur:
+			w, h := 2*max(f.RadiusX, 0)+1, 2*max(f.RadiusY, 0)+1
+			kernel := make([]float64, w*h)
+			for i := range kernel {
+				kernel[i] = 1
+			}
+			primitives = append(primitives, &FeConvolveMatrix{
+				Order:        numbers(float64(w), float64(h)),
+				KernelMatrix: numbers(kernel...),
+				EdgeMode:     "none",
+			})
+		case draw2dfilter.ColorMatrix:
+			primitives = append(primitives, &FeColorMatrix{Type: "matrix", Values: numbers(f[:]...)})
+		case draw2dfilter.Morphology:
+			primitives = append(primitives, &FeMorphology{
+				Operator: f.Operator.String(),
+				Radius:   numbers(float64(f.RadiusX), float64(f.RadiusY)),
+			})
+		case draw2dfilter.Convolution:
+			if f.OrderX <= 0 || f.OrderY <= 0 || len(f.Kernel) != f.OrderX*f.OrderY {
+				continue
+			}
+			primitive := &FeConvolveMatrix{
+				Order:         numbers(float64(f.OrderX), float64(f.OrderY)),
+				KernelMatrix:  numbers(f.Kernel...),
+				EdgeMode:      "none",
+				PreserveAlpha: f.PreserveAlpha,
+			}
+			if f.Divisor != 0 {
+				primitive.Divisor = numbers(f.Divisor)
+			}
+			if f.Bias != 0 {
+				primitive.Bias = numbers(f.Bias)
+			}
+			primitives = append(primitives, primitive)
+		}
+	}
+	return primitives
+}
+
 func toSvgPathDesc(p *draw2d.Path) string {
 	parts := make([]string, len(p.Components))
 	ps := p.Points
@@ -7,6 +7,7 @@ import (
 		"github.com/golang/freetype/truetype"
 	"github.com/llgcode/draw2d"
 	"github.com/llgcode/draw2d/draw2dbase"
+	"github.com/llgcode/draw2d/draw2dfilter"
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
 	"image"
@@ -32,16 +33,24 @@ type GraphicContext struct {
 	glyphBuf   *truetype.GlyphBuf
 	svg        *Svg
 	DPI        int
+	// groups are the groups of the svg saved by PushGroup
+	groups [][]*Group
+	// mask is the last mask drawn with and maskId the id of its svg mask
+	mask   *draw2dbase.Mask
+	maskId string
 }
 
 func NewGraphicContext(svg *Svg) *GraphicContext {
 	gc := &GraphicContext{
 		draw2dbase.NewStackGraphicContext(),
 		draw2d.GetGlobalFontCache(),
-		draw2dbase.NewGlyphCache(),
+		draw2dbase.NewLRUGlyphCache(draw2dbase.DefaultGlyphCacheSize),
 		&truetype.GlyphBuf{},
 		svg,
 		92,
+		nil,
+		nil,
+		"",
 	}
 	return gc
 }
@@ -89,6 +98,19 @@ func (gc *GraphicContext) StrokeStringAt(text string, x, y float64) (cursor floa
 	return gc.drawString(text, stroked, x, y)
 }
 
+// FillStringOnPath draws the text along path, starting at offset from the
+// beginning of the path. In PathFontMode glyphs are converted to paths,
+// otherwise a textPath element referencing path is used.
+func (gc *GraphicContext) FillStringOnPath(text string, path *draw2d.Path, offset float64) (cursor float64) {
+	return gc.drawStringOnPath(text, filled, path, offset)
+}
+
+// StrokeStringOnPath draws the contour of the text along path, starting at
+// offset from the beginning of the path
+func (gc *GraphicContext) StrokeStringOnPath(text string, path *draw2d.Path, offset float64) (cursor float64) {
+	return gc.drawStringOnPath(text, stroked, path, offset)
+}
+
 // Save the context and push it to the context stack
 func (gc *GraphicContext) Save() {
 	gc.StackGraphicContext.Save()
@@ -110,6 +132,13 @@ func (gc *GraphicContext) GetDPI() int {
 	return gc.DPI
 }
 
+// SetGlyphCache sets the cache of the glyphs drawn by FillString and
+// StrokeString. A draw2dbase.LRUGlyphCache can be shared by several graphic
+// contexts, by default each context has its own.
+func (gc *GraphicContext) SetGlyphCache(glyphCache draw2dbase.GlyphCache) {
+	gc.glyphCache = glyphCache
+}
+
 // SetFont sets the font used to draw text.
 func (gc *GraphicContext) SetFont(font *truetype.Font) {
 	gc.Current.Font = font
@@ -133,6 +162,18 @@ func (gc *GraphicContext) DrawImage(image image.Image) {
 	gc.newGroup(0).Image = svgImage
 }
 
+// DrawImageScaled draws the raster image at x, y, scaled and aligned as
+// specified by scaling
+func (gc *GraphicContext) DrawImageScaled(img image.Image, x, y float64, scaling draw2d.ImageScaling) {
+	draw2dbase.DrawImageScaled(gc, img, x, y, scaling)
+}
+
+// DrawImageRect draws the rectangle srcRect of the raster image scaled to
+// the rectangle dstRect of the user space
+func (gc *GraphicContext) DrawImageRect(img image.Image, srcRect image.Rectangle, dstRect [4]float64) {
+	draw2dbase.DrawImageRect(gc, img, srcRect, dstRect)
+}
+
 // ClearRect fills the specified rectangle with a default transparent color
 func (gc *GraphicContext) ClearRect(x1, y1, x2, y2 int) {
 	mask := gc.newMask(x1, y1, x2-x1, y2-y1)
@@ -146,6 +187,55 @@ func (gc *GraphicContext) ClearRect(x1, y1, x2, y2 int) {
 	gc.svg.Groups = []*Group{newGroup}
 }
 
+// PushGroup starts a group: the following drawings are added to a svg
+// group, composited by PopGroup
+func (gc *GraphicContext) PushGroup() {
+	gc.groups = append(gc.groups, gc.svg.Groups)
+	gc.svg.Groups = nil
+}
+
+// PopGroup ends the last group started by PushGroup, setting the opacity
+// and the mix-blend-mode of its svg group. It does nothing if there is no
+// group.
+func (gc *GraphicContext) PopGroup(opacity float64, blend draw2d.BlendMode) {
+	if len(gc.groups) == 0 {
+		return
+	}
+	group := &Group{
+		Groups:  gc.svg.Groups,
+		Opacity: toSvgOpacity(opacity),
+	}
+	if blend != draw2d.BlendNormal {
+		group.Style = "mix-blend-mode: " + blend.String()
+	}
+	gc.svg.Groups = append(gc.groups[len(gc.groups)-1], group)
+	gc.groups = gc.groups[:len(gc.groups)-1]
+}
+
+// ApplyFilters filters the drawings, or the ones of the current group if
+// any, with a svg filter made of the filter primitives equivalent to
+// filters. Filters not defined by draw2dfilter are ignored.
+func (gc *GraphicContext) ApplyFilters(filters ...draw2dfilter.Filter) {
+	primitives := toSvgFilterPrimitives(filters)
+	if len(primitives) == 0 {
+		return
+	}
+	filter := &Filter{
+		X:                         "-50%",
+		Y:                         "-50%",
+		Width:                     "200%",
+		Height:                    "200%",
+		ColorInterpolationFilters: "sRGB",
+		Primitives:                primitives,
+	}
+	gc.svg.Filters = append(gc.svg.Filters, filter)
+	filter.Id = "filter-" + strconv.Itoa(len(gc.svg.Filters))
+	gc.svg.Groups = []*Group{{
+		Filter: "url(#" + filter.Id + ")",
+		Groups: gc.svg.Groups,
+	}}
+}
+
 // NOTE following  two functions and soe other further below copied from dwra2d{img|gl}
 // TODO move them all to common draw2dbase?
 
@@ -154,30 +244,27 @@ func (gc *GraphicContext) ClearRect(x1, y1, x2, y2 int) {
 // and the baseline intersect at x, y. The majority of the affected pixels will be
 // above and to the right of the point, but some may be below or to the left.
 // For example, drawing a string that starts with a 'J' in an italic font may
-// affect pixels below and left of the point.
+// affect pixels below and left of the point. In vertical writing mode x, y is
+// the top of the column on its center line and the height of the column is
+// returned.
 func (gc *GraphicContext) CreateStringPath(s string, x, y float64) (cursor float64) {
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.AddPathFontString(gc, font, gc.pathFontScale(font), s, x, y)
+	}
 	f, err := gc.loadCurrentFont()
 	if err != nil {
 		log.Println(err)
 		return 0.0
 	}
-	startx := x
-	prev, hasPrev := truetype.Index(0), false
-	for _, rune := range s {
-		index := f.Index(rune)
-		if hasPrev {
-			x += fUnitsToFloat64(f.Kern(fixed.Int26_6(gc.Current.Scale), prev, index))
-		}
-		err := gc.drawGlyph(index, x, y)
+	glyphs, width := draw2dbase.ShapeString(f, gc.Current.Scale, gc.Current.WritingMode, s)
+	for _, g := range glyphs {
+		err := draw2dbase.AddGlyph(gc, f, gc.Current.Scale, g, x, y)
 		if err != nil {
 			log.Println(err)
-			return startx - x
+			return -g.X
 		}
-		x += fUnitsToFloat64(f.HMetric(fixed.Int26_6(gc.Current.Scale), index).AdvanceWidth)
-		prev, hasPrev = index, true
 	}
-
-	return x - startx
+	return width
 }
 
 // GetStringBounds returns the approximate pixel bounds of the string s at x, y.
@@ -185,6 +272,10 @@ func (gc *GraphicContext) CreateStringPath(s string, x, y float64) (cursor float
 // and the baseline intersect at 0, 0 in the returned coordinates.
 // Therefore the top and left coordinates may well be negative.
 func (gc *GraphicContext) GetStringBounds(s string) (left, top, right, bottom float64) {
+	if font, ok := gc.loadPathFont(); ok {
+		m := draw2dbase.MeasurePathFont(font, gc.pathFontScale(font), gc.Current.TextDecoration, s)
+		return m.Left, m.Top, m.Right, m.Bottom
+	}
 	f, err := gc.loadCurrentFont()
 	if err != nil {
 		log.Println(err)
@@ -194,14 +285,9 @@ func (gc *GraphicContext) GetStringBounds(s string) (left, top, right, bottom fl
 		panic("zero scale")
 	}
 	top, left, bottom, right = 10e6, 10e6, -10e6, -10e6
-	cursor := 0.0
-	prev, hasPrev := truetype.Index(0), false
-	for _, rune := range s {
-		index := f.Index(rune)
-		if hasPrev {
-			cursor += fUnitsToFloat64(f.Kern(fixed.Int26_6(gc.Current.Scale), prev, index))
-		}
-		if err := gc.glyphBuf.Load(gc.Current.Font, fixed.Int26_6(gc.Current.Scale), index, font.HintingNone); err != nil {
+	glyphs, width := draw2dbase.ShapeString(f, gc.Current.Scale, gc.Current.WritingMode, s)
+	for _, g := range glyphs {
+		if err := gc.glyphBuf.Load(gc.Current.Font, fixed.Int26_6(gc.Current.Scale), g.Index, font.HintingNone); err != nil {
 			log.Println(err)
 			return 0, 0, 0, 0
 		}
@@ -209,19 +295,37 @@ func (gc *GraphicContext) GetStringBounds(s string) (left, top, right, bottom fl
 		for _, e1 := range gc.glyphBuf.Ends {
 			ps := gc.glyphBuf.Points[e0:e1]
 			for _, p := range ps {
-				x, y := pointToF64Point(p)
+				x, y := draw2dbase.GlyphPoint(p, g)
 				top = math.Min(top, y)
 				bottom = math.Max(bottom, y)
-				left = math.Min(left, x+cursor)
-				right = math.Max(right, x+cursor)
+				left = math.Min(left, x)
+				right = math.Max(right, x)
 			}
 		}
-		cursor += fUnitsToFloat64(f.HMetric(fixed.Int26_6(gc.Current.Scale), index).AdvanceWidth)
-		prev, hasPrev = index, true
+	}
+	if gc.Current.TextDecoration != draw2d.TextDecorationNone {
+		m := draw2dbase.GetFontMetrics(f, gc.Current.Scale)
+		if l, t, r, b, ok := draw2dbase.DecorationBounds(m, gc.Current.TextDecoration, gc.Current.WritingMode, width); ok {
+			top, left, bottom, right = math.Min(top, t), math.Min(left, l), math.Max(bottom, b), math.Max(right, r)
+		}
 	}
 	return left, top, right, bottom
 }
 
+// MeasureString returns the advance, ink bounds, font metrics and glyph
+// positions of the text drawn with the current font
+func (gc *GraphicContext) MeasureString(text string) draw2d.TextMetrics {
+	if font, ok := gc.loadPathFont(); ok {
+		return draw2dbase.MeasurePathFont(font, gc.pathFontScale(font), gc.Current.TextDecoration, text)
+	}
+	f, err := gc.loadCurrentFont()
+	if err != nil {
+		log.Println(err)
+		return draw2d.TextMetrics{}
+	}
+	return draw2dbase.MeasureString(f, gc.Current.Scale, gc.Current.WritingMode, gc.Current.TextDecoration, text)
+}
+
 ////////////////////
 // private funcitons
 
@@ -246,9 +350,23 @@ func (gc *GraphicContext) drawPaths(drawType drawType, paths ...*draw2d.Path) {
 
 // Add text element to svg and returns its expected width
 func (gc *GraphicContext) drawString(text string, drawType drawType, x, y float64) float64 {
+	// bitmap and stroke fonts are always drawn as paths
+	if font, ok := gc.loadPathFont(); ok {
+		drawGlyph := draw2dbase.FillGlyph
+		if drawType == stroked {
+			drawGlyph = draw2dbase.StrokeGlyph
+		}
+		return draw2dbase.DrawPathFontString(gc, font, gc.pathFontScale(font), text, x, y, drawGlyph)
+	}
 	switch gc.svg.FontMode {
 	case PathFontMode:
 		w := gc.CreateStringPath(text, x, y)
+		if gc.Current.TextDecoration != draw2d.TextDecorationNone {
+			if f, err := gc.loadCurrentFont(); err == nil {
+				m := draw2dbase.GetFontMetrics(f, gc.Current.Scale)
+				draw2dbase.AddDecorations(gc.Current.Path, m, gc.Current.TextDecoration, gc.Current.WritingMode, x, y, w)
+			}
+		}
 		gc.drawPaths(drawType)
 		gc.Current.Path.Clear()
 		return w
@@ -266,6 +384,54 @@ func (gc *GraphicContext) drawString(text string, drawType drawType, x, y float6
 	svgText.X = x
 	svgText.Y = y
 	svgText.FontFamily = gc.Current.FontData.Name
+	if gc.Current.TextDecoration != draw2d.TextDecorationNone {
+		svgText.TextDecoration = gc.Current.TextDecoration.String()
+	}
+
+	// attach to group
+	group.Texts = []*Text{&svgText}
+	left, top, right, bottom := gc.GetStringBounds(text)
+	if gc.Current.WritingMode == draw2d.WritingModeVertical {
+		svgText.WritingMode = gc.Current.WritingMode.String()
+		return bottom - top
+	}
+	return right - left
+}
+
+// Add text element laid out along path to svg and returns its expected width
+func (gc *GraphicContext) drawStringOnPath(text string, drawType drawType, path *draw2d.Path, offset float64) float64 {
+	switch gc.svg.FontMode {
+	case PathFontMode:
+		f, err := gc.loadCurrentFont()
+		if err != nil {
+			log.Println(err)
+			return 0.0
+		}
+		drawGlyph := draw2dbase.FillGlyph
+		if drawType == stroked {
+			drawGlyph = draw2dbase.StrokeGlyph
+		}
+		return draw2dbase.DrawStringOnPath(gc, gc.glyphCache, f, gc.Current.Scale, text, path, offset, drawGlyph)
+	case SvgFontMode:
+		gc.embedSvgFont(text)
+	}
+
+	// create elements
+	defPath := gc.newDefPath(path)
+	svgText := Text{}
+	group := gc.newGroup(drawType)
+
+	// set attrs to text element
+	svgText.FontSize = gc.Current.FontSize
+	svgText.FontFamily = gc.Current.FontData.Name
+	if gc.Current.TextPathAnchor != draw2d.TextAnchorStart {
+		svgText.TextAnchor = gc.Current.TextPathAnchor.String()
+	}
+	svgText.TextPath = &TextPath{
+		Href:        "#" + defPath.Id,
+		StartOffset: toSvgLength(offset),
+		Text:        text,
+	}
 
 	// attach to group
 	group.Texts = []*Text{&svgText}
@@ -273,6 +439,16 @@ func (gc *GraphicContext) drawString(text string, drawType drawType, x, y float6
 	return right - left
 }
 
+// creates new path definition attached to svg, to be referenced by id
+func (gc *GraphicContext) newDefPath(path *draw2d.Path) *Path {
+	defPath := &Path{Desc: toSvgPathDesc(path)}
+
+	// attach path
+	gc.svg.DefPaths = append(gc.svg.DefPaths, defPath)
+	defPath.Id = "path-" + strconv.Itoa(len(gc.svg.DefPaths))
+	return defPath
+}
+
 // Creates new group from current context
 // attach it to svg and return
 func (gc *GraphicContext) newGroup(drawType drawType) *Group {
@@ -295,13 +471,98 @@ func (gc *GraphicContext) newGroup(drawType drawType) *Group {
 	}
 
 	group.Transform = toSvgTransform(gc.Current.Tr)
+	group.ShapeRendering = toSvgShapeRendering(gc.Current.Antialias, gc.Current.CrispEdges)
 
 	// attach
-	gc.svg.Groups = append(gc.svg.Groups, &group)
+	attached := &group
+	if gc.Current.HasShadow() {
+		// the filter is applied to a group without transformation, in which
+		// the shadow is in device space
+		attached = &Group{
+			Filter: "url(#" + gc.newShadowFilter().Id + ")",
+			Groups: []*Group{attached},
+		}
+	}
+	if gc.Current.Mask != nil {
+		// the mask is applied to a group without transformation, as the
+		// shadow
+		attached = &Group{
+			Mask:   "url(#" + gc.currentMaskId() + ")",
+			Groups: []*Group{attached},
+		}
+	}
+	gc.svg.Groups = append(gc.svg.Groups, attached)
 
 	return &group
 }
 
+// currentMaskId returns the id of the svg mask of the current mask, which
+// is attached to svg the first time it is drawn with
+func (gc *GraphicContext) currentMaskId() string {
+	if gc.mask != gc.Current.Mask {
+		gc.mask, gc.maskId = gc.Current.Mask, gc.newContentMask(gc.Current.Mask).Id
+	}
+	return gc.maskId
+}
+
+// creates new mask of the content of m, in device space, attached to svg
+func (gc *GraphicContext) newContentMask(m *draw2dbase.Mask) *Mask {
+	content := &Group{Transform: toSvgTransform(m.Tr)}
+	mask := &Mask{Groups: []*Group{content}}
+	x0, y0, x1, y1, ok := m.Bounds()
+	if !ok {
+		// an empty mask masks out everything
+		x0, y0, x1, y1 = 0, 0, 0, 0
+	}
+	mask.X, mask.Y = x0, y0
+	mask.Width, mask.Height = toSvgLength(x1-x0), toSvgLength(y1-y0)
+	if m.Image != nil {
+		bounds := m.Image.Bounds()
+		content.Image = &Image{Href: imageToSvgHref(m.Image)}
+		content.Image.X, content.Image.Y = float64(bounds.Min.X), float64(bounds.Min.Y)
+		content.Image.Width, content.Image.Height = toSvgLength(float64(bounds.Dx())), toSvgLength(float64(bounds.Dy()))
+		if !m.Luminance {
+			mask.MaskType = "alpha"
+		}
+	} else {
+		descs := make([]string, len(m.Paths))
+		for i, path := range m.Paths {
+			descs[i] = toSvgPathDesc(path)
+		}
+		content.Fill, content.FillRule = "#FFFFFF", toSvgFillRule(m.FillRule)
+		content.Paths = []*Path{{Desc: strings.Join(descs, " ")}}
+	}
+
+	// attach mask
+	gc.svg.Masks = append(gc.svg.Masks, mask)
+	mask.Id = "mask-" + strconv.Itoa(len(gc.svg.Masks))
+	return mask
+}
+
+// creates new drop shadow filter of the current shadow attached to svg
+func (gc *GraphicContext) newShadowFilter() *Filter {
+	r, g, b, a := gc.Current.ShadowColor.RGBA()
+	floodColor := optiSprintf("#%02X%02X%02X", r*0xff/a, g*0xff/a, b*0xff/a)
+	filter := &Filter{
+		X:      "-50%",
+		Y:      "-50%",
+		Width:  "200%",
+		Height: "200%",
+		DropShadow: &DropShadow{
+			Dx:           gc.Current.ShadowOffsetX,
+			Dy:           gc.Current.ShadowOffsetY,
+			StdDeviation: gc.Current.ShadowBlur / 2,
+			FloodColor:   floodColor,
+		},
+	}
+	if a != 0xffff {
+		filter.DropShadow.FloodOpacity = optiSprintf("%f", float64(a)/0xffff)
+	}
+	gc.svg.Filters = append(gc.svg.Filters, filter)
+	filter.Id = "shadow-" + strconv.Itoa(len(gc.svg.Filters))
+	return filter
+}
+
 // creates new mask attached to svg
 func (gc *GraphicContext) newMask(x, y, width, height int) *Mask {
 	mask := &Mask{}
@@ -383,16 +644,16 @@ func (gc *GraphicContext) loadCurrentFont() (*truetype.Font, error) {
 	return font, err
 }
 
-func (gc *GraphicContext) drawGlyph(glyph truetype.Index, dx, dy float64) error {
-	if err := gc.glyphBuf.Load(gc.Current.Font, fixed.Int26_6(gc.Current.Scale), glyph, font.HintingNone); err != nil {
-		return err
-	}
-	e0 := 0
-	for _, e1 := range gc.glyphBuf.Ends {
-		DrawContour(gc, gc.glyphBuf.Points[e0:e1], dx, dy)
-		e0 = e1
-	}
-	return nil
+// loadPathFont returns the bitmap or stroke font selected by the current font
+// data, which is used instead of a TrueType font
+func (gc *GraphicContext) loadPathFont() (draw2d.PathFont, bool) {
+	return draw2dbase.LoadPathFont(gc.FontCache, gc.Current.FontData)
+}
+
+// pathFontScale returns the size in pixels of the font units of font drawn
+// with the current font size
+func (gc *GraphicContext) pathFontScale(font draw2d.PathFont) float64 {
+	return draw2dbase.PathFontScale(font, gc.Current.FontSize*float64(gc.DPI)/72)
 }
 
 // recalc recalculates scale and bounds values from the font size, screen
@@ -33,15 +33,17 @@ const (
 )
 
 type Svg struct {
-	XMLName  xml.Name `xml:"svg"`
-	Xmlns    string   `xml:"xmlns,attr"`
-	Width    string   `xml:"width,attr,omitempty"`
-	Height   string   `xml:"height,attr,omitempty"`
-	ViewBox  string   `xml:"viewBox,attr,omitempty"`
-	Fonts    []*Font  `xml:"defs>font"`
-	Masks    []*Mask  `xml:"defs>mask"`
-	Groups   []*Group `xml:"g"`
-	FontMode FontMode `xml:"-"`
+	XMLName  xml.Name  `xml:"svg"`
+	Xmlns    string    `xml:"xmlns,attr"`
+	Width    string    `xml:"width,attr,omitempty"`
+	Height   string    `xml:"height,attr,omitempty"`
+	ViewBox  string    `xml:"viewBox,attr,omitempty"`
+	Fonts    []*Font   `xml:"defs>font"`
+	Masks    []*Mask   `xml:"defs>mask"`
+	DefPaths []*Path   `xml:"defs>path"`
+	Filters  []*Filter `xml:"defs>filter"`
+	Groups   []*Group  `xml:"g"`
+	FontMode FontMode  `xml:"-"`
 	FillStroke
 }
 
@@ -55,15 +57,20 @@ func NewSvg() *Svg {
 
 type Group struct {
 	FillStroke
-	Transform string   `xml:"transform,attr,omitempty"`
-	Groups    []*Group `xml:"g"`
-	Paths     []*Path  `xml:"path"`
-	Texts     []*Text  `xml:"text"`
-	Image     *Image   `xml:"image"`
-	Mask      string   `xml:"mask,attr,omitempty"`
+	Transform      string   `xml:"transform,attr,omitempty"`
+	ShapeRendering string   `xml:"shape-rendering,attr,omitempty"`
+	Opacity        string   `xml:"opacity,attr,omitempty"`
+	Style          string   `xml:"style,attr,omitempty"`
+	Groups         []*Group `xml:"g"`
+	Paths          []*Path  `xml:"path"`
+	Texts          []*Text  `xml:"text"`
+	Image          *Image   `xml:"image"`
+	Mask           string   `xml:"mask,attr,omitempty"`
+	Filter         string   `xml:"filter,attr,omitempty"`
 }
 
 type Path struct {
+	Id string `xml:"id,attr,omitempty"`
 	FillStroke
 	Desc string `xml:"d,attr"`
 }
@@ -71,10 +78,21 @@ type Path struct {
 type Text struct {
 	FillStroke
 	Position
-	FontSize   float64 `xml:"font-size,attr,omitempty"`
-	FontFamily string  `xml:"font-family,attr,omitempty"`
-	Text       string  `xml:",innerxml"`
-	Style      string  `xml:"style,attr,omitempty"`
+	FontSize       float64   `xml:"font-size,attr,omitempty"`
+	FontFamily     string    `xml:"font-family,attr,omitempty"`
+	TextAnchor     string    `xml:"text-anchor,attr,omitempty"`
+	TextDecoration string    `xml:"text-decoration,attr,omitempty"`
+	WritingMode    string    `xml:"writing-mode,attr,omitempty"`
+	Text           string    `xml:",innerxml"`
+	TextPath       *TextPath `xml:"textPath"`
+	Style          string    `xml:"style,attr,omitempty"`
+}
+
+// TextPath lays out its text along the path referenced by Href
+type TextPath struct {
+	Href        string `xml:"href,attr"`
+	StartOffset string `xml:"startOffset,attr,omitempty"`
+	Text        string `xml:",chardata"`
 }
 
 type Image struct {
@@ -83,10 +101,70 @@ type Image struct {
 	Href string `xml:"href,attr"`
 }
 
+// Mask is a mask of its content, or the mask clearing its rectangle, as by
+// ClearRect, if it has no content
 type Mask struct {
 	Identity
 	Position
 	Dimension
+	// MaskType is "alpha" if the alpha of the content modulates the masked
+	// drawings instead of its luminance
+	MaskType string
+	// Groups are the content of the mask, in the user space of the masked
+	// group and within its rectangle
+	Groups []*Group
+}
+
+// Filter is a filter casting a drop shadow or made of the filter primitives
+// of draw2dfilter, applied to a group in device space. Its region is
+// enlarged to the shadow or to the blur.
+type Filter struct {
+	Id                        string      `xml:"id,attr"`
+	X                         string      `xml:"x,attr"`
+	Y                         string      `xml:"y,attr"`
+	Width                     string      `xml:"width,attr"`
+	Height                    string      `xml:"height,attr"`
+	ColorInterpolationFilters string      `xml:"color-interpolation-filters,attr,omitempty"`
+	DropShadow                *DropShadow `xml:"feDropShadow"`
+	// Primitives are the filter primitives applied in order, of types
+	// FeGaussianBlur, FeColorMatrix, FeMorphology and FeConvolveMatrix
+	Primitives []interface{}
+}
+
+// DropShadow is the drop shadow of a filter
+type DropShadow struct {
+	Dx           float64 `xml:"dx,attr"`
+	Dy           float64 `xml:"dy,attr"`
+	StdDeviation float64 `xml:"stdDeviation,attr"`
+	FloodColor   string  `xml:"flood-color,attr"`
+	FloodOpacity string  `xml:"flood-opacity,attr,omitempty"`
+}
+
+type FeGaussianBlur struct {
+	XMLName      xml.Name `xml:"feGaussianBlur"`
+	StdDeviation string   `xml:"stdDeviation,attr"`
+}
+
+type FeColorMatrix struct {
+	XMLName xml.Name `xml:"feColorMatrix"`
+	Type    string   `xml:"type,attr"`
+	Values  string   `xml:"values,attr"`
+}
+
+type FeMorphology struct {
+	XMLName  xml.Name `xml:"feMorphology"`
+	Operator string   `xml:"operator,attr"`
+	Radius   string   `xml:"radius,attr"`
+}
+
+type FeConvolveMatrix struct {
+	XMLName       xml.Name `xml:"feConvolveMatrix"`
+	Order         string   `xml:"order,attr"`
+	KernelMatrix  string   `xml:"kernelMatrix,attr"`
+	Divisor       string   `xml:"divisor,attr,omitempty"`
+	Bias          string   `xml:"bias,attr,omitempty"`
+	EdgeMode      string   `xml:"edgeMode,attr"`
+	PreserveAlpha bool     `xml:"preserveAlpha,attr"`
 }
 
 type Rect struct {
@@ -96,6 +174,26 @@ type Rect struct {
 }
 
 func (m Mask) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if len(m.Groups) > 0 {
+		return e.EncodeElement(struct {
+			XMLName   xml.Name `xml:"mask"`
+			Id        string   `xml:"id,attr"`
+			MaskUnits string   `xml:"maskUnits,attr"`
+			X         float64  `xml:"x,attr"`
+			Y         float64  `xml:"y,attr"`
+			Dimension
+			MaskType string   `xml:"mask-type,attr,omitempty"`
+			Groups   []*Group `xml:"g"`
+		}{
+			Id:        m.Id,
+			MaskUnits: "userSpaceOnUse",
+			X:         m.X,
+			Y:         m.Y,
+			Dimension: m.Dimension,
+			MaskType:  m.MaskType,
+			Groups:    m.Groups,
+		}, start)
+	}
 	bigRect := Rect{}
 	bigRect.X, bigRect.Y = 0, 0
 	bigRect.Width, bigRect.Height = "100%", "100%"
@@ -8,7 +8,14 @@ package draw2dsvg
 
 import (
 	"encoding/xml"
+	"image"
+	"image/color"
+	"strings"
 	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dfilter"
+	"github.com/llgcode/draw2d/draw2dkit"
 )
 
 // Test basic encoding of svg/xml elements
@@ -106,3 +113,263 @@ func TestXml_ViewBox(t *testing.T) {
 	svg.ViewBox = "0 100 -10 -100"
 	t.Run("with viewBox", subtest)
 }
+
+func TestXml_TextPath(t *testing.T) {
+	svg := NewSvg()
+	gc := NewGraphicContext(svg)
+	svg.FontMode = SysFontMode
+	gc.SetTextPathAnchor(draw2d.TextAnchorMiddle)
+	path := &draw2d.Path{}
+	path.MoveTo(0, 0)
+	path.LineTo(100, 0)
+	gc.FillStringOnPath("a<b", path, 50)
+
+	out, err := xml.Marshal(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<defs><path id="path-1" d="M 0,0 L 100,0"></path></defs>`,
+		`text-anchor="middle"`,
+		`<textPath href="#path-1" startOffset="50">a&lt;b</textPath>`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("svg output does not contain %s\ngot:\n%s", want, out)
+		}
+	}
+}
+
+func TestXml_TextDecoration(t *testing.T) {
+	svg := NewSvg()
+	gc := NewGraphicContext(svg)
+	svg.FontMode = SysFontMode
+	gc.SetTextDecoration(draw2d.TextDecorationUnderline | draw2d.TextDecorationLineThrough)
+	gc.FillStringAt("decorated", 10, 20)
+
+	out, err := xml.Marshal(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `text-decoration="underline line-through"`; !strings.Contains(string(out), want) {
+		t.Errorf("svg output does not contain %s\ngot:\n%s", want, out)
+	}
+}
+
+func TestXml_TextDecorationPathFontMode(t *testing.T) {
+	countSubpaths := func(decoration draw2d.TextDecoration) int {
+		svg := NewSvg()
+		gc := NewGraphicContext(svg)
+		svg.FontMode = PathFontMode
+		gc.SetTextDecoration(decoration)
+		gc.FillStringAt("l", 10, 20)
+		return strings.Count(svg.Groups[0].Paths[0].Desc, "M")
+	}
+	if plain, decorated := countSubpaths(draw2d.TextDecorationNone), countSubpaths(draw2d.TextDecorationOverline); decorated != plain+1 {
+		t.Errorf("the overline should add a subpath, got %d then %d", plain, decorated)
+	}
+}
+
+func TestXml_WritingMode(t *testing.T) {
+	svg := NewSvg()
+	gc := NewGraphicContext(svg)
+	svg.FontMode = SysFontMode
+	gc.SetWritingMode(draw2d.WritingModeVertical)
+	gc.FillStringAt("縦書き", 10, 20)
+
+	out, err := xml.Marshal(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `writing-mode="vertical-rl"`; !strings.Contains(string(out), want) {
+		t.Errorf("svg output does not contain %s\ngot:\n%s", want, out)
+	}
+}
+
+func TestXml_StrokeFont(t *testing.T) {
+	font, err := draw2d.ParseHershey([]byte("12345  1JZ\n12345  6MWRFRT RRYRZ\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := NewSvg()
+	gc := NewGraphicContext(svg)
+	cache := draw2d.NewFolderFontCache(t.TempDir())
+	fontData := draw2d.FontData{Name: "hershey"}
+	cache.StorePathFont(fontData, font)
+	gc.FontCache = cache
+	svg.FontMode = SysFontMode
+	gc.SetFontData(fontData)
+	gc.FillStringAt("!", 10, 20)
+
+	out, err := xml.Marshal(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stroke fonts are drawn as stroked paths, even in SysFontMode
+	if strings.Contains(string(out), "<text") || !strings.Contains(string(out), `fill="none"`) || !strings.Contains(string(out), "<path") {
+		t.Errorf("expected a stroked path\ngot:\n%s", out)
+	}
+}
+
+func TestXml_ShapeRendering(t *testing.T) {
+	svg := NewSvg()
+	gc := NewGraphicContext(svg)
+	draw2dkit.Rectangle(gc, 10, 10, 20, 20)
+	gc.Fill()
+	gc.SetAntialias(false)
+	draw2dkit.Rectangle(gc, 10, 10, 20, 20)
+	gc.Fill()
+	gc.SetCrispEdges(true)
+	draw2dkit.Rectangle(gc, 10, 10, 20, 20)
+	gc.Stroke()
+
+	for i, want := range []string{"", "crispEdges", "crispEdges"} {
+		if got := svg.Groups[i].ShapeRendering; got != want {
+			t.Errorf("group %d has shape-rendering %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestXml_Group(t *testing.T) {
+	svg := NewSvg()
+	gc := NewGraphicContext(svg)
+	draw2dkit.Rectangle(gc, 10, 10, 20, 20)
+	gc.Fill()
+	gc.PushGroup()
+	draw2dkit.Rectangle(gc, 10, 10, 20, 20)
+	gc.Fill()
+	gc.PushGroup()
+	draw2dkit.Circle(gc, 15, 15, 5)
+	gc.Stroke()
+	gc.PopGroup(1, draw2d.BlendNormal)
+	gc.PopGroup(0.5, draw2d.BlendMultiply)
+
+	if len(svg.Groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(svg.Groups))
+	}
+	group := svg.Groups[1]
+	if group.Opacity != "0.5" || group.Style != "mix-blend-mode: multiply" {
+		t.Errorf("got opacity %q and style %q, want \"0.5\" and \"mix-blend-mode: multiply\"", group.Opacity, group.Style)
+	}
+	if len(group.Groups) != 2 || len(group.Groups[1].Groups) != 1 {
+		t.Fatal("the drawings of the groups should be nested in their svg groups")
+	}
+	if nested := group.Groups[1]; nested.Opacity != "" || nested.Style != "" {
+		t.Errorf("an opaque normal group should not have opacity %q nor style %q", nested.Opacity, nested.Style)
+	}
+}
+
+func TestXml_Shadow(t *testing.T) {
+	svg := NewSvg()
+	gc := NewGraphicContext(svg)
+	gc.SetShadow(2, 3, 4, color.NRGBA{0, 0, 0xff, 0x80})
+	gc.Translate(10, 10)
+	draw2dkit.Rectangle(gc, 10, 10, 20, 20)
+	gc.Fill()
+
+	if len(svg.Filters) != 1 || len(svg.Groups) != 1 {
+		t.Fatalf("got %d filters and %d groups, want 1 and 1", len(svg.Filters), len(svg.Groups))
+	}
+	filter, group := svg.Filters[0], svg.Groups[0]
+	if group.Filter != "url(#"+filter.Id+")" || group.Transform != "" || len(group.Groups) != 1 {
+		t.Error("the shape should be in a group without transformation filtered by the shadow")
+	}
+	want := DropShadow{Dx: 2, Dy: 3, StdDeviation: 2, FloodColor: "#0000FF", FloodOpacity: "0.502"}
+	if *filter.DropShadow != want {
+		t.Errorf("got drop shadow %+v, want %+v", *filter.DropShadow, want)
+	}
+	out, err := xml.Marshal(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `<feDropShadow dx="2" dy="3" stdDeviation="2" flood-color="#0000FF" flood-opacity="0.502">`) {
+		t.Errorf("unexpected svg %s", out)
+	}
+}
+
+func TestXml_Filters(t *testing.T) {
+	svg := NewSvg()
+	gc := NewGraphicContext(svg)
+	draw2dkit.Rectangle(gc, 10, 10, 20, 20)
+	gc.Fill()
+	gc.ApplyFilters(draw2dfilter.GaussianBlur{StdDeviationX: 2, StdDeviationY: 1}, draw2dfilter.Grayscale(1),
+		draw2dfilter.Morphology{Operator: draw2dfilter.Dilate, RadiusX: 1, RadiusY: 2}, draw2dfilter.BoxBlur{RadiusX: 1})
+
+	if len(svg.Filters) != 1 || len(svg.Groups) != 1 {
+		t.Fatalf("got %d filters and %d groups, want 1 and 1", len(svg.Filters), len(svg.Groups))
+	}
+	if group := svg.Groups[0]; group.Filter != "url(#"+svg.Filters[0].Id+")" || len(group.Groups) != 1 {
+		t.Error("the drawings should be in a group filtered by the filters")
+	}
+	out, err := xml.Marshal(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`color-interpolation-filters="sRGB"`,
+		`<feGaussianBlur stdDeviation="2 1">`,
+		`<feColorMatrix type="matrix" values="0.2126 0.7152 0.0722 0 0 0.2126 0.7152 0.0722 0 0 0.2126 0.7152 0.0722 0 0 0 0 0 1 0">`,
+		`<feMorphology operator="dilate" radius="1 2">`,
+		`<feConvolveMatrix order="3 1" kernelMatrix="1 1 1" edgeMode="none" preserveAlpha="false">`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("%s not found in svg %s", want, out)
+		}
+	}
+}
+
+func TestXml_Mask(t *testing.T) {
+	svg := NewSvg()
+	gc := NewGraphicContext(svg)
+	gc.Save()
+	gc.SetMask(image.NewAlpha(image.Rect(0, 0, 10, 20)), draw2d.NewTranslationMatrix(5, 5))
+	draw2dkit.Rectangle(gc, 10, 10, 20, 20)
+	gc.Fill()
+	gc.Stroke()
+	gc.Restore()
+	draw2dkit.Circle(gc, 10, 10, 5)
+	gc.MaskWithPath()
+	draw2dkit.Rectangle(gc, 10, 10, 20, 20)
+	gc.Fill()
+
+	if len(svg.Masks) != 2 || len(svg.Groups) != 3 {
+		t.Fatalf("got %d masks and %d groups, want 2 and 3", len(svg.Masks), len(svg.Groups))
+	}
+	for i, id := range []string{"mask-1", "mask-1", "mask-2"} {
+		if group := svg.Groups[i]; group.Mask != "url(#"+id+")" || len(group.Groups) != 1 {
+			t.Errorf("group %d should be in a group masked by %s", i, id)
+		}
+	}
+	out, err := xml.Marshal(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<mask id="mask-1" maskUnits="userSpaceOnUse" x="5" y="5" width="10" height="20" mask-type="alpha"><g transform="translate(5,5)"><image width="10" height="20" href="data:image/png;base64,`,
+		`<mask id="mask-2" maskUnits="userSpaceOnUse" x="5" y="5" width="10" height="10"><g fill="#FFFFFF" fill-rule="evenodd"><path d="M 15,10`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("%s not found in svg %s", want, out)
+		}
+	}
+}
+
+func TestXml_DrawImageRect(t *testing.T) {
+	svg := NewSvg()
+	gc := NewGraphicContext(svg)
+	gc.Translate(5, 0)
+	gc.DrawImageRect(image.NewRGBA(image.Rect(0, 0, 20, 20)), image.Rect(10, 0, 20, 10), [4]float64{0, 0, 30, 20})
+
+	if len(svg.Groups) != 1 || svg.Groups[0].Image == nil {
+		t.Fatal("the image should be drawn in a group")
+	}
+	group := svg.Groups[0]
+	if want := "matrix(3,0,0,2,-25,0)"; group.Transform != want {
+		t.Errorf("got transform %q, want %q", group.Transform, want)
+	}
+	if img := group.Image; img.X != 10 || img.Y != 0 || img.Width != "10" || img.Height != "10" {
+		t.Errorf("got image %v %v %v %v, want the source rectangle", img.X, img.Y, img.Width, img.Height)
+	}
+	if tr := gc.GetMatrixTransform(); !tr.Equals(draw2d.NewTranslationMatrix(5, 0)) {
+		t.Errorf("the transformation should be restored, got %v", tr)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"weak"
 
 	"github.com/golang/freetype/truetype"
 	"sync"
@@ -21,6 +23,9 @@ const (
 	FontStyleNormal FontStyle = iota
 	FontStyleBold
 	FontStyleItalic
+	// FontStyleOblique selects a slanted face. When no oblique face is
+	// available an italic one is used instead, and vice versa.
+	FontStyleOblique FontStyle = 4
 )
 
 type FontFamily byte
@@ -31,10 +36,84 @@ const (
 	FontFamilyMono
 )
 
+// FontWeight is the numeric weight of a font face, from 100 (thin) to 900
+// (black), as in CSS. The zero value means the weight is derived from the
+// FontStyleBold bit of the FontData style.
+type FontWeight int
+
+const (
+	FontWeightThin       FontWeight = 100
+	FontWeightExtraLight FontWeight = 200
+	FontWeightLight      FontWeight = 300
+	FontWeightNormal     FontWeight = 400
+	FontWeightMedium     FontWeight = 500
+	FontWeightSemiBold   FontWeight = 600
+	FontWeightBold       FontWeight = 700
+	FontWeightExtraBold  FontWeight = 800
+	FontWeightBlack      FontWeight = 900
+)
+
+// FontStretch is the width of a font face, as in CSS. The zero value means
+// FontStretchNormal.
+type FontStretch int
+
+const (
+	FontStretchUltraCondensed FontStretch = iota + 1
+	FontStretchExtraCondensed
+	FontStretchCondensed
+	FontStretchSemiCondensed
+	FontStretchNormal
+	FontStretchSemiExpanded
+	FontStretchExpanded
+	FontStretchExtraExpanded
+	FontStretchUltraExpanded
+)
+
 type FontData struct {
-	Name   string
-	Family FontFamily
-	Style  FontStyle
+	Name    string
+	Family  FontFamily
+	Style   FontStyle
+	Weight  FontWeight
+	Stretch FontStretch
+}
+
+// ResolvedWeight returns the weight of the font, falling back to
+// FontWeightBold or FontWeightNormal depending on the style when Weight is
+// not set.
+func (fontData FontData) ResolvedWeight() FontWeight {
+	if fontData.Weight != 0 {
+		return fontData.Weight
+	}
+	if fontData.Style&FontStyleBold != 0 {
+		return FontWeightBold
+	}
+	return FontWeightNormal
+}
+
+// ResolvedStretch returns the stretch of the font, FontStretchNormal when
+// Stretch is not set.
+func (fontData FontData) ResolvedStretch() FontStretch {
+	if fontData.Stretch == 0 {
+		return FontStretchNormal
+	}
+	return fontData.Stretch
+}
+
+// normalize returns the canonical form of fontData, so that equivalent
+// descriptions of the same face compare equal.
+func (fontData FontData) normalize() FontData {
+	weight := fontData.ResolvedWeight()
+	style := fontData.Style &^ FontStyleBold
+	if weight >= FontWeightSemiBold {
+		style |= FontStyleBold
+	}
+	return FontData{
+		Name:    fontData.Name,
+		Family:  fontData.Family,
+		Style:   style,
+		Weight:  weight,
+		Stretch: fontData.ResolvedStretch(),
+	}
 }
 
 type FontFileNamer func(fontData FontData) string
@@ -49,13 +128,13 @@ func FontFileName(fontData FontData) string {
 	case FontFamilyMono:
 		fontFileName += "m"
 	}
-	if fontData.Style&FontStyleBold != 0 {
+	if fontData.ResolvedWeight() >= FontWeightSemiBold {
 		fontFileName += "b"
 	} else {
 		fontFileName += "r"
 	}
 
-	if fontData.Style&FontStyleItalic != 0 {
+	if fontData.Style&(FontStyleItalic|FontStyleOblique) != 0 {
 		fontFileName += "i"
 	}
 	fontFileName += ".ttf"
@@ -76,6 +155,32 @@ func GetFont(fontData FontData) (font *truetype.Font) {
 	return
 }
 
+// ParseFont parses TrueType font data like truetype.Parse and keeps the data,
+// so that the tables the truetype package does not expose (line gap, cap
+// height, underline position, ...) can be read with GetFontFileData. The
+// data is kept as long as the font is.
+func ParseFont(data []byte) (*truetype.Font, error) {
+	font, err := truetype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	key := weak.Make(font)
+	fontFileData.Store(key, data)
+	runtime.AddCleanup(font, func(key weak.Pointer[truetype.Font]) {
+		fontFileData.Delete(key)
+	}, key)
+	return font, nil
+}
+
+// GetFontFileData returns the data font was parsed from with ParseFont, or
+// nil if it was parsed by other means.
+func GetFontFileData(font *truetype.Font) []byte {
+	if data, ok := fontFileData.Load(weak.Make(font)); ok {
+		return data.([]byte)
+	}
+	return nil
+}
+
 func GetFontFolder() string {
 	return defaultFonts.folder
 }
@@ -118,112 +223,187 @@ func SetFontCache(cache FontCache) {
 	}
 }
 
-// FolderFontCache can Load font from folder
-type FolderFontCache struct {
-	fonts  map[string]*truetype.Font
-	folder string
-	namer  FontFileNamer
+// folderFonts holds the fonts loaded from a folder and the faces registered
+// with Store, and selects the closest face when an exact one is missing.
+// It is not safe for concurrent use.
+type folderFonts struct {
+	fonts   map[string]*truetype.Font
+	faces   map[FontData]*truetype.Font
+	matches map[FontData]FontData
+	folder  string
+	namer   FontFileNamer
+	// pathFonts holds the bitmap and stroke fonts, nil for the faces that
+	// have none
+	pathFonts map[FontData]PathFont
 }
 
-// NewFolderFontCache creates FolderFontCache
-func NewFolderFontCache(folder string) *FolderFontCache {
-	return &FolderFontCache{
-		fonts:  make(map[string]*truetype.Font),
-		folder: folder,
-		namer:  FontFileName,
+func newFolderFonts(folder string) folderFonts {
+	return folderFonts{
+		fonts:   make(map[string]*truetype.Font),
+		faces:   make(map[FontData]*truetype.Font),
+		matches: make(map[FontData]FontData),
+		folder:  folder,
+		namer:   FontFileName,
+
+		pathFonts: make(map[FontData]PathFont),
 	}
 }
 
-// Load a font from cache if exists otherwise it will load the font from file
-func (cache *FolderFontCache) Load(fontData FontData) (font *truetype.Font, err error) {
-	if font = cache.fonts[cache.namer(fontData)]; font != nil {
-		return font, nil
+// cached returns the font already selected for fontData, if any.
+func (ff *folderFonts) cached(fontData FontData) *truetype.Font {
+	key := fontData.normalize()
+	if font := ff.faces[key]; font != nil {
+		return font
 	}
+	if match, ok := ff.matches[key]; ok {
+		if font := ff.faces[match]; font != nil {
+			return font
+		}
+		return ff.fonts[ff.namer(match)]
+	}
+	return nil
+}
 
-	var data []byte
-	var file = cache.namer(fontData)
-
-	if data, err = os.ReadFile(filepath.Join(cache.folder, file)); err != nil {
-		return
+// candidates lists the faces that can be loaded for the name and family of
+// fontData: the registered ones and the regular, bold, italic and bold italic
+// files present in the folder.
+func (ff *folderFonts) candidates(fontData FontData) []FontData {
+	var available []FontData
+	for face := range ff.faces {
+		if face.Name == fontData.Name && face.Family == fontData.Family {
+			available = append(available, face)
+		}
 	}
+	for _, style := range []FontStyle{FontStyleNormal, FontStyleBold, FontStyleItalic, FontStyleBold | FontStyleItalic} {
+		face := FontData{Name: fontData.Name, Family: fontData.Family, Style: style}.normalize()
+		file := ff.namer(face)
+		if ff.fonts[file] != nil {
+			available = append(available, face)
+		} else if _, err := os.Stat(filepath.Join(ff.folder, file)); err == nil {
+			available = append(available, face)
+		}
+	}
+	return available
+}
 
-	if font, err = truetype.Parse(data); err != nil {
-		return
+// load selects the closest available face for fontData and loads it from
+// the folder if necessary.
+func (ff *folderFonts) load(fontData FontData) (font *truetype.Font, err error) {
+	key := fontData.normalize()
+	if font = ff.faces[key]; font != nil {
+		return font, nil
+	}
+	match := key
+	if m, ok := MatchFont(key, ff.candidates(key)); ok {
+		match = m
 	}
+	if font = ff.faces[match]; font == nil {
+		var data []byte
+		var file = ff.namer(match)
+		if font = ff.fonts[file]; font == nil {
+			if data, err = os.ReadFile(filepath.Join(ff.folder, file)); err != nil {
+				return
+			}
+			if font, err = ParseFont(data); err != nil {
+				return
+			}
+			ff.fonts[file] = font
+		}
+	}
+	// exact matches are remembered too, so that cached finds them without
+	// listing the candidates again
+	ff.matches[key] = match
+	return font, nil
+}
 
-	cache.fonts[file] = font
-	return
+func (ff *folderFonts) store(fontData FontData, font *truetype.Font) {
+	ff.faces[fontData.normalize()] = font
+	// a new face may be a better match for previous requests
+	ff.matches = make(map[FontData]FontData)
+}
+
+// FolderFontCache can Load font from folder
+type FolderFontCache struct {
+	folderFonts
+}
+
+// NewFolderFontCache creates FolderFontCache
+func NewFolderFontCache(folder string) *FolderFontCache {
+	return &FolderFontCache{newFolderFonts(folder)}
+}
+
+// Load a font from cache if exists otherwise it will load the font from file.
+// When there is no face with the exact weight, stretch and style of fontData,
+// the closest one is returned as selected by MatchFont.
+func (cache *FolderFontCache) Load(fontData FontData) (font *truetype.Font, err error) {
+	if font = cache.cached(fontData); font != nil {
+		return font, nil
+	}
+	return cache.load(fontData)
 }
 
 // Store a font to this cache
 func (cache *FolderFontCache) Store(fontData FontData, font *truetype.Font) {
-	cache.fonts[cache.namer(fontData)] = font
+	cache.store(fontData, font)
 }
 
 // SyncFolderFontCache can Load font from folder
 type SyncFolderFontCache struct {
 	sync.RWMutex
-	fonts  map[string]*truetype.Font
-	folder string
-	namer  FontFileNamer
+	folderFonts
 }
 
 // NewSyncFolderFontCache creates SyncFolderFontCache
 func NewSyncFolderFontCache(folder string) *SyncFolderFontCache {
-	return &SyncFolderFontCache{
-		fonts:  make(map[string]*truetype.Font),
-		folder: folder,
-		namer:  FontFileName,
-	}
+	return &SyncFolderFontCache{folderFonts: newFolderFonts(folder)}
 }
 
 func (cache *SyncFolderFontCache) setFolder(folder string) {
 	cache.Lock()
 	cache.folder = folder
+	cache.matches = make(map[FontData]FontData)
+	cache.forgetMissingPathFonts()
 	cache.Unlock()
 }
 
 func (cache *SyncFolderFontCache) setNamer(namer FontFileNamer) {
 	cache.Lock()
 	cache.namer = namer
+	cache.matches = make(map[FontData]FontData)
+	cache.forgetMissingPathFonts()
 	cache.Unlock()
 }
 
-// Load a font from cache if exists otherwise it will load the font from file
+// Load a font from cache if exists otherwise it will load the font from file.
+// When there is no face with the exact weight, stretch and style of fontData,
+// the closest one is returned as selected by MatchFont.
 func (cache *SyncFolderFontCache) Load(fontData FontData) (font *truetype.Font, err error) {
 	cache.RLock()
-	font = cache.fonts[cache.namer(fontData)]
+	font = cache.cached(fontData)
 	cache.RUnlock()
 
 	if font != nil {
 		return font, nil
 	}
 
-	var data []byte
-	var file = cache.namer(fontData)
-
-	if data, err = os.ReadFile(filepath.Join(cache.folder, file)); err != nil {
-		return
-	}
-
-	if font, err = truetype.Parse(data); err != nil {
-		return
-	}
 	cache.Lock()
-	cache.fonts[file] = font
-	cache.Unlock()
-	return
+	defer cache.Unlock()
+	return cache.load(fontData)
 }
 
 // Store a font to this cache
 func (cache *SyncFolderFontCache) Store(fontData FontData, font *truetype.Font) {
 	cache.Lock()
-	cache.fonts[cache.namer(fontData)] = font
+	cache.store(fontData, font)
 	cache.Unlock()
 }
 
 var (
 	defaultFonts = NewSyncFolderFontCache("../resource/font")
 
+	// fontFileData maps weak pointers to the fonts parsed by ParseFont to
+	// their data, the entries are deleted when the fonts are collected
+	fontFileData sync.Map
+
 	fontCache FontCache = defaultFonts
 )
@@ -0,0 +1,430 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2d
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BitmapFont is a font of pixel glyphs read from a BDF or PCF file. Its font
+// unit is the pixel: the path of a glyph has a square for each pixel set,
+// so that the glyphs are drawn pixel exact at their size when their origin
+// is on a whole pixel.
+type BitmapFont struct {
+	// Ascent and Descent are the extent of the font above and below the
+	// baseline in pixels
+	Ascent, Descent int
+	// PixelSize is the size of the em in pixels
+	PixelSize int
+	// DefaultChar is the rune drawn for the runes without glyph, -1 if none
+	DefaultChar rune
+	glyphs      map[rune]bitmapGlyph
+}
+
+type bitmapGlyph struct {
+	path    *Path
+	advance int
+}
+
+// Glyph returns the path of the glyph of r, or of the default char if the
+// font has no glyph for r
+func (font *BitmapFont) Glyph(r rune) (path *Path, advance float64, ok bool) {
+	glyph, ok := font.glyphs[r]
+	if !ok {
+		glyph, ok = font.glyphs[font.DefaultChar]
+	}
+	if !ok {
+		return nil, 0, false
+	}
+	return glyph.path, float64(glyph.advance), true
+}
+
+// Metrics returns the ascent, the descent and the size of the em in pixels
+func (font *BitmapFont) Metrics() (ascent, descent, em float64) {
+	return float64(font.Ascent), float64(font.Descent), float64(font.PixelSize)
+}
+
+// Stroked returns false, bitmap glyphs are filled
+func (font *BitmapFont) Stroked() bool {
+	return false
+}
+
+// bitmapPath returns a path with a rectangle for each run of set pixels of
+// a bitmap of width x height pixels, its top left corner at left, top
+func bitmapPath(width, height, left, top int, set func(x, y int) bool) *Path {
+	path := &Path{}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !set(x, y) {
+				continue
+			}
+			end := x + 1
+			for end < width && set(end, y) {
+				end++
+			}
+			x0, y0 := float64(left+x), float64(top+y)
+			x1, y1 := float64(left+end), float64(top+y+1)
+			path.MoveTo(x0, y0)
+			path.LineTo(x1, y0)
+			path.LineTo(x1, y1)
+			path.LineTo(x0, y1)
+			path.Close()
+			x = end
+		}
+	}
+	return path
+}
+
+// newBitmapFont completes the metrics of a parsed font
+func newBitmapFont(font *BitmapFont) (*BitmapFont, error) {
+	if len(font.glyphs) == 0 {
+		return nil, errors.New("draw2d: bitmap font without glyph")
+	}
+	if font.PixelSize <= 0 {
+		font.PixelSize = font.Ascent + font.Descent
+	}
+	if font.PixelSize <= 0 {
+		return nil, errors.New("draw2d: bitmap font without size")
+	}
+	return font, nil
+}
+
+// ParseBDF parses a font in the Glyph Bitmap Distribution Format of X11.
+// Glyph encodings are read as Unicode code points.
+func ParseBDF(data []byte) (*BitmapFont, error) {
+	font := &BitmapFont{DefaultChar: -1, glyphs: make(map[rune]bitmapGlyph)}
+	var (
+		started, inBitmap bool
+		encoding          int
+		advance           int
+		bbx, fontBBX      [4]int
+		rows              [][]byte
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if !started {
+			if fields[0] != "STARTFONT" {
+				return nil, errors.New("draw2d: not a BDF font")
+			}
+			started = true
+			continue
+		}
+		if inBitmap && fields[0] != "ENDCHAR" {
+			row, err := hex.DecodeString(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("draw2d: BDF line %d: %v", line, err)
+			}
+			rows = append(rows, row)
+			continue
+		}
+		values := make([]int, len(fields)-1)
+		for i, field := range fields[1:] {
+			values[i], _ = strconv.Atoi(field)
+		}
+		want := map[string]int{"FONTBOUNDINGBOX": 4, "BBX": 4, "DWIDTH": 1, "ENCODING": 1,
+			"PIXEL_SIZE": 1, "FONT_ASCENT": 1, "FONT_DESCENT": 1, "DEFAULT_CHAR": 1}[fields[0]]
+		if len(values) < want {
+			return nil, fmt.Errorf("draw2d: BDF line %d: %s expects %d values", line, fields[0], want)
+		}
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			copy(fontBBX[:], values)
+		case "PIXEL_SIZE":
+			font.PixelSize = values[0]
+		case "FONT_ASCENT":
+			font.Ascent = values[0]
+		case "FONT_DESCENT":
+			font.Descent = values[0]
+		case "DEFAULT_CHAR":
+			font.DefaultChar = rune(values[0])
+		case "STARTCHAR":
+			encoding, advance, bbx, rows = -1, fontBBX[0], fontBBX, nil
+		case "ENCODING":
+			encoding = values[0]
+		case "DWIDTH":
+			advance = values[0]
+		case "BBX":
+			copy(bbx[:], values)
+		case "BITMAP":
+			inBitmap = true
+		case "ENDCHAR":
+			inBitmap = false
+			if encoding < 0 {
+				// unencoded glyph
+				continue
+			}
+			width, height := bbx[0], bbx[1]
+			set := func(x, y int) bool {
+				return y < len(rows) && x/8 < len(rows[y]) && rows[y][x/8]&(0x80>>(x%8)) != 0
+			}
+			font.glyphs[rune(encoding)] = bitmapGlyph{
+				path:    bitmapPath(width, height, bbx[2], -(bbx[3] + height), set),
+				advance: advance,
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if font.Ascent == 0 && font.Descent == 0 {
+		font.Ascent, font.Descent = fontBBX[1]+fontBBX[3], -fontBBX[3]
+	}
+	return newBitmapFont(font)
+}
+
+// PCF table types
+const (
+	pcfProperties      = 1 << 0
+	pcfAccelerators    = 1 << 1
+	pcfMetrics         = 1 << 2
+	pcfBitmaps         = 1 << 3
+	pcfBDFEncodings    = 1 << 5
+	pcfBDFAccelerators = 1 << 8
+)
+
+// PCF table formats
+const (
+	pcfCompressedMetrics = 0x100
+	pcfByteMSBFirst      = 1 << 2
+	pcfBitMSBFirst       = 1 << 3
+)
+
+// pcfTable reads a table of a PCF file, in the byte order of its format
+type pcfTable struct {
+	data   []byte
+	format uint32
+	err    error
+}
+
+func (t *pcfTable) order() binary.ByteOrder {
+	if t.format&pcfByteMSBFirst != 0 {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+func (t *pcfTable) check(offset, size int) bool {
+	if t.err == nil && (offset < 0 || offset+size > len(t.data)) {
+		t.err = errors.New("draw2d: truncated PCF table")
+	}
+	return t.err == nil
+}
+
+func (t *pcfTable) u8(offset int) int {
+	if !t.check(offset, 1) {
+		return 0
+	}
+	return int(t.data[offset])
+}
+
+func (t *pcfTable) u16(offset int) int {
+	if !t.check(offset, 2) {
+		return 0
+	}
+	return int(t.order().Uint16(t.data[offset:]))
+}
+
+func (t *pcfTable) i16(offset int) int {
+	return int(int16(t.u16(offset)))
+}
+
+func (t *pcfTable) i32(offset int) int {
+	if !t.check(offset, 4) {
+		return 0
+	}
+	return int(int32(t.order().Uint32(t.data[offset:])))
+}
+
+// pcfMetric is the metric of a glyph of a PCF font
+type pcfMetric struct {
+	left, right, advance, ascent, descent int
+}
+
+// ParsePCF parses a font in the Portable Compiled Format of X11. Glyph
+// encodings are read as Unicode code points.
+func ParsePCF(data []byte) (*BitmapFont, error) {
+	if len(data) < 8 || string(data[:4]) != "\x01fcp" {
+		return nil, errors.New("draw2d: not a PCF font")
+	}
+	tables := make(map[int]*pcfTable)
+	count := int(binary.LittleEndian.Uint32(data[4:]))
+	for i := 0; i < count; i++ {
+		entry := 8 + 16*i
+		if entry+16 > len(data) {
+			return nil, errors.New("draw2d: truncated PCF table of contents")
+		}
+		kind := int(binary.LittleEndian.Uint32(data[entry:]))
+		size := int(binary.LittleEndian.Uint32(data[entry+8:]))
+		offset := int(binary.LittleEndian.Uint32(data[entry+12:]))
+		if offset < 0 || size < 4 || offset+size > len(data) {
+			return nil, errors.New("draw2d: truncated PCF table")
+		}
+		table := data[offset : offset+size]
+		// the format is always least significant byte first
+		tables[kind] = &pcfTable{data: table, format: binary.LittleEndian.Uint32(table)}
+	}
+	for _, kind := range []int{pcfMetrics, pcfBitmaps, pcfBDFEncodings} {
+		if tables[kind] == nil {
+			return nil, fmt.Errorf("draw2d: PCF font without table %d", kind)
+		}
+	}
+
+	font := &BitmapFont{DefaultChar: -1, glyphs: make(map[rune]bitmapGlyph)}
+	if t := tables[pcfProperties]; t != nil {
+		readPCFProperties(t, font)
+	}
+	accelerators := tables[pcfBDFAccelerators]
+	if accelerators == nil {
+		accelerators = tables[pcfAccelerators]
+	}
+	if accelerators != nil && font.Ascent == 0 && font.Descent == 0 {
+		font.Ascent, font.Descent = accelerators.i32(12), accelerators.i32(16)
+	}
+
+	var metrics []pcfMetric
+	t := tables[pcfMetrics]
+	if t.format&pcfCompressedMetrics != 0 {
+		// the count is checked against the size of the table before
+		// allocating the metrics
+		count := t.i16(4)
+		if !t.check(6, 5*count) {
+			count = 0
+		}
+		for i := 0; i < count; i++ {
+			offset := 6 + 5*i
+			metrics = append(metrics, pcfMetric{
+				t.u8(offset) - 0x80, t.u8(offset+1) - 0x80, t.u8(offset+2) - 0x80, t.u8(offset+3) - 0x80, t.u8(offset+4) - 0x80,
+			})
+		}
+	} else {
+		count := t.i32(4)
+		if !t.check(8, 12*count) {
+			count = 0
+		}
+		for i := 0; i < count; i++ {
+			offset := 8 + 12*i
+			metrics = append(metrics, pcfMetric{
+				t.i16(offset), t.i16(offset + 2), t.i16(offset + 4), t.i16(offset + 6), t.i16(offset + 8),
+			})
+		}
+	}
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	bitmaps := tables[pcfBitmaps]
+	if bitmaps.i32(4) != len(metrics) {
+		return nil, errors.New("draw2d: PCF bitmap and metric counts differ")
+	}
+	pad := 1 << (bitmaps.format & 3)
+	scanUnit := 1 << ((bitmaps.format >> 4) & 3)
+	bitMSBFirst := bitmaps.format&pcfBitMSBFirst != 0
+	swap := scanUnit > 1 && (bitmaps.format&pcfByteMSBFirst != 0) != bitMSBFirst
+	// the glyph data follows the offsets and the 4 sizes of the bitmaps
+	dataStart := 8 + 4*len(metrics) + 16
+
+	encodings := tables[pcfBDFEncodings]
+	min2, max2 := encodings.i16(4), encodings.i16(6)
+	min1, max1 := encodings.i16(8), encodings.i16(10)
+	defaultChar := encodings.i16(12)
+	if !encodings.check(14, 2*max(0, max1-min1+1)*max(0, max2-min2+1)) {
+		return nil, encodings.err
+	}
+	for byte1 := min1; byte1 <= max1; byte1++ {
+		for byte2 := min2; byte2 <= max2; byte2++ {
+			index := encodings.u16(14 + 2*((byte1-min1)*(max2-min2+1)+byte2-min2))
+			if index == 0xffff || index >= len(metrics) {
+				continue
+			}
+			m := metrics[index]
+			width, height := m.right-m.left, m.ascent+m.descent
+			stride := (width + 7) / 8
+			stride = (stride + pad - 1) / pad * pad
+			start := dataStart + bitmaps.i32(8+4*index)
+			if width < 0 || height < 0 || !bitmaps.check(start, stride*height) {
+				return nil, errors.New("draw2d: invalid PCF glyph")
+			}
+			glyphData := bitmaps.data[start : start+stride*height]
+			set := func(x, y int) bool {
+				i := y*stride + x/8
+				if swap {
+					// bytes are swapped within scan units
+					i = i/scanUnit*scanUnit + scanUnit - 1 - i%scanUnit
+				}
+				if bitMSBFirst {
+					return glyphData[i]&(0x80>>(x%8)) != 0
+				}
+				return glyphData[i]&(1<<(x%8)) != 0
+			}
+			r := rune(byte1<<8 | byte2)
+			font.glyphs[r] = bitmapGlyph{
+				path:    bitmapPath(width, height, m.left, -m.ascent, set),
+				advance: m.advance,
+			}
+			if byte1<<8|byte2 == defaultChar && font.DefaultChar < 0 {
+				font.DefaultChar = r
+			}
+		}
+	}
+	if encodings.err != nil {
+		return nil, encodings.err
+	}
+	return newBitmapFont(font)
+}
+
+// readPCFProperties reads the size and the metrics of the font from its
+// properties
+func readPCFProperties(t *pcfTable, font *BitmapFont) {
+	count := t.i32(4)
+	if count < 0 || count > len(t.data) {
+		return
+	}
+	padding := 0
+	if count&3 != 0 {
+		padding = 4 - count&3
+	}
+	stringsStart := 8 + 9*count + padding + 4
+	name := func(offset int) string {
+		start := stringsStart + offset
+		if start < 0 || start >= len(t.data) {
+			return ""
+		}
+		end := bytes.IndexByte(t.data[start:], 0)
+		if end < 0 {
+			return ""
+		}
+		return string(t.data[start : start+end])
+	}
+	for i := 0; i < count; i++ {
+		offset := 8 + 9*i
+		if t.u8(offset+4) != 0 {
+			// string property
+			continue
+		}
+		value := t.i32(offset + 5)
+		switch name(t.i32(offset)) {
+		case "PIXEL_SIZE":
+			font.PixelSize = value
+		case "FONT_ASCENT":
+			font.Ascent = value
+		case "FONT_DESCENT":
+			font.Descent = value
+		case "DEFAULT_CHAR":
+			font.DefaultChar = rune(value)
+		}
+	}
+}
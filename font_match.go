@@ -0,0 +1,121 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2d
+
+// fontSlant classifies a FontStyle as normal, italic or oblique.
+type fontSlant int
+
+const (
+	slantNormal fontSlant = iota
+	slantItalic
+	slantOblique
+)
+
+func slantOf(style FontStyle) fontSlant {
+	switch {
+	case style&FontStyleItalic != 0:
+		return slantItalic
+	case style&FontStyleOblique != 0:
+		return slantOblique
+	}
+	return slantNormal
+}
+
+// slantPreferences lists, for each requested slant, the order in which
+// available slants are tried.
+var slantPreferences = map[fontSlant][3]fontSlant{
+	slantNormal:  {slantNormal, slantOblique, slantItalic},
+	slantItalic:  {slantItalic, slantOblique, slantNormal},
+	slantOblique: {slantOblique, slantItalic, slantNormal},
+}
+
+// fontScore ranks an available face against a request, lower is better.
+// Fields are compared in order, so stretch wins over style which wins over
+// weight, like in the CSS font matching algorithm.
+type fontScore [5]int
+
+func (a fontScore) less(b fontScore) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// stretchRank ranks an available stretch: narrower faces are preferred for
+// condensed and normal requests, wider ones for expanded requests.
+func stretchRank(want, have FontStretch) (tier, distance int) {
+	if want <= FontStretchNormal {
+		if have <= want {
+			return 0, int(want - have)
+		}
+		return 1, int(have - want)
+	}
+	if have >= want {
+		return 0, int(have - want)
+	}
+	return 1, int(want - have)
+}
+
+// weightRank ranks an available weight following the CSS rules: requests
+// between 400 and 500 first look up to 500, then lighter, then heavier;
+// lighter requests look lighter first, heavier requests heavier first.
+func weightRank(want, have FontWeight) (tier, distance int) {
+	switch {
+	case want >= FontWeightNormal && want <= FontWeightMedium:
+		switch {
+		case have >= want && have <= FontWeightMedium:
+			return 0, int(have - want)
+		case have < want:
+			return 1, int(want - have)
+		}
+		return 2, int(have - want)
+	case want < FontWeightNormal:
+		if have <= want {
+			return 0, int(want - have)
+		}
+		return 1, int(have - want)
+	}
+	if have >= want {
+		return 0, int(have - want)
+	}
+	return 1, int(want - have)
+}
+
+func scoreFont(request, face FontData) fontScore {
+	var score fontScore
+	score[0], score[1] = stretchRank(request.ResolvedStretch(), face.ResolvedStretch())
+	preferences := slantPreferences[slantOf(request.Style)]
+	slant := slantOf(face.Style)
+	for i, s := range preferences {
+		if s == slant {
+			score[2] = i
+		}
+	}
+	score[3], score[4] = weightRank(request.ResolvedWeight(), face.ResolvedWeight())
+	return score
+}
+
+// MatchFont returns the face among available that best matches request.
+// Only faces with the same Name and Family are considered; among them the
+// closest stretch is selected first, then the closest style (italic and
+// oblique substitute for each other before falling back to normal), then the
+// closest weight, following the CSS font matching rules.
+// The second result is false if no face has the requested name and family.
+func MatchFont(request FontData, available []FontData) (FontData, bool) {
+	var best FontData
+	var bestScore fontScore
+	found := false
+	for _, face := range available {
+		if face.Name != request.Name || face.Family != request.Family {
+			continue
+		}
+		score := scoreFont(request, face)
+		if !found || score.less(bestScore) {
+			best, bestScore, found = face, score, true
+		}
+	}
+	return best, found
+}
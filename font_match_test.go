@@ -0,0 +1,148 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2d
+
+import (
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+)
+
+func TestMatchFont_Weight(t *testing.T) {
+	faces := []FontData{
+		{Name: "f", Weight: FontWeightLight},
+		{Name: "f", Weight: FontWeightNormal},
+		{Name: "f", Weight: FontWeightBold},
+		{Name: "f", Weight: FontWeightBlack},
+	}
+	tests := []struct {
+		want     FontWeight
+		expected FontWeight
+	}{
+		{FontWeightThin, FontWeightLight},
+		{FontWeightLight, FontWeightLight},
+		{FontWeightNormal, FontWeightNormal},
+		{FontWeightMedium, FontWeightNormal},
+		{FontWeightSemiBold, FontWeightBold},
+		{FontWeightExtraBold, FontWeightBlack},
+	}
+	for _, tt := range tests {
+		got, ok := MatchFont(FontData{Name: "f", Weight: tt.want}, faces)
+		if !ok || got.Weight != tt.expected {
+			t.Errorf("MatchFont(weight %d) = %d, want %d", tt.want, got.Weight, tt.expected)
+		}
+	}
+}
+
+func TestMatchFont_MediumPrefersUpTo500(t *testing.T) {
+	faces := []FontData{
+		{Name: "f", Weight: FontWeightLight},
+		{Name: "f", Weight: FontWeightMedium},
+	}
+	got, _ := MatchFont(FontData{Name: "f", Weight: FontWeightNormal}, faces)
+	if got.Weight != FontWeightMedium {
+		t.Errorf("MatchFont(400) = %d, want 500", got.Weight)
+	}
+}
+
+func TestMatchFont_StyleBeforeWeight(t *testing.T) {
+	faces := []FontData{
+		{Name: "f", Style: FontStyleNormal},
+		{Name: "f", Style: FontStyleBold},
+		{Name: "f", Style: FontStyleOblique, Weight: FontWeightLight},
+	}
+	got, _ := MatchFont(FontData{Name: "f", Style: FontStyleBold | FontStyleItalic}, faces)
+	if got.Style != FontStyleOblique {
+		t.Errorf("italic request should fall back to oblique face, got %+v", got)
+	}
+}
+
+func TestMatchFont_Stretch(t *testing.T) {
+	faces := []FontData{
+		{Name: "f", Stretch: FontStretchUltraCondensed},
+		{Name: "f", Stretch: FontStretchSemiExpanded},
+		{Name: "f", Stretch: FontStretchExtraExpanded},
+	}
+	tests := []struct {
+		want     FontStretch
+		expected FontStretch
+	}{
+		{FontStretchCondensed, FontStretchUltraCondensed},
+		{0, FontStretchUltraCondensed},
+		{FontStretchExpanded, FontStretchExtraExpanded},
+		{FontStretchUltraExpanded, FontStretchExtraExpanded},
+	}
+	for _, tt := range tests {
+		got, _ := MatchFont(FontData{Name: "f", Stretch: tt.want}, faces)
+		if got.Stretch != tt.expected {
+			t.Errorf("MatchFont(stretch %d) = %d, want %d", tt.want, got.Stretch, tt.expected)
+		}
+	}
+}
+
+func TestMatchFont_NameAndFamily(t *testing.T) {
+	faces := []FontData{{Name: "other"}, {Name: "f", Family: FontFamilyMono}}
+	if _, ok := MatchFont(FontData{Name: "f"}, faces); ok {
+		t.Error("MatchFont should not match a face with another name or family")
+	}
+}
+
+func TestFontFileName_Weight(t *testing.T) {
+	if got := FontFileName(FontData{Name: "luxi", Weight: FontWeightSemiBold}); got != "luxisb.ttf" {
+		t.Errorf("FontFileName(semibold) = %v, want luxisb.ttf", got)
+	}
+	if got := FontFileName(FontData{Name: "luxi", Style: FontStyleBold, Weight: FontWeightLight}); got != "luxisr.ttf" {
+		t.Errorf("FontFileName(light) = %v, want luxisr.ttf", got)
+	}
+	if got := FontFileName(FontData{Name: "luxi", Style: FontStyleOblique}); got != "luxisri.ttf" {
+		t.Errorf("FontFileName(oblique) = %v, want luxisri.ttf", got)
+	}
+}
+
+func TestFolderFontCache_LoadClosestWeight(t *testing.T) {
+	cache := NewFolderFontCache("resource/font")
+	bold, err := cache.Load(FontData{Name: "luxi", Style: FontStyleBold})
+	if err != nil {
+		t.Fatal(err)
+	}
+	regular, err := cache.Load(FontData{Name: "luxi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font, _ := cache.Load(FontData{Name: "luxi", Weight: FontWeightSemiBold}); font != bold {
+		t.Error("semibold should resolve to the bold face")
+	}
+	if font, _ := cache.Load(FontData{Name: "luxi", Weight: FontWeightLight}); font != regular {
+		t.Error("light should resolve to the regular face")
+	}
+}
+
+func TestFolderFontCache_CachedExactMatch(t *testing.T) {
+	cache := NewFolderFontCache("resource/font")
+	fontData := FontData{Name: "luxi", Family: FontFamilyMono}
+	font, err := cache.Load(fontData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cache.cached(fontData); got != font {
+		t.Error("the font loaded for an exact request is not cached")
+	}
+}
+
+func TestSyncFolderFontCache_StoreBetterMatch(t *testing.T) {
+	cache := NewSyncFolderFontCache("resource/font")
+	semibold := FontData{Name: "luxi", Weight: FontWeightSemiBold}
+	bold, err := cache.Load(semibold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face := &truetype.Font{}
+	cache.Store(semibold, face)
+	if font, _ := cache.Load(semibold); font != face {
+		t.Error("Load should return the stored semibold face")
+	}
+	if font, _ := cache.Load(FontData{Name: "luxi", Weight: FontWeightBold}); font != bold {
+		t.Error("Load of bold should still return the bold file")
+	}
+}
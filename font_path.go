@@ -0,0 +1,150 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2d
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathFont is a font whose glyphs are paths rather than TrueType outlines:
+// a BitmapFont draws a square per pixel and a StrokeFont draws open paths
+// meant to be stroked. Glyph paths are in font units, y going downwards,
+// with their origin on the baseline.
+type PathFont interface {
+	// Glyph returns the path of the glyph of r and its advance width. ok is
+	// false if the font has no glyph for r. The path must not be modified.
+	Glyph(r rune) (path *Path, advance float64, ok bool)
+	// Metrics returns the ascent, the descent and the size of the em of
+	// the font in font units
+	Metrics() (ascent, descent, em float64)
+	// Stroked returns true if the glyphs are open paths to be stroked
+	Stroked() bool
+}
+
+// PathFontCache is implemented by the font caches that also select bitmap
+// and stroke fonts. The graphic contexts use the PathFont selected for their
+// FontData instead of a TrueType font.
+type PathFontCache interface {
+	FontCache
+	// LoadPathFont returns the bitmap or stroke font of fontData, or an error
+	// if there is none
+	LoadPathFont(fontData FontData) (PathFont, error)
+	// StorePathFont sets the font returned by LoadPathFont for fontData
+	StorePathFont(fontData FontData, font PathFont)
+}
+
+// pathFontExtensions are the extensions of the bitmap and stroke font files,
+// with their parser
+var pathFontExtensions = []struct {
+	extension string
+	parse     func(data []byte) (PathFont, error)
+}{
+	{".bdf", func(data []byte) (PathFont, error) { return ParseBDF(data) }},
+	{".pcf", func(data []byte) (PathFont, error) { return ParsePCF(data) }},
+	{".jhf", func(data []byte) (PathFont, error) { return ParseHershey(data) }},
+}
+
+// RegisterPathFont registers a bitmap or stroke font in the global font
+// cache, if it is a PathFontCache
+func RegisterPathFont(fontData FontData, font PathFont) {
+	if cache, ok := fontCache.(PathFontCache); ok {
+		cache.StorePathFont(fontData, font)
+	}
+}
+
+// GetPathFont returns the bitmap or stroke font selected by fontData in the
+// global font cache, nil if there is none
+func GetPathFont(fontData FontData) PathFont {
+	if cache, ok := fontCache.(PathFontCache); ok {
+		if font, err := cache.LoadPathFont(fontData); err == nil {
+			return font
+		}
+	}
+	return nil
+}
+
+// cachedPathFont returns the path font registered or loaded for fontData,
+// ok being false if its files have not been looked for yet
+func (ff *folderFonts) cachedPathFont(fontData FontData) (font PathFont, ok bool, err error) {
+	if font, ok = ff.pathFonts[fontData.normalize()]; ok && font == nil {
+		err = fmt.Errorf("draw2d: no bitmap or stroke font for %s", fontData.Name)
+	}
+	return font, ok, err
+}
+
+// loadPathFont returns the path font registered for fontData or loaded from
+// the file named like its TrueType file with a bitmap or stroke font
+// extension. Missing and invalid fonts are remembered, so that text drawn
+// with TrueType fonts does not look for files each time.
+func (ff *folderFonts) loadPathFont(fontData FontData) (PathFont, error) {
+	if font, ok, err := ff.cachedPathFont(fontData); ok {
+		return font, err
+	}
+	key := fontData.normalize()
+	base := strings.TrimSuffix(ff.namer(key), ".ttf")
+	for _, format := range pathFontExtensions {
+		data, err := os.ReadFile(filepath.Join(ff.folder, base+format.extension))
+		if err != nil {
+			continue
+		}
+		font, err := format.parse(data)
+		if err != nil {
+			ff.pathFonts[key] = nil
+			return nil, err
+		}
+		ff.pathFonts[key] = font
+		return font, nil
+	}
+	ff.pathFonts[key] = nil
+	return nil, fmt.Errorf("draw2d: no bitmap or stroke font for %s", fontData.Name)
+}
+
+// forgetMissingPathFonts forgets the faces without path font, to look for
+// their files again after the folder or the namer changed
+func (ff *folderFonts) forgetMissingPathFonts() {
+	for key, font := range ff.pathFonts {
+		if font == nil {
+			delete(ff.pathFonts, key)
+		}
+	}
+}
+
+// LoadPathFont returns the bitmap or stroke font registered for fontData,
+// or loads it from the folder. The file is named by the FontFileNamer of the
+// cache with the .ttf extension replaced by .bdf, .pcf or .jhf.
+func (cache *FolderFontCache) LoadPathFont(fontData FontData) (PathFont, error) {
+	return cache.loadPathFont(fontData)
+}
+
+// StorePathFont registers a bitmap or stroke font for fontData
+func (cache *FolderFontCache) StorePathFont(fontData FontData, font PathFont) {
+	cache.pathFonts[fontData.normalize()] = font
+}
+
+// LoadPathFont returns the bitmap or stroke font registered for fontData,
+// or loads it from the folder. The file is named by the FontFileNamer of the
+// cache with the .ttf extension replaced by .bdf, .pcf or .jhf.
+func (cache *SyncFolderFontCache) LoadPathFont(fontData FontData) (PathFont, error) {
+	cache.RLock()
+	font, ok, err := cache.cachedPathFont(fontData)
+	cache.RUnlock()
+
+	if ok {
+		return font, err
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+	return cache.loadPathFont(fontData)
+}
+
+// StorePathFont registers a bitmap or stroke font for fontData
+func (cache *SyncFolderFontCache) StorePathFont(fontData FontData, font PathFont) {
+	cache.Lock()
+	cache.pathFonts[fontData.normalize()] = font
+	cache.Unlock()
+}
@@ -0,0 +1,257 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2d
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testBDF is a font of two glyphs: an 'A' of 3x2 pixels on the baseline and
+// a 'g' of 1x2 pixels below it
+const testBDF = `STARTFONT 2.1
+FONT -test-fixed-medium-r-normal--8-80-75-75-c-50-iso10646-1
+SIZE 8 75 75
+FONTBOUNDINGBOX 4 6 0 -2
+STARTPROPERTIES 3
+PIXEL_SIZE 8
+FONT_ASCENT 6
+FONT_DESCENT 2
+ENDPROPERTIES
+CHARS 2
+STARTCHAR A
+ENCODING 65
+DWIDTH 5 0
+BBX 3 2 1 0
+BITMAP
+E0
+A0
+ENDCHAR
+STARTCHAR g
+ENCODING 103
+DWIDTH 4 0
+BBX 1 2 0 -2
+BITMAP
+80
+80
+ENDCHAR
+ENDFONT
+`
+
+// pathBounds returns the bounds of the points of path
+func pathBounds(path *Path) (left, top, right, bottom float64) {
+	left, top, right, bottom = path.Points[0], path.Points[1], path.Points[0], path.Points[1]
+	for i := 0; i < len(path.Points); i += 2 {
+		x, y := path.Points[i], path.Points[i+1]
+		left, right = min(left, x), max(right, x)
+		top, bottom = min(top, y), max(bottom, y)
+	}
+	return
+}
+
+func TestParseBDF(t *testing.T) {
+	font, err := ParseBDF([]byte(testBDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.Ascent != 6 || font.Descent != 2 || font.PixelSize != 8 || font.Stroked() {
+		t.Errorf("unexpected font %+v", font)
+	}
+	path, advance, ok := font.Glyph('A')
+	if !ok || advance != 5 {
+		t.Fatalf("Glyph('A') = %v, %v", advance, ok)
+	}
+	// a rectangle for the first row and two for the second one
+	if n := strings.Count(path.String(), "Close"); n != 3 {
+		t.Errorf("expected 3 pixel runs, got %d:\n%s", n, path)
+	}
+	if l, top, r, b := pathBounds(path); l != 1 || top != -2 || r != 4 || b != 0 {
+		t.Errorf("unexpected 'A' bounds %v %v %v %v", l, top, r, b)
+	}
+	path, _, _ = font.Glyph('g')
+	if l, top, r, b := pathBounds(path); l != 0 || top != 0 || r != 1 || b != 2 {
+		t.Errorf("unexpected 'g' bounds %v %v %v %v", l, top, r, b)
+	}
+	if _, _, ok := font.Glyph('z'); ok {
+		t.Error("the font has no default char")
+	}
+	if _, err := ParseBDF([]byte("not a font")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+// encodePCF returns a PCF file of the tables, indexed by type. The first 4
+// bytes of each table are its format.
+func encodePCF(tables map[int][]byte) []byte {
+	var kinds []int
+	for kind := range tables {
+		kinds = append(kinds, kind)
+	}
+	// sorted, as in PCF files
+	sort.Ints(kinds)
+	buf := &bytes.Buffer{}
+	buf.WriteString("\x01fcp")
+	binary.Write(buf, binary.LittleEndian, uint32(len(kinds)))
+	offset := 8 + 16*len(kinds)
+	for _, kind := range kinds {
+		table := tables[kind]
+		binary.Write(buf, binary.LittleEndian, []uint32{uint32(kind), binary.LittleEndian.Uint32(table), uint32(len(table)), uint32(offset)})
+		offset += len(table)
+	}
+	for _, kind := range kinds {
+		buf.Write(tables[kind])
+	}
+	return buf.Bytes()
+}
+
+// pcfTableData encodes a table of format, the values following the format
+// in the byte order of the format
+func pcfTableData(format uint32, values ...any) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, format)
+	var order binary.ByteOrder = binary.LittleEndian
+	if format&pcfByteMSBFirst != 0 {
+		order = binary.BigEndian
+	}
+	for _, v := range values {
+		binary.Write(buf, order, v)
+	}
+	return buf.Bytes()
+}
+
+func TestParsePCF(t *testing.T) {
+	// the 'A' of testBDF, with its bitmap rows padded to 4 bytes, big endian
+	// and most significant bit first
+	bitmapFormat := uint32(pcfByteMSBFirst | pcfBitMSBFirst | 2)
+	data := encodePCF(map[int][]byte{
+		pcfMetrics:         pcfTableData(0, int32(1), []int16{1, 4, 5, 2, 0, 0}),
+		pcfBitmaps:         pcfTableData(bitmapFormat, int32(1), int32(0), []int32{8, 8, 8, 8}, []byte{0xe0, 0, 0, 0, 0xa0, 0, 0, 0}),
+		pcfBDFEncodings:    pcfTableData(0, []int16{'A', 'A', 0, 0, 'A'}, uint16(0)),
+		pcfBDFAccelerators: pcfTableData(0, [8]byte{}, int32(6), int32(2)),
+	})
+	font, err := ParsePCF(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.Ascent != 6 || font.Descent != 2 || font.PixelSize != 8 || font.DefaultChar != 'A' {
+		t.Errorf("unexpected font %+v", font)
+	}
+	bdf, _ := ParseBDF([]byte(testBDF))
+	want, _, _ := bdf.Glyph('A')
+	path, advance, ok := font.Glyph('A')
+	if !ok || advance != 5 || !reflect.DeepEqual(path.Points, want.Points) {
+		t.Errorf("Glyph('A') = %v, %v, %v, want the BDF glyph %v", path, advance, ok, want)
+	}
+	if _, _, ok := font.Glyph('z'); !ok {
+		t.Error("the default char should be drawn for missing glyphs")
+	}
+	if _, err := ParsePCF(data[:40]); err == nil {
+		t.Error("expected an error for a truncated font")
+	}
+	// counts larger than the tables
+	for name, tables := range map[string]map[int][]byte{
+		"metrics": {
+			pcfMetrics:      pcfTableData(0, int32(1<<30), []int16{1, 4, 5, 2, 0, 0}),
+			pcfBitmaps:      pcfTableData(bitmapFormat, int32(1), int32(0), []int32{8, 8, 8, 8}, []byte{0xe0, 0, 0, 0, 0xa0, 0, 0, 0}),
+			pcfBDFEncodings: pcfTableData(0, []int16{'A', 'A', 0, 0, 'A'}, uint16(0)),
+		},
+		"encodings": {
+			pcfMetrics:      pcfTableData(0, int32(1), []int16{1, 4, 5, 2, 0, 0}),
+			pcfBitmaps:      pcfTableData(bitmapFormat, int32(1), int32(0), []int32{8, 8, 8, 8}, []byte{0xe0, 0, 0, 0, 0xa0, 0, 0, 0}),
+			pcfBDFEncodings: pcfTableData(0, []int16{0, 0x7fff, 0, 0x7fff, 'A'}, uint16(0)),
+		},
+	} {
+		if _, err := ParsePCF(encodePCF(tables)); err == nil {
+			t.Errorf("expected an error for the %s count", name)
+		}
+	}
+}
+
+// testHershey has a space and an exclamation mark, wrapped on two lines
+const testHershey = "12345  1JZ\n12345  6MWRFRT R\nRYRZ\n"
+
+func TestParseHershey(t *testing.T) {
+	font, err := ParseHershey([]byte(testHershey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !font.Stroked() {
+		t.Error("stroke fonts should be stroked")
+	}
+	if path, advance, ok := font.Glyph(' '); !ok || advance != 16 || !path.IsEmpty() {
+		t.Errorf("Glyph(' ') = %v, %v, %v", path, advance, ok)
+	}
+	path, advance, ok := font.Glyph('!')
+	if !ok || advance != 10 {
+		t.Fatalf("Glyph('!') = %v, %v", advance, ok)
+	}
+	want := []float64{5, -21, 5, -7, 5, -2, 5, -1}
+	if !reflect.DeepEqual(path.Points, want) || !reflect.DeepEqual(path.Components, []PathCmp{MoveToCmp, LineToCmp, MoveToCmp, LineToCmp}) {
+		t.Errorf("unexpected '!' path %v", path)
+	}
+	if ascent, descent, em := font.Metrics(); ascent != 21 || descent != 0 || em != 21 {
+		t.Errorf("unexpected metrics %v %v %v", ascent, descent, em)
+	}
+	if _, err := ParseHershey([]byte("12345  6MW")); err == nil {
+		t.Error("expected an error for a truncated glyph")
+	}
+}
+
+func TestSyncFolderFontCache_LoadPathFont(t *testing.T) {
+	folder := t.TempDir()
+	cache := NewSyncFolderFontCache(folder)
+	bitmap := FontData{Name: "fixed", Family: FontFamilyMono}
+	stroke := FontData{Name: "hershey", Family: FontFamilySerif}
+
+	if _, err := cache.LoadPathFont(bitmap); err == nil {
+		t.Error("expected an error without font file")
+	}
+	for fontData, data := range map[FontData]string{bitmap: testBDF, stroke: testHershey} {
+		name := strings.TrimSuffix(FontFileName(fontData), ".ttf")
+		ext := map[string]string{testBDF: ".bdf", testHershey: ".jhf"}[data]
+		if err := os.WriteFile(filepath.Join(folder, name+ext), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// missing fonts are remembered until the folder changes
+	if _, err := cache.LoadPathFont(bitmap); err == nil {
+		t.Error("expected the missing font to be remembered")
+	}
+	cache.setFolder(folder)
+	if font, err := cache.LoadPathFont(bitmap); err != nil {
+		t.Error(err)
+	} else if _, ok := font.(*BitmapFont); !ok {
+		t.Errorf("expected a bitmap font, got %T", font)
+	}
+	if font, err := cache.LoadPathFont(stroke); err != nil {
+		t.Error(err)
+	} else if _, ok := font.(*StrokeFont); !ok {
+		t.Errorf("expected a stroke font, got %T", font)
+	}
+
+	invalid := FontData{Name: "invalid"}
+	name := strings.TrimSuffix(FontFileName(invalid), ".ttf")
+	if err := os.WriteFile(filepath.Join(folder, name+".bdf"), []byte("STARTFONT"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.LoadPathFont(invalid); err == nil {
+		t.Error("expected an error for an invalid font file")
+	}
+	if _, ok := cache.pathFonts[invalid.normalize()]; !ok {
+		t.Error("expected the invalid font to be remembered")
+	}
+
+	registered := FontData{Name: "registered"}
+	font, _ := ParseHershey([]byte(testHershey))
+	cache.StorePathFont(registered, font)
+	if loaded, err := cache.LoadPathFont(registered); err != nil || loaded != font {
+		t.Errorf("LoadPathFont() = %v, %v, want the stored font", loaded, err)
+	}
+}
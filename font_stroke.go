@@ -0,0 +1,111 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package draw2d
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// StrokeFont is a single-stroke vector font, like the Hershey fonts used by
+// pen plotters and engravers. Its glyphs are open paths drawn with a single
+// line: they are stroked, never filled.
+type StrokeFont struct {
+	// Ascent and Descent are the extent of the font above and below the
+	// baseline in font units
+	Ascent, Descent float64
+	glyphs          map[rune]strokeGlyph
+}
+
+type strokeGlyph struct {
+	path    *Path
+	advance float64
+}
+
+// Glyph returns the open path of the glyph of r
+func (font *StrokeFont) Glyph(r rune) (path *Path, advance float64, ok bool) {
+	glyph, ok := font.glyphs[r]
+	if !ok {
+		return nil, 0, false
+	}
+	return glyph.path, glyph.advance, true
+}
+
+// Metrics returns the ascent, the descent and the size of the em in font
+// units, the em spanning the ascent and the descent
+func (font *StrokeFont) Metrics() (ascent, descent, em float64) {
+	return font.Ascent, font.Descent, font.Ascent + font.Descent
+}
+
+// Stroked returns true, the glyphs are open paths
+func (font *StrokeFont) Stroked() bool {
+	return true
+}
+
+// hersheyBaseline is the vertical coordinate of the baseline of the Hershey
+// roman and script fonts
+const hersheyBaseline = 9
+
+// ParseHershey parses a Hershey font in the .jhf format, where each glyph is
+// a line with its number, its number of vertices and the vertices encoded as
+// pairs of characters relative to 'R'. The first vertex holds the left and
+// right bounds of the glyph and " R" lifts the pen. As in the usual
+// distribution of the fonts, glyphs are mapped to the printable ASCII
+// characters in order, starting with the space.
+func ParseHershey(data []byte) (*StrokeFont, error) {
+	font := &StrokeFont{glyphs: make(map[rune]strokeGlyph)}
+	text := strings.ReplaceAll(string(data), "\r", "")
+	r := ' '
+	for len(strings.TrimSpace(text)) > 0 {
+		text = strings.TrimLeft(text, "\n")
+		if len(text) < 8 {
+			return nil, errors.New("draw2d: truncated Hershey glyph")
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(text[5:8]))
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("draw2d: invalid Hershey glyph %q", text[:8])
+		}
+		// long glyphs are wrapped on several lines
+		var vertices []byte
+		i := 8
+		for ; i < len(text) && len(vertices) < 2*count; i++ {
+			if text[i] != '\n' {
+				vertices = append(vertices, text[i])
+			}
+		}
+		if len(vertices) < 2*count {
+			return nil, errors.New("draw2d: truncated Hershey glyph")
+		}
+		text = text[i:]
+
+		left, right := float64(vertices[0])-'R', float64(vertices[1])-'R'
+		path := &Path{}
+		penUp := true
+		for v := 2; v < len(vertices); v += 2 {
+			if vertices[v] == ' ' && vertices[v+1] == 'R' {
+				penUp = true
+				continue
+			}
+			x := float64(vertices[v]) - 'R' - left
+			y := float64(vertices[v+1]) - 'R' - hersheyBaseline
+			if penUp {
+				path.MoveTo(x, y)
+			} else {
+				path.LineTo(x, y)
+			}
+			penUp = false
+			font.Ascent = math.Max(font.Ascent, -y)
+			font.Descent = math.Max(font.Descent, y)
+		}
+		font.glyphs[r] = strokeGlyph{path: path, advance: right - left}
+		r++
+	}
+	if len(font.glyphs) == 0 {
+		return nil, errors.New("draw2d: Hershey font without glyph")
+	}
+	return font, nil
+}
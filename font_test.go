@@ -4,7 +4,11 @@
 package draw2d
 
 import (
+	"os"
+	"runtime"
 	"testing"
+	"time"
+	"weak"
 )
 
 func TestSetFontFolder_GetFontFolder(t *testing.T) {
@@ -239,3 +243,28 @@ func TestSetFontNamer(t *testing.T) {
 	// Restore by setting a valid namer
 	SetFontNamer(FontFileName)
 }
+
+func TestParseFont_ReleasesData(t *testing.T) {
+	data, err := os.ReadFile("resource/font/luxisr.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := ParseFont(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(GetFontFileData(font)) != len(data) {
+		t.Fatal("GetFontFileData should return the parsed data")
+	}
+	key := weak.Make(font)
+	font = nil
+	// the data is deleted by a cleanup once the font is collected
+	for range 100 {
+		runtime.GC()
+		if _, ok := fontFileData.Load(key); !ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("the data of the collected font is kept")
+}
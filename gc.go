@@ -42,11 +42,50 @@ type GraphicContext interface {
 	SetLineJoin(join LineJoin)
 	// SetLineDash sets the current dash
 	SetLineDash(dash []float64, dashOffset float64)
+	// SetAntialias sets whether the edges of shapes and text are smoothed
+	SetAntialias(antialias bool)
+	// GetAntialias gets whether the edges of shapes and text are smoothed
+	GetAntialias() bool
+	// SetCrispEdges sets whether horizontal and vertical lines are snapped
+	// to the pixel grid
+	SetCrispEdges(crisp bool)
+	// GetCrispEdges gets whether horizontal and vertical lines are snapped
+	// to the pixel grid
+	GetCrispEdges() bool
+	// SetShadow sets the shadow cast by fills, strokes and text, offset by
+	// offsetX, offsetY and blurred by a Gaussian blur of standard deviation
+	// blur/2, as the shadows of the html canvas. They are in device space,
+	// not transformed by the current transformation. No shadow is cast if c
+	// is transparent, the default.
+	SetShadow(offsetX, offsetY, blur float64, c color.Color)
+	// PushGroup starts a group: the following drawings are composited with
+	// each other, and then as a whole with the drawings below the group by
+	// PopGroup. Groups can be nested. The opengl backend of draw2dgl does not
+	// support groups, it paints their drawings one by one and ignores the
+	// opacity and the blend mode of PopGroup.
+	PushGroup()
+	// PopGroup ends the last group started by PushGroup and composites it
+	// with opacity, from 0 to 1, and the blend mode blend
+	PopGroup(opacity float64, blend BlendMode)
+	// SetMask sets the mask of the following drawings, which are modulated
+	// by the alpha of mask and not drawn outside of it. m transforms mask to
+	// user space. The mask is removed if mask is nil, and restored by
+	// Restore.
+	SetMask(mask image.Image, m Matrix)
+	// SetLuminanceMask sets the mask of the following drawings as SetMask,
+	// modulating them by the luminance of mask times its alpha, as the
+	// masks of svg
+	SetLuminanceMask(mask image.Image, m Matrix)
+	// MaskWithPath sets the mask of the following drawings to the fill of
+	// the paths and of the current path with the current fill rule, which
+	// is cleared as by Fill. The mask is restored by Restore.
+	MaskWithPath(paths ...*Path)
 	// SetFontSize sets the current font size
 	SetFontSize(fontSize float64)
 	// GetFontSize gets the current font size
 	GetFontSize() float64
-	// SetFontData sets the current FontData
+	// SetFontData sets the current FontData, text is drawn with the closest
+	// available face when there is no exact match for its weight, stretch and style
 	SetFontData(fontData FontData)
 	// GetFontData gets the current FontData
 	GetFontData() FontData
@@ -54,6 +93,12 @@ type GraphicContext interface {
 	GetFontName() string
 	// DrawImage draws the raster image in the current canvas
 	DrawImage(image image.Image)
+	// DrawImageScaled draws the raster image at x, y, scaled and aligned as
+	// specified by scaling, see ImageScaling.Rect
+	DrawImageScaled(image image.Image, x, y float64, scaling ImageScaling)
+	// DrawImageRect draws the rectangle srcRect of the raster image scaled
+	// to the rectangle dstRect, x0, y0, x1, y1, of the user space
+	DrawImageRect(image image.Image, srcRect image.Rectangle, dstRect [4]float64)
 	// Save the context and push it to the context stack
 	Save()
 	// Restore remove the current context and restore the last one
@@ -68,6 +113,9 @@ type GraphicContext interface {
 	GetDPI() int
 	// GetStringBounds gets pixel bounds(dimensions) of given string
 	GetStringBounds(s string) (left, top, right, bottom float64)
+	// MeasureString returns the advance, ink bounds, font metrics and glyph
+	// positions of the text drawn with the current font
+	MeasureString(text string) TextMetrics
 	// CreateStringPath creates a path from the string s at x, y
 	CreateStringPath(text string, x, y float64) (cursor float64)
 	// FillString draws the text at point (0, 0)
@@ -78,6 +126,25 @@ type GraphicContext interface {
 	StrokeString(text string) (cursor float64)
 	// StrokeStringAt draws the contour of the text at point (x, y)
 	StrokeStringAt(text string, x, y float64) (cursor float64)
+	// SetWritingMode sets the direction in which FillStringAt, StrokeStringAt,
+	// CreateStringPath and the measuring functions lay out text
+	SetWritingMode(mode WritingMode)
+	// GetWritingMode gets the current writing mode
+	GetWritingMode() WritingMode
+	// SetTextDecoration sets the lines drawn with text by FillStringAt and StrokeStringAt
+	SetTextDecoration(decoration TextDecoration)
+	// GetTextDecoration gets the current text decoration
+	GetTextDecoration() TextDecoration
+	// SetTextPathAnchor sets how text drawn along a path is anchored at its offset
+	SetTextPathAnchor(anchor TextAnchor)
+	// GetTextPathAnchor gets the current anchor of text drawn along a path
+	GetTextPathAnchor() TextAnchor
+	// FillStringOnPath draws the text along path, starting at offset from the
+	// beginning of the path, each glyph rotated to follow the path tangent
+	FillStringOnPath(text string, path *Path, offset float64) (cursor float64)
+	// StrokeStringOnPath draws the contour of the text along path, starting at
+	// offset from the beginning of the path
+	StrokeStringOnPath(text string, path *Path, offset float64) (cursor float64)
 	// Stroke strokes the paths with the color specified by SetStrokeColor
 	Stroke(paths ...*Path)
 	// Fill fills the paths with the color specified by SetFillColor
@@ -11,4 +11,5 @@ require (
 	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/llgcode/ps v0.0.0-20210114104736-f4b0c5d1e02e
 	golang.org/x/image v0.36.0
+	golang.org/x/text v0.34.0
 )
@@ -0,0 +1,290 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+// Package floatraster converts paths of float64 coordinates to the spans of
+// the pixels they cover, by accumulating the signed area of their lines in
+// the cells of the pixels they cross, as golang.org/x/image/vector does.
+// Unlike the 26.6 fixed point rasterizer of freetype, coordinates do not
+// overflow on large images and are not rounded to a 64th of pixel.
+package floatraster
+
+import (
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/golang/freetype/raster"
+)
+
+// bandHeight is the number of rows rasterized by a worker at a time
+const bandHeight = 128
+
+// Rasterizer accumulates the lines of paths and paints the spans of the
+// pixels they cover. It implements the draw2dbase.Flattener interface. Sub
+// paths are closed implicitly.
+type Rasterizer struct {
+	// UseNonZeroWinding selects the non-zero winding fill rule instead of
+	// the even-odd one
+	UseNonZeroWinding bool
+	// Workers is the number of goroutines rasterizing bands of rows, the
+	// rows being rasterized serially if it is 0 or 1. The painter must then
+	// support the painting of different rows concurrently.
+	Workers int
+
+	width, height int
+	lines         []line
+	// start and current points of the current sub path
+	startX, startY, x, y float64
+}
+
+// line is a line from (x0, y0) to (x1, y1), with y0 < y1, and of direction
+// +1 if it goes down and -1 otherwise
+type line struct {
+	x0, y0, x1, y1 float64
+	direction      float64
+}
+
+// cell is the area and the cover accumulated by the lines crossing the
+// pixel of a row at x. The coverage of the pixel is the sum of the covers
+// of the pixels on its left plus its area, the one of the pixels on its
+// right up to the next cell is the sum of the covers including its own.
+type cell struct {
+	x           int
+	area, cover float64
+}
+
+// NewRasterizer returns a rasterizer of an image of width x height pixels
+func NewRasterizer(width, height int) *Rasterizer {
+	return &Rasterizer{width: width, height: height}
+}
+
+// MoveTo starts a new sub path at (x, y), closing the current one
+func (r *Rasterizer) MoveTo(x, y float64) {
+	r.Close()
+	r.startX, r.startY, r.x, r.y = x, y, x, y
+}
+
+// LineTo adds a line from the current point to (x, y)
+func (r *Rasterizer) LineTo(x, y float64) {
+	r.addLine(r.x, r.y, x, y)
+	r.x, r.y = x, y
+}
+
+// LineJoin does nothing, lines are joined by the coverage of their pixels
+func (r *Rasterizer) LineJoin() {
+}
+
+// Close adds a line from the current point to the start of the sub path
+func (r *Rasterizer) Close() {
+	r.LineTo(r.startX, r.startY)
+}
+
+// End closes the current sub path
+func (r *Rasterizer) End() {
+	r.Close()
+}
+
+func (r *Rasterizer) addLine(x0, y0, x1, y1 float64) {
+	if y0 == y1 || math.IsNaN(x0+y0+x1+y1) || math.IsInf(x0+y0+x1+y1, 0) {
+		// horizontal lines do not cover any pixel
+		return
+	}
+	if y0 < y1 {
+		r.lines = append(r.lines, line{x0, y0, x1, y1, 1})
+	} else {
+		r.lines = append(r.lines, line{x1, y1, x0, y0, -1})
+	}
+}
+
+// Clear removes the accumulated lines
+func (r *Rasterizer) Clear() {
+	r.lines = r.lines[:0]
+	r.startX, r.startY, r.x, r.y = 0, 0, 0, 0
+}
+
+// Rasterize paints with p the spans of the accumulated lines. The spans of
+// a row are sorted by X, the rows of a band of rows are sorted by Y.
+func (r *Rasterizer) Rasterize(p raster.Painter) {
+	r.Close()
+	// bin the lines in the bands of rows they cross
+	bands := make([][]int, (r.height+bandHeight-1)/bandHeight)
+	for i, l := range r.lines {
+		b0 := max(int(math.Floor(l.y0))/bandHeight, 0)
+		b1 := min(int(math.Min(math.Ceil(l.y1), float64(r.height)))/bandHeight, len(bands)-1)
+		for b := b0; b <= b1 && l.y1 > 0; b++ {
+			bands[b] = append(bands[b], i)
+		}
+	}
+	workers := min(max(r.Workers, 1), len(bands))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var rows [bandHeight][]cell
+			var spans []raster.Span
+			for b := range next {
+				spans = r.rasterizeBand(p, b*bandHeight, bands[b], &rows, spans)
+			}
+		}()
+	}
+	for b, lines := range bands {
+		if len(lines) > 0 {
+			next <- b
+		}
+	}
+	close(next)
+	wg.Wait()
+	p.Paint(nil, true)
+}
+
+// rasterizeBand paints the spans of the rows from y of the lines, using
+// rows and spans as buffers
+func (r *Rasterizer) rasterizeBand(p raster.Painter, y int, lines []int, rows *[bandHeight][]cell, spans []raster.Span) []raster.Span {
+	for i := range rows {
+		rows[i] = rows[i][:0]
+	}
+	height := min(bandHeight, r.height-y)
+	for _, index := range lines {
+		l := r.lines[index]
+		// the rows of the band crossed by the line
+		top, bottom := max(l.y0, float64(y)), min(l.y1, float64(y+height))
+		for row := int(math.Floor(top)); float64(row) < bottom; row++ {
+			ya, yb := max(l.y0, float64(row)), min(l.y1, float64(row+1))
+			if ya >= yb {
+				continue
+			}
+			rows[row-y] = r.accumulate(rows[row-y], l, ya, yb)
+		}
+	}
+	for i := range height {
+		spans = r.spans(spans[:0], y+i, rows[i])
+		if len(spans) > 0 {
+			p.Paint(spans, false)
+		}
+	}
+	return spans
+}
+
+// xAt returns the abscissa of the line l at the ordinate y
+func (l line) xAt(y float64) float64 {
+	switch y {
+	case l.y0:
+		return l.x0
+	case l.y1:
+		return l.x1
+	}
+	return l.x0 + (y-l.y0)*(l.x1-l.x0)/(l.y1-l.y0)
+}
+
+// accumulate adds to cells the area and the cover of the piece of the line l
+// between the ordinates ya and yb within a row
+func (r *Rasterizer) accumulate(cells []cell, l line, ya, yb float64) []cell {
+	xa, xb := l.xAt(ya), l.xAt(yb)
+	// the piece is split where it leaves the image, in the order of its x
+	bounds := [2]float64{0, float64(r.width)}
+	if xb < xa {
+		bounds[0], bounds[1] = bounds[1], bounds[0]
+	}
+	for _, bound := range bounds {
+		if xa < bound && bound < xb || xb < bound && bound < xa {
+			y := ya + (bound-xa)*(yb-ya)/(xb-xa)
+			cells = r.accumulatePiece(cells, l.direction, xa, ya, bound, y)
+			xa, ya = bound, y
+		}
+	}
+	return r.accumulatePiece(cells, l.direction, xa, ya, xb, yb)
+}
+
+// accumulatePiece adds to cells a piece of line on one side of the left
+// and right edges of the image. The pieces left of the image cover its
+// first pixel, the ones right of it do not cover any pixel.
+func (r *Rasterizer) accumulatePiece(cells []cell, direction, xa, ya, xb, yb float64) []cell {
+	switch {
+	case math.Max(xa, xb) <= 0:
+		cover := (yb - ya) * direction
+		return append(cells, cell{0, cover, cover})
+	case math.Min(xa, xb) >= float64(r.width):
+		return cells
+	}
+	// add adds a piece of line within a column of pixels
+	add := func(x0, x1, dy float64) {
+		cover := dy * direction
+		column := int(math.Floor(math.Min(x0, x1)))
+		mean := (x0+x1)/2 - float64(column)
+		cells = append(cells, cell{column, cover * (1 - mean), cover})
+	}
+	if xa == xb || math.Floor(xa) == math.Floor(xb) {
+		add(xa, xb, yb-ya)
+		return cells
+	}
+	// the piece is split at the pixel boundaries
+	x, y := xa, ya
+	for {
+		next := math.Floor(x) + 1
+		if xb < xa {
+			next = math.Ceil(x) - 1
+		}
+		if xa < xb && next >= xb || xb < xa && next <= xb {
+			add(x, xb, yb-y)
+			return cells
+		}
+		ny := ya + (next-xa)*(yb-ya)/(xb-xa)
+		add(x, next, ny-y)
+		x, y = next, ny
+	}
+}
+
+// spans appends to spans the spans of the row y of cells
+func (r *Rasterizer) spans(spans []raster.Span, y int, cells []cell) []raster.Span {
+	if len(cells) == 0 {
+		return spans
+	}
+	slices.SortFunc(cells, func(a, b cell) int {
+		return a.x - b.x
+	})
+	add := func(x0, x1 int, coverage float64) {
+		alpha := r.alpha(coverage)
+		if alpha == 0 || x0 >= x1 {
+			return
+		}
+		if n := len(spans); n > 0 && spans[n-1].X1 == x0 && spans[n-1].Alpha == alpha {
+			spans[n-1].X1 = x1
+			return
+		}
+		spans = append(spans, raster.Span{Y: y, X0: x0, X1: x1, Alpha: alpha})
+	}
+	x, cover := 0, 0.0
+	for i := 0; i < len(cells); {
+		c := cells[i]
+		area := c.area
+		coverDelta := c.cover
+		// merge the cells of the same pixel
+		for i++; i < len(cells) && cells[i].x == c.x; i++ {
+			area += cells[i].area
+			coverDelta += cells[i].cover
+		}
+		add(x, c.x, cover)
+		add(c.x, c.x+1, cover+area)
+		cover += coverDelta
+		x = c.x + 1
+	}
+	// up to the lines right of the image
+	add(x, r.width, cover)
+	return spans
+}
+
+// alpha returns the alpha of the spans of a winding coverage
+func (r *Rasterizer) alpha(coverage float64) uint32 {
+	coverage = math.Abs(coverage)
+	if r.UseNonZeroWinding {
+		coverage = math.Min(coverage, 1)
+	} else {
+		coverage = math.Mod(coverage, 2)
+		if coverage > 1 {
+			coverage = 2 - coverage
+		}
+	}
+	return uint32(coverage*0xffff + 0.5)
+}
@@ -0,0 +1,132 @@
+// Copyright 2010 The draw2d Authors. All rights reserved.
+// created: 19/10/2026 by draw2d contributors
+
+package floatraster
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/golang/freetype/raster"
+	"golang.org/x/image/math/fixed"
+)
+
+// alphaPainter records the alpha of the painted pixels
+type alphaPainter struct {
+	*image.Alpha16
+}
+
+func (p alphaPainter) Paint(ss []raster.Span, done bool) {
+	for _, s := range ss {
+		for x := s.X0; x < s.X1; x++ {
+			p.SetAlpha16(x, s.Y, color.Alpha16{A: uint16(s.Alpha)})
+		}
+	}
+}
+
+// polygon adds the closed polygon of points to r and to the freetype
+// rasterizer f if not nil, the points being rounded to the 26.6 fixed point
+// coordinates of freetype
+func polygon(r *Rasterizer, f *raster.Rasterizer, points ...float64) {
+	if f != nil {
+		for i := range points {
+			points[i] = math.Round(points[i]*64) / 64
+		}
+	}
+	r.MoveTo(points[0], points[1])
+	for i := 2; i < len(points); i += 2 {
+		r.LineTo(points[i], points[i+1])
+	}
+	r.End()
+	if f == nil {
+		return
+	}
+	point := func(i int) fixed.Point26_6 {
+		return fixed.Point26_6{X: fixed.Int26_6(points[i] * 64), Y: fixed.Int26_6(points[i+1] * 64)}
+	}
+	f.Start(point(0))
+	for i := 2; i < len(points); i += 2 {
+		f.Add1(point(i))
+	}
+	f.Add1(point(0))
+}
+
+// star returns the points of a five branch star, of which the center is
+// covered twice
+func star(cx, cy, radius float64) []float64 {
+	var points []float64
+	for i := 0; i < 5; i++ {
+		angle := float64(i) * 4 * math.Pi / 5
+		points = append(points, cx+radius*math.Sin(angle), cy-radius*math.Cos(angle))
+	}
+	return points
+}
+
+func TestRasterizer_Freetype(t *testing.T) {
+	for _, nonZero := range []bool{false, true} {
+		r := NewRasterizer(100, 300)
+		r.UseNonZeroWinding = nonZero
+		f := raster.NewRasterizer(100, 300)
+		f.UseNonZeroWinding = nonZero
+		polygon(r, f, star(50, 50, 45)...)
+		polygon(r, f, 10.3, 120.7, 93.2, 150.1, 20.8, 290.6)
+		// partly out of the image
+		polygon(r, f, -30, 160, 130, 170, 40, 180)
+
+		got, want := image.NewAlpha16(image.Rect(0, 0, 100, 300)), image.NewAlpha16(image.Rect(0, 0, 100, 300))
+		r.Workers = 3
+		r.Rasterize(alphaPainter{got})
+		f.Rasterize(alphaPainter{want})
+		for y := 0; y < 300; y++ {
+			for x := 0; x < 100; x++ {
+				g, w := got.Alpha16At(x, y).A, want.Alpha16At(x, y).A
+				// freetype rounds the coverage to 12 bits and the crossings of the
+				// lines to a 64th of pixel, which adds up at the tips of the star
+				if math.Abs(float64(g)-float64(w)) > 0x600 {
+					t.Fatalf("non-zero winding %v, pixel (%d, %d): got %#x, want %#x", nonZero, x, y, g, w)
+				}
+			}
+		}
+		center := got.Alpha16At(50, 50).A
+		if nonZero && center != 0xffff || !nonZero && center != 0 {
+			t.Errorf("non-zero winding %v: unexpected coverage %#x of the center of the star", nonZero, center)
+		}
+	}
+}
+
+func TestRasterizer_Exact(t *testing.T) {
+	r := NewRasterizer(10, 10)
+	// a square from 2.5 to 6 and a rectangle covering the whole image,
+	// drawn reversed and far from it
+	polygon(r, nil, 2.5, 2.5, 6, 2.5, 6, 6, 2.5, 6)
+	img := image.NewAlpha16(image.Rect(0, 0, 10, 10))
+	r.Rasterize(alphaPainter{img})
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			want := uint16(0)
+			switch {
+			case x >= 3 && x < 6 && y >= 3 && y < 6:
+				want = 0xffff
+			case (x == 2) != (y == 2) && x >= 2 && x < 6 && y >= 2 && y < 6:
+				want = 0x8000
+			case x == 2 && y == 2:
+				want = 0x4000
+			}
+			if got := img.Alpha16At(x, y).A; got != want {
+				t.Fatalf("pixel (%d, %d): got %#x, want %#x", x, y, got, want)
+			}
+		}
+	}
+
+	r.Clear()
+	polygon(r, nil, -1e9, -1e9, -1e9, 1e9, 1e9, 1e9, 1e9, -1e9)
+	img = image.NewAlpha16(image.Rect(0, 0, 10, 10))
+	r.Rasterize(alphaPainter{img})
+	for i := 0; i < len(img.Pix); i += 2 {
+		if img.Pix[i] != 0xff || img.Pix[i+1] != 0xff {
+			t.Fatal("huge coordinates should cover the whole image")
+		}
+	}
+}
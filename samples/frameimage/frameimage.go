@@ -5,8 +5,6 @@
 package frameimage
 
 import (
-	"math"
-
 	"github.com/llgcode/draw2d"
 	"github.com/llgcode/draw2d/draw2dimg"
 	"github.com/llgcode/draw2d/draw2dkit"
@@ -44,17 +42,17 @@ func Draw(gc draw2d.GraphicContext, png string,
 	if err != nil {
 		return err
 	}
-	// Size of source image
-	sw, sh := float64(source.Bounds().Dx()), float64(source.Bounds().Dy())
-	// Draw image to fit in the frame
-	// TODO Seems to have a transform bug here on draw image
-	scale := math.Min((dw-margin*2)/sw, (dh-margin*2)/sh)
+	// Draw image to fit in the frame, rotated around its center
 	gc.Save()
-	gc.Translate((dw-sw*scale)/2, (dh-sh*scale)/2)
-	gc.Scale(scale, scale)
+	gc.Translate(dw/2, dh/2)
 	gc.Rotate(0.2)
-
-	gc.DrawImage(source)
+	gc.DrawImageScaled(source, margin-dw/2, margin-dh/2, draw2d.ImageScaling{
+		Halign:        draw2d.HalignCenter,
+		Valign:        draw2d.ValignCenter,
+		Width:         dw - margin*2,
+		Height:        dh - margin*2,
+		ScalingPolicy: draw2d.ScalingFit,
+	})
 	gc.Restore()
 	return nil
 }